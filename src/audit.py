@@ -0,0 +1,575 @@
+"""Security audit of strings and validation of display names against spoofing."""
+
+import unicodedata
+from dataclasses import dataclass
+from enum import Enum
+from typing import FrozenSet, List, Optional, Set
+
+from src.emoji import _iter_clusters
+from src.string_utils import (
+    MAX_STRING_LENGTH,
+    _BIDI_CONTROL_PATTERN,
+    _DEFAULT_IGNORABLE,
+    _DISALLOWED_CONTROLS,
+    _EXTENDED_PICTOGRAPHIC,
+    _SCRIPT_COMBINATIONS,
+    _WHITESPACE_WORD,
+    _char_script,
+    _confusable_prototype,
+    skeleton,
+)
+
+
+class IssueKind(Enum):
+    """The kinds of suspicious content audit_string reports."""
+    
+    CONTROL = "control"
+    SURROGATE = "surrogate"
+    BIDI = "bidi"
+    INVISIBLE = "invisible"
+    MIXED_SCRIPT = "mixed_script"
+    CONFUSABLE = "confusable"
+
+
+class Severity(Enum):
+    """How likely a finding is to be an attack rather than an accident."""
+    
+    LOW = "low"
+    MEDIUM = "medium"
+    HIGH = "high"
+
+
+_SEVERITY_RANK = {Severity.LOW: 0, Severity.MEDIUM: 1, Severity.HIGH: 2}
+
+
+@dataclass
+class AuditFinding:
+    """
+    One suspicious character or word found by audit_string.
+    
+    Attributes:
+        kind: What kind of issue it is
+        severity: How suspicious it is
+        start: Index of its first character in the input
+        end: Index just past its last character, so that
+            text == input[start:end]
+        text: The character or word itself
+        description: What was found, such as
+            "U+202E RIGHT-TO-LEFT OVERRIDE"
+    """
+    
+    kind: IssueKind
+    severity: Severity
+    start: int
+    end: int
+    text: str
+    description: str
+
+
+@dataclass
+class AuditReport:
+    """
+    The result of audit_string.
+    
+    Attributes:
+        findings: Everything suspicious found, in order of position
+        severity: The highest severity among the findings, or None if
+            there are none
+    """
+    
+    findings: List[AuditFinding]
+    severity: Optional[Severity]
+    
+    @property
+    def clean(self) -> bool:
+        """Whether nothing suspicious was found."""
+        return not self.findings
+
+
+def _describe(char: str) -> str:
+    """Return a character as "U+XXXX NAME", or "U+XXXX" if it has no name."""
+    name = unicodedata.name(char, "")
+    return f"U+{ord(char):04X} {name}" if name else f"U+{ord(char):04X}"
+
+
+def _is_emoji_ignorable(char: str) -> bool:
+    """Return whether char is a default-ignorable that emoji sequences use."""
+    # The zero-width joiner, the text and emoji presentation selectors, and
+    # the tag characters of subdivision flags such as England's.
+    return char in "\u200d\ufe0e\ufe0f" or "\U000e0020" <= char <= "\U000e007f"
+
+
+def _stray_ignorables(input_str: str) -> Set[int]:
+    """
+    Return the indices of the default-ignorable characters outside emoji.
+    
+    These draw as nothing, so they can hide inside a word, as in "admin"
+    followed by a variation selector or a tag character. Only the joiners,
+    presentation selectors and tags within an emoji sequence are left out.
+    """
+    stray: Set[int] = set()
+    start = 0
+    for cluster, emoji in _iter_clusters(input_str):
+        pictographic = emoji or any(
+            _EXTENDED_PICTOGRAPHIC.match(char) for char in cluster
+        )
+        for offset, char in enumerate(cluster):
+            if _DEFAULT_IGNORABLE.match(char) and not (
+                pictographic and _is_emoji_ignorable(char)
+            ):
+                stray.add(start + offset)
+        start += len(cluster)
+    return stray
+
+
+def _character_finding(
+    input_str: str, index: int, stray: Set[int]
+) -> Optional[AuditFinding]:
+    """
+    Return the finding for the character at index, if it is suspicious.
+    
+    stray holds the indices of default-ignorables outside emoji, as
+    returned by _stray_ignorables.
+    """
+    char = input_str[index]
+    if char in _DISALLOWED_CONTROLS:
+        severity = Severity.HIGH if char in "\x00\x1b" else Severity.MEDIUM
+        kind = IssueKind.CONTROL
+    elif "\ud800" <= char <= "\udfff":
+        severity = Severity.HIGH
+        kind = IssueKind.SURROGATE
+    elif _BIDI_CONTROL_PATTERN.match(char):
+        # Embeddings, overrides and isolates reorder the text after them;
+        # the marks only affect neutral characters next to them.
+        reorders = "\u202a" <= char <= "\u202e" or "\u2066" <= char <= "\u2069"
+        severity = Severity.HIGH if reorders else Severity.LOW
+        kind = IssueKind.BIDI
+    elif index in stray:
+        # The joiners also shape Arabic, Persian and Indic words.
+        severity = Severity.LOW if char in "\u200c\u200d" else Severity.MEDIUM
+        kind = IssueKind.INVISIBLE
+    else:
+        return None
+    return AuditFinding(kind, severity, index, index + 1, char, _describe(char))
+
+
+def _word_findings(input_str: str, start: int, end: int) -> List[AuditFinding]:
+    """Return the mixed-script and confusable findings for one word."""
+    word = input_str[start:end]
+    scripts = set()
+    for char in word:
+        script = _char_script(char)
+        if script not in ("Common", "Inherited"):
+            scripts.add(script)
+    findings: List[AuditFinding] = []
+    if len(scripts) > 1 and not any(
+        scripts <= combination for combination in _SCRIPT_COMBINATIONS
+    ):
+        findings.append(
+            AuditFinding(
+                IssueKind.MIXED_SCRIPT,
+                Severity.HIGH,
+                start,
+                end,
+                word,
+                "Letters from " + ", ".join(sorted(scripts)),
+            )
+        )
+    # Lookalike letters are only deceptive in a word that mixes scripts or
+    # could pass for ASCII as a whole, such as Cyrillic "\u0441\u043e\u0441\u043e".
+    lookalikes = []
+    passes_for_ascii = True
+    for offset, char in enumerate(word):
+        base = unicodedata.normalize("NFD", char)[0]
+        if not base.isalpha() or base.isascii():
+            continue
+        prototype = _confusable_prototype(base)
+        if prototype.isascii() and prototype.isalpha():
+            lookalikes.append((offset, char, prototype))
+        else:
+            passes_for_ascii = False
+    if findings or passes_for_ascii:
+        for offset, char, prototype in lookalikes:
+            findings.append(
+                AuditFinding(
+                    IssueKind.CONFUSABLE,
+                    Severity.MEDIUM,
+                    start + offset,
+                    start + offset + 1,
+                    char,
+                    f"{_describe(char)} looks like {prototype!r}",
+                )
+            )
+    return findings
+
+
+def audit_string(input_str: str) -> AuditReport:
+    """
+    Report every suspicious character in a string, with offsets and severity.
+    
+    Where validation only accepts or rejects a string, this lists what is
+    wrong with it, for security review pipelines, moderation tools and
+    logs. It reports:
+    
+    - CONTROL: control characters other than tab, newline and carriage
+      return; NUL and ESC, which starts terminal escape sequences, are
+      HIGH severity and the others MEDIUM
+    - SURROGATE: lone surrogates, which cannot be encoded (HIGH)
+    - BIDI: bidirectional embeddings, overrides and isolates, which can
+      reorder how text is displayed as in "Trojan Source" attacks (HIGH),
+      and the directional marks, which are often legitimate (LOW)
+    - INVISIBLE: zero-width and other default-ignorable characters, such
+      as U+200B, variation selectors and tag characters (MEDIUM), and the
+      zero-width joiners U+200C and U+200D, which some scripts need
+      (LOW). The joiners, presentation selectors and tag characters that
+      are part of an emoji sequence are not reported
+    - MIXED_SCRIPT: whitespace-delimited words mixing letters of several
+      scripts, such as "paypal" spelled with a Cyrillic "a" (U+0430),
+      other than combinations written together as in Japanese (HIGH)
+    - CONFUSABLE: letters drawn like an ASCII letter, such as Cyrillic
+      U+0430 or fullwidth U+FF41, as mapped by skeleton, in words that
+      mix scripts or consist only of such letters and ASCII (MEDIUM).
+      Words in other scripts that merely contain a lookalike, such as
+      Russian "мир", are not reported
+    
+    Unlike most functions in this module, every character is accepted,
+    since finding unusual characters is the point.
+    
+    Args:
+        input_str: The string to audit
+        
+    Returns:
+        An AuditReport listing the findings in order of position, with
+        the highest severity among them
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input exceeds MAX_STRING_LENGTH
+        
+    Examples:
+        >>> report = audit_string("p\\u0430ypal\\u200b")
+        >>> report.severity
+        <Severity.HIGH: 'high'>
+        >>> for finding in report.findings:
+        ...     print(finding.kind.name, finding.start, finding.description)
+        MIXED_SCRIPT 0 Letters from Cyrillic, Latin
+        CONFUSABLE 1 U+0430 CYRILLIC SMALL LETTER A looks like 'a'
+        INVISIBLE 6 U+200B ZERO WIDTH SPACE
+        >>> audit_string("Hello, world").clean
+        True
+    """
+    if not isinstance(input_str, str):
+        raise TypeError(f"Input must be a string, got {type(input_str).__name__}")
+    if len(input_str) > MAX_STRING_LENGTH:
+        raise ValueError(
+            f"Input exceeds maximum length of {MAX_STRING_LENGTH} characters"
+        )
+    
+    findings: List[AuditFinding] = []
+    stray = _stray_ignorables(input_str)
+    for index in range(len(input_str)):
+        finding = _character_finding(input_str, index, stray)
+        if finding is not None:
+            findings.append(finding)
+    for match in _WHITESPACE_WORD.finditer(input_str):
+        findings.extend(_word_findings(input_str, match.start(), match.end()))
+    findings.sort(key=lambda finding: (finding.start, -finding.end))
+    
+    severity = max(
+        (finding.severity for finding in findings),
+        key=_SEVERITY_RANK.__getitem__,
+        default=None,
+    )
+    return AuditReport(findings, severity)
+
+
+class ViolationKind(Enum):
+    """The ways a display name can break a DisplayNamePolicy."""
+    
+    EMPTY = "empty"
+    CONTROL = "control"
+    INVISIBLE = "invisible"
+    BIDI_CONTROL = "bidi_control"
+    DISALLOWED_SCRIPT = "disallowed_script"
+    MIXED_SCRIPT = "mixed_script"
+    COMBINING_MARKS = "combining_marks"
+    RESERVED = "reserved"
+
+
+@dataclass
+class DisplayNamePolicy:
+    """
+    Rules applied by validate_display_name.
+    
+    Attributes:
+        allowed_scripts: The scripts letters may be written in, such as
+            {"Latin", "Cyrillic"}, as named by detect_scripts, or None to
+            allow any script. Digits, punctuation and symbols are always
+            allowed.
+        allow_mixed_scripts: Whether one word may mix letters of several
+            scripts. Defaults to False, since that is how lookalike names
+            such as "paypal" with a Cyrillic "a" are made.
+        max_combining_marks: The number of combining marks that may stack
+            on one character. Defaults to 2, enough for any Latin,
+            Cyrillic or Greek text and for decomposed Vietnamese.
+        allow_bidi_controls: Whether bidirectional control characters are
+            allowed. Defaults to False.
+        reserved_names: Names that may not be used or imitated, such as
+            "admin" or "support". A name is rejected when it is
+            confusable with one of them, ignoring case.
+    """
+    
+    allowed_scripts: Optional[FrozenSet[str]] = None
+    allow_mixed_scripts: bool = False
+    max_combining_marks: int = 2
+    allow_bidi_controls: bool = False
+    reserved_names: FrozenSet[str] = frozenset()
+
+
+@dataclass
+class DisplayNameViolation:
+    """
+    One way a display name breaks a DisplayNamePolicy.
+    
+    Attributes:
+        kind: Which rule is broken
+        start: Index of the first offending character in the name
+        end: Index just past the last offending character, so that
+            text == name[start:end]; rules about the whole name, such as
+            RESERVED, span all of it
+        text: The offending characters
+        message: A description of the violation that can be shown to the
+            user choosing the name
+    """
+    
+    kind: ViolationKind
+    start: int
+    end: int
+    text: str
+    message: str
+
+
+def _check_display_name_policy(policy: DisplayNamePolicy) -> None:
+    """Raise unless policy is usable."""
+    if isinstance(policy.allowed_scripts, str):
+        raise TypeError("Allowed scripts must be a collection of script names")
+    if isinstance(policy.reserved_names, str):
+        raise TypeError("Reserved names must be a collection of names")
+    if policy.max_combining_marks < 0:
+        raise ValueError(
+            "Maximum combining marks cannot be negative, "
+            f"got {policy.max_combining_marks}"
+        )
+
+
+def _reserved_keys(name: str) -> Set[str]:
+    """Return the skeletons under which name collides with a reserved name."""
+    # Folding case first catches "AdmIn" for "admin"; reducing to the
+    # skeleton first catches "SUPP0RT" for "support", since the prototype
+    # of "0" is the capital "O".
+    return {skeleton(name.casefold()), skeleton(skeleton(name).casefold())}
+
+
+def _is_mark(char: str) -> bool:
+    """Return whether char is a combining mark."""
+    return unicodedata.category(char) in ("Mn", "Me")
+
+
+def _character_violations(
+    name: str, policy: DisplayNamePolicy
+) -> List[DisplayNameViolation]:
+    """Return the violations of single characters anywhere in name."""
+    violations: List[DisplayNameViolation] = []
+    stray = _stray_ignorables(name)
+    for index, char in enumerate(name):
+        if unicodedata.category(char) in ("Cc", "Cs", "Zl", "Zp"):
+            kind = ViolationKind.CONTROL
+            message = f"Control character {_describe(char)} is not allowed"
+        elif _BIDI_CONTROL_PATTERN.match(char):
+            if policy.allow_bidi_controls:
+                continue
+            kind = ViolationKind.BIDI_CONTROL
+            message = f"Bidirectional control {_describe(char)} is not allowed"
+        elif index in stray:
+            kind = ViolationKind.INVISIBLE
+            message = f"Invisible character {_describe(char)} is not allowed"
+        else:
+            continue
+        violations.append(DisplayNameViolation(kind, index, index + 1, char, message))
+    return violations
+
+
+def _mark_violations(name: str, max_marks: int) -> List[DisplayNameViolation]:
+    """Return a violation for each run of more than max_marks marks."""
+    violations: List[DisplayNameViolation] = []
+    index = 0
+    while index < len(name):
+        if not _is_mark(name[index]):
+            index += 1
+            continue
+        start = index
+        while index < len(name) and _is_mark(name[index]):
+            index += 1
+        if index - start > max_marks:
+            violations.append(
+                DisplayNameViolation(
+                    ViolationKind.COMBINING_MARKS,
+                    start,
+                    index,
+                    name[start:index],
+                    f"{index - start} combining marks are stacked on one "
+                    f"character; at most {max_marks} are allowed",
+                )
+            )
+    return violations
+
+
+def _script_violations(
+    name: str, allowed_scripts: FrozenSet[str]
+) -> List[DisplayNameViolation]:
+    """Return a violation for each run of letters in a disallowed script."""
+    # Each run is [script, start, end].
+    runs: List[list] = []
+    previous = "Common"
+    for index, char in enumerate(name):
+        script = _char_script(char)
+        if script == "Inherited":
+            script = previous
+        previous = "Common" if char.isspace() else script
+        if script == "Common" or script in allowed_scripts:
+            continue
+        if runs and runs[-1][0] == script and runs[-1][2] == index:
+            runs[-1][2] = index + 1
+        else:
+            runs.append([script, index, index + 1])
+    return [
+        DisplayNameViolation(
+            ViolationKind.DISALLOWED_SCRIPT,
+            start,
+            end,
+            name[start:end],
+            f"Letters in the {script} script are not allowed",
+        )
+        for script, start, end in runs
+    ]
+
+
+def _mixed_script_violations(name: str) -> List[DisplayNameViolation]:
+    """Return a violation for each word mixing letters of several scripts."""
+    violations: List[DisplayNameViolation] = []
+    for match in _WHITESPACE_WORD.finditer(name):
+        findings = _word_findings(name, match.start(), match.end())
+        if findings and findings[0].kind is IssueKind.MIXED_SCRIPT:
+            violations.append(
+                DisplayNameViolation(
+                    ViolationKind.MIXED_SCRIPT,
+                    match.start(),
+                    match.end(),
+                    match.group(),
+                    findings[0].description
+                    + " are mixed in one word, which can imitate another name",
+                )
+            )
+    return violations
+
+
+def validate_display_name(
+    name: str, policy: Optional[DisplayNamePolicy] = None
+) -> List[DisplayNameViolation]:
+    """
+    Check a username or display name against rules that prevent spoofing.
+    
+    Rather than rejecting a name with a single error, every problem is
+    reported with its position and a message that can be shown to the
+    user, so they can fix the name. The checks are:
+    
+    - EMPTY: the name is empty or only whitespace
+    - CONTROL: control characters, line separators and lone surrogates,
+      which are never allowed
+    - INVISIBLE: zero-width and other default-ignorable characters, such
+      as joiners, variation selectors and tag characters, which are never
+      allowed outside an emoji sequence
+    - BIDI_CONTROL: bidirectional controls, which can make a name display
+      in a different order than it is stored, unless allowed by the policy
+    - DISALLOWED_SCRIPT: each run of letters in a script outside
+      policy.allowed_scripts
+    - MIXED_SCRIPT: each whitespace-delimited word mixing letters of
+      several scripts, other than combinations written together as in
+      Japanese, unless allowed by the policy
+    - COMBINING_MARKS: each run of more than policy.max_combining_marks
+      combining marks, as in "Zalgo" text
+    - RESERVED: the name is confusable with one of policy.reserved_names,
+      ignoring case; "PayPa1" and "p\\u0430ypal" with a Cyrillic "a" both
+      collide with "paypal" (see skeleton)
+    
+    Args:
+        name: The name to check
+        policy: The rules to apply. Defaults to DisplayNamePolicy(),
+            which allows any single script per word, two combining marks
+            per character and no reserved names.
+        
+    Returns:
+        The violations in order of position; an empty list means the name
+        is acceptable
+        
+    Raises:
+        TypeError: If name is not a string, or allowed_scripts or
+            reserved_names is a string rather than a collection
+        ValueError: If name exceeds MAX_STRING_LENGTH or
+            max_combining_marks is negative
+        
+    Examples:
+        >>> policy = DisplayNamePolicy(reserved_names=frozenset({"paypal"}))
+        >>> validate_display_name("Ada Lovelace", policy)
+        []
+        >>> for violation in validate_display_name("PayPa1\\u200b", policy):
+        ...     print(violation.kind.name, violation.start, violation.message)
+        RESERVED 0 Name is too similar to the reserved name 'paypal'
+        INVISIBLE 6 Invisible character U+200B ZERO WIDTH SPACE is not allowed
+    """
+    if not isinstance(name, str):
+        raise TypeError(f"Input must be a string, got {type(name).__name__}")
+    if len(name) > MAX_STRING_LENGTH:
+        raise ValueError(
+            f"Input exceeds maximum length of {MAX_STRING_LENGTH} characters"
+        )
+    if policy is None:
+        policy = DisplayNamePolicy()
+    _check_display_name_policy(policy)
+    
+    if not name.strip():
+        return [
+            DisplayNameViolation(
+                ViolationKind.EMPTY, 0, len(name), name, "Name cannot be empty"
+            )
+        ]
+    
+    violations = _character_violations(name, policy)
+    violations.extend(_mark_violations(name, policy.max_combining_marks))
+    if policy.allowed_scripts is not None:
+        violations.extend(_script_violations(name, policy.allowed_scripts))
+    if not policy.allow_mixed_scripts:
+        violations.extend(_mixed_script_violations(name))
+    
+    # Default-ignorable characters and controls are left out of the
+    # comparison, so that inserting them cannot get around a reserved name.
+    visible = "".join(
+        char
+        for char in _DEFAULT_IGNORABLE.sub("", name)
+        if unicodedata.category(char) not in ("Cc", "Cs", "Zl", "Zp")
+    ).strip()
+    keys = _reserved_keys(visible)
+    for reserved in sorted(policy.reserved_names):
+        if keys & _reserved_keys(reserved):
+            violations.append(
+                DisplayNameViolation(
+                    ViolationKind.RESERVED,
+                    0,
+                    len(name),
+                    name,
+                    f"Name is too similar to the reserved name {reserved!r}",
+                )
+            )
+            break
+    violations.sort(key=lambda violation: (violation.start, -violation.end))
+    return violations
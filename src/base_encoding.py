@@ -0,0 +1,440 @@
+"""Base58, Base62 and Crockford Base32 encodings for short identifiers."""
+
+import math
+from typing import Dict, Tuple, Union
+
+from src.string_utils import MAX_STRING_LENGTH
+
+
+BASE58_ALPHABET = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+BASE62_ALPHABET = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+CROCKFORD_ALPHABET = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+# The longest text, in symbols, that is encoded or decoded. The encodings
+# are meant for identifiers, and converting a number between bases takes
+# time that grows with the square of its length, so longer input is
+# refused rather than left to tie up the caller.
+MAX_ENCODED_LENGTH = 1024
+
+# The extra symbols for check values 32 to 36, which only appear as the
+# final check symbol.
+_CROCKFORD_CHECK_SYMBOLS = CROCKFORD_ALPHABET + "*~$=U"
+
+_BASE58_VALUES = {char: value for value, char in enumerate(BASE58_ALPHABET)}
+_BASE62_VALUES = {char: value for value, char in enumerate(BASE62_ALPHABET)}
+# Decoding is case-insensitive and reads the letters easily mistaken for
+# digits as those digits.
+_CROCKFORD_VALUES = {
+    **{char: value for value, char in enumerate(CROCKFORD_ALPHABET)},
+    **{char.lower(): value for value, char in enumerate(CROCKFORD_ALPHABET)},
+    "O": 0, "o": 0, "I": 1, "i": 1, "L": 1, "l": 1,
+}
+_CROCKFORD_CHECK_VALUES = {
+    **{char: value for value, char in enumerate(_CROCKFORD_CHECK_SYMBOLS)},
+    **_CROCKFORD_VALUES,
+    "u": 36,
+}
+
+Data = Union[bytes, bytearray, memoryview, int]
+
+
+def _check_data(data: Data) -> Union[bytes, int]:
+    """Return data as bytes or a non-negative int, or raise."""
+    if isinstance(data, (bytes, bytearray, memoryview)):
+        return bytes(data)
+    if isinstance(data, int) and not isinstance(data, bool):
+        if data < 0:
+            raise ValueError(f"Cannot encode a negative number, got {data}")
+        return data
+    raise TypeError(f"Data must be bytes or an int, got {type(data).__name__}")
+
+
+def _check_text(text: str) -> None:
+    """Raise unless text is a string of acceptable length."""
+    if not isinstance(text, str):
+        raise TypeError(f"Input must be a string, got {type(text).__name__}")
+    if len(text) > MAX_STRING_LENGTH:
+        raise ValueError(
+            f"Input exceeds maximum length of {MAX_STRING_LENGTH} characters"
+        )
+
+
+def _check_encoded_size(data: Union[bytes, int], base: int) -> None:
+    """Raise if data would encode to more than MAX_ENCODED_LENGTH symbols."""
+    bits = data.bit_length() if isinstance(data, int) else len(data) * 8
+    if bits > MAX_ENCODED_LENGTH * math.log2(base):
+        raise ValueError(
+            f"Data is too large to encode in {MAX_ENCODED_LENGTH} characters"
+        )
+
+
+def _check_encoded_length(text: str, name: str) -> None:
+    """Raise if text has more than MAX_ENCODED_LENGTH symbols."""
+    if len(text) > MAX_ENCODED_LENGTH:
+        raise ValueError(
+            f"{name} text exceeds maximum length of {MAX_ENCODED_LENGTH} characters"
+        )
+
+
+def _encode_int(value: int, alphabet: str) -> str:
+    """Write a non-negative int in the base of alphabet, most significant first."""
+    base = len(alphabet)
+    digits = []
+    while True:
+        value, digit = divmod(value, base)
+        digits.append(alphabet[digit])
+        if not value:
+            return "".join(reversed(digits))
+
+
+def _decode_int(text: str, values: Dict[str, int], base: int, name: str) -> int:
+    """Read text as a number in base, raising on characters not in values."""
+    value = 0
+    for index, char in enumerate(text):
+        digit = values.get(char)
+        if digit is None:
+            raise ValueError(f"Invalid {name} character {char!r} at index {index}")
+        value = value * base + digit
+    return value
+
+
+def _encode_big_endian(data: Data, alphabet: str) -> str:
+    """
+    Encode bytes as one big-endian number, or encode an int.
+    
+    Each leading zero byte is written as the zero digit, since the number
+    alone would lose them; this is the Bitcoin Base58 convention.
+    """
+    data = _check_data(data)
+    _check_encoded_size(data, len(alphabet))
+    if isinstance(data, int):
+        return _encode_int(data, alphabet)
+    stripped = data.lstrip(b"\x00")
+    zeros = alphabet[0] * (len(data) - len(stripped))
+    if not stripped:
+        return zeros
+    return zeros + _encode_int(int.from_bytes(stripped, "big"), alphabet)
+
+
+def _decode_big_endian(
+    text: str, alphabet: str, values: Dict[str, int], name: str
+) -> bytes:
+    """Decode the output of _encode_big_endian for bytes."""
+    _check_text(text)
+    _check_encoded_length(text, name)
+    stripped = text.lstrip(alphabet[0])
+    value = _decode_int(stripped, values, len(alphabet), name)
+    zeros = b"\x00" * (len(text) - len(stripped))
+    if not stripped:
+        return zeros
+    return zeros + value.to_bytes((value.bit_length() + 7) // 8, "big")
+
+
+def _decode_number(text: str, values: Dict[str, int], base: int, name: str) -> int:
+    """Decode a non-empty string as an int."""
+    _check_text(text)
+    if not text:
+        raise ValueError(f"Cannot decode an empty {name} string as a number")
+    _check_encoded_length(text, name)
+    return _decode_int(text, values, base, name)
+
+
+def encode_base58(data: Data) -> str:
+    """
+    Encode bytes or a non-negative int in Base58.
+    
+    Base58 uses the Bitcoin alphabet: digits and letters without "0",
+    "O", "I" and "l", which are easily confused, and without punctuation,
+    so identifiers can be read aloud, typed and selected with a double
+    click. Bytes are encoded as one big-endian number, with each leading
+    zero byte written as "1".
+    
+    Args:
+        data: The bytes or non-negative int to encode
+        
+    Returns:
+        The Base58 text
+        
+    Raises:
+        TypeError: If data is not bytes, a bytearray, a memoryview or an int
+        ValueError: If data is a negative int, or too large to encode in
+            MAX_ENCODED_LENGTH characters
+        
+    Examples:
+        >>> encode_base58(b"hello world")
+        'StV1DL6CwTryKyV'
+        >>> encode_base58(b"\\x00\\x00\\x01")
+        '112'
+        >>> encode_base58(2**64 - 1)
+        'jpXCZedGfVQ'
+    """
+    return _encode_big_endian(data, BASE58_ALPHABET)
+
+
+def decode_base58(text: str) -> bytes:
+    """
+    Decode Base58 text produced by encode_base58 from bytes.
+    
+    Args:
+        text: The Base58 text
+        
+    Returns:
+        The decoded bytes
+        
+    Raises:
+        TypeError: If text is not a string
+        ValueError: If text exceeds MAX_ENCODED_LENGTH or contains a
+            character outside the Base58 alphabet
+        
+    Examples:
+        >>> decode_base58("StV1DL6CwTryKyV")
+        b'hello world'
+    """
+    return _decode_big_endian(text, BASE58_ALPHABET, _BASE58_VALUES, "Base58")
+
+
+def decode_base58_int(text: str) -> int:
+    """
+    Decode Base58 text produced by encode_base58 from an int.
+    
+    Args:
+        text: The Base58 text
+        
+    Returns:
+        The decoded number
+        
+    Raises:
+        TypeError: If text is not a string
+        ValueError: If text is empty, exceeds MAX_ENCODED_LENGTH or
+            contains a character outside the Base58 alphabet
+        
+    Examples:
+        >>> decode_base58_int("jpXCZedGfVQ") == 2**64 - 1
+        True
+    """
+    return _decode_number(text, _BASE58_VALUES, 58, "Base58")
+
+
+def encode_base62(data: Data) -> str:
+    """
+    Encode bytes or a non-negative int in Base62.
+    
+    Base62 uses the digits, uppercase and lowercase letters, the densest
+    encoding that needs no punctuation and so is safe in URLs, file names
+    and identifiers. Bytes are encoded as one big-endian number, with
+    each leading zero byte written as "0".
+    
+    Args:
+        data: The bytes or non-negative int to encode
+        
+    Returns:
+        The Base62 text
+        
+    Raises:
+        TypeError: If data is not bytes, a bytearray, a memoryview or an int
+        ValueError: If data is a negative int, or too large to encode in
+            MAX_ENCODED_LENGTH characters
+        
+    Examples:
+        >>> encode_base62(b"hello world")
+        'AAwf93rvy4aWQVw'
+        >>> encode_base62(2**64 - 1)
+        'LygHa16AHYF'
+    """
+    return _encode_big_endian(data, BASE62_ALPHABET)
+
+
+def decode_base62(text: str) -> bytes:
+    """
+    Decode Base62 text produced by encode_base62 from bytes.
+    
+    Args:
+        text: The Base62 text
+        
+    Returns:
+        The decoded bytes
+        
+    Raises:
+        TypeError: If text is not a string
+        ValueError: If text exceeds MAX_ENCODED_LENGTH or contains a
+            character outside the Base62 alphabet
+        
+    Examples:
+        >>> decode_base62("AAwf93rvy4aWQVw")
+        b'hello world'
+    """
+    return _decode_big_endian(text, BASE62_ALPHABET, _BASE62_VALUES, "Base62")
+
+
+def decode_base62_int(text: str) -> int:
+    """
+    Decode Base62 text produced by encode_base62 from an int.
+    
+    Args:
+        text: The Base62 text
+        
+    Returns:
+        The decoded number
+        
+    Raises:
+        TypeError: If text is not a string
+        ValueError: If text is empty, exceeds MAX_ENCODED_LENGTH or
+            contains a character outside the Base62 alphabet
+        
+    Examples:
+        >>> decode_base62_int("LygHa16AHYF") == 2**64 - 1
+        True
+    """
+    return _decode_number(text, _BASE62_VALUES, 62, "Base62")
+
+
+def _crockford_check_symbol(value: int) -> str:
+    """Return the Crockford check symbol of a number: its value modulo 37."""
+    return _CROCKFORD_CHECK_SYMBOLS[value % 37]
+
+
+def encode_crockford32(data: Data, checksum: bool = False) -> str:
+    """
+    Encode bytes or a non-negative int in Crockford's Base32.
+    
+    Crockford's alphabet leaves out "I", "L", "O" and "U", so that codes
+    are unambiguous when read aloud or typed, and decoding forgives case
+    and reads "O" as 0 and "I" and "L" as 1. Bytes are split into 5-bit
+    groups, the last padded with zero bits, as in RFC 4648 but without
+    "=" padding; an int is written as a number in base 32.
+    
+    Args:
+        data: The bytes or non-negative int to encode
+        checksum: If True, a check symbol is appended: the value modulo 37,
+            with "*~$=U" for 32 to 36, which detects any single wrong or
+            any two swapped adjacent symbols. For bytes, the value is the
+            bytes read as one big-endian number. Defaults to False.
+        
+    Returns:
+        The Crockford Base32 text, in uppercase
+        
+    Raises:
+        TypeError: If data is not bytes, a bytearray, a memoryview or an int
+        ValueError: If data is a negative int, or too large to encode in
+            MAX_ENCODED_LENGTH characters
+        
+    Examples:
+        >>> encode_crockford32(b"hello")
+        'D1JPRV3F'
+        >>> encode_crockford32(1234, checksum=True)
+        '16JD'
+    """
+    data = _check_data(data)
+    _check_encoded_size(data, 32)
+    if isinstance(data, int):
+        value = data
+        text = _encode_int(value, CROCKFORD_ALPHABET)
+    else:
+        value = int.from_bytes(data, "big")
+        length = (len(data) * 8 + 4) // 5
+        padded = value << (length * 5 - len(data) * 8)
+        text = _encode_int(padded, CROCKFORD_ALPHABET).rjust(length, "0")
+        if not data:
+            text = ""
+    if checksum:
+        text += _crockford_check_symbol(value)
+    return text
+
+
+def _split_check_symbol(text: str, checksum: bool) -> Tuple[str, str]:
+    """Remove hyphens from text and split off its check symbol, if any."""
+    _check_text(text)
+    text = text.replace("-", "")
+    if not checksum:
+        return text, ""
+    if not text:
+        raise ValueError("Crockford Base32 text with a checksum cannot be empty")
+    if text[-1] not in _CROCKFORD_CHECK_VALUES:
+        raise ValueError(f"Invalid Crockford Base32 check symbol {text[-1]!r}")
+    return text[:-1], text[-1]
+
+
+def _verify_check_symbol(value: int, symbol: str) -> None:
+    """Raise unless symbol is the check symbol of value."""
+    if symbol and _CROCKFORD_CHECK_VALUES[symbol] != value % 37:
+        raise ValueError(
+            f"Crockford Base32 checksum mismatch: expected "
+            f"{_crockford_check_symbol(value)!r}, got {symbol!r}"
+        )
+
+
+def decode_crockford32(text: str, checksum: bool = False) -> bytes:
+    """
+    Decode Crockford Base32 text produced by encode_crockford32 from bytes.
+    
+    Decoding is case-insensitive, reads "O" as 0 and "I" and "L" as 1, and
+    ignores hyphens, which may be added for readability.
+    
+    Args:
+        text: The Crockford Base32 text
+        checksum: Whether text ends with a check symbol, which is verified
+            and removed. Defaults to False.
+        
+    Returns:
+        The decoded bytes
+        
+    Raises:
+        TypeError: If text is not a string
+        ValueError: If text exceeds MAX_ENCODED_LENGTH symbols, not
+            counting hyphens and the check symbol, contains an invalid
+            character, has a length no bytes encode to, has nonzero
+            padding bits, or its check symbol does not match
+        
+    Examples:
+        >>> decode_crockford32("d1jp-rv3f")
+        b'hello'
+    """
+    text, symbol = _split_check_symbol(text, checksum)
+    _check_encoded_length(text, "Crockford Base32")
+    padded = _decode_int(text, _CROCKFORD_VALUES, 32, "Crockford Base32")
+    size = len(text) * 5 // 8
+    if (size * 8 + 4) // 5 != len(text):
+        raise ValueError(
+            f"Invalid Crockford Base32 length {len(text)}: no bytes encode to it"
+        )
+    padding = len(text) * 5 - size * 8
+    if padded & ((1 << padding) - 1):
+        raise ValueError("Crockford Base32 text has nonzero padding bits")
+    value = padded >> padding
+    _verify_check_symbol(value, symbol)
+    return value.to_bytes(size, "big")
+
+
+def decode_crockford32_int(text: str, checksum: bool = False) -> int:
+    """
+    Decode Crockford Base32 text produced by encode_crockford32 from an int.
+    
+    Decoding is case-insensitive, reads "O" as 0 and "I" and "L" as 1, and
+    ignores hyphens, which may be added for readability.
+    
+    Args:
+        text: The Crockford Base32 text
+        checksum: Whether text ends with a check symbol, which is verified
+            and removed. Defaults to False.
+        
+    Returns:
+        The decoded number
+        
+    Raises:
+        TypeError: If text is not a string
+        ValueError: If text is empty, exceeds MAX_ENCODED_LENGTH symbols,
+            not counting hyphens and the check symbol, contains an invalid
+            character or its check symbol does not match
+        
+    Examples:
+        >>> decode_crockford32_int("16jd", checksum=True)
+        1234
+        >>> decode_crockford32_int("16JE", checksum=True)
+        Traceback (most recent call last):
+            ...
+        ValueError: Crockford Base32 checksum mismatch: expected 'D', got 'E'
+    """
+    text, symbol = _split_check_symbol(text, checksum)
+    value = _decode_number(text, _CROCKFORD_VALUES, 32, "Crockford Base32")
+    _verify_check_symbol(value, symbol)
+    return value
@@ -0,0 +1,531 @@
+"""Conversions between human-readable text and identifier case styles."""
+
+import unicodedata
+from dataclasses import dataclass
+from enum import Enum
+from typing import Callable, Iterable, List, Tuple
+
+from src.string_utils import _validate_input
+
+
+def _is_word_char(char: str) -> bool:
+    """Return True for letters, digits and combining marks."""
+    return char.isalnum() or unicodedata.category(char).startswith("M")
+
+
+def _split_identifier_words(input_str: str) -> List[str]:
+    """
+    Split text or an existing identifier into words.
+    
+    Runs of characters other than letters, digits and combining marks
+    separate words. Inside a run, a new word starts at an uppercase letter
+    that follows a lowercase letter or digit ("helloWorld"), and at the
+    last uppercase letter of an acronym that is followed by a lowercase
+    letter ("HTTPResponse" splits as "HTTP", "Response").
+    
+    Args:
+        input_str: A string that has already passed validation
+        
+    Returns:
+        The non-empty words in order, with their original casing
+    """
+    words: List[str] = []
+    current: List[str] = []
+    for index, char in enumerate(input_str):
+        if not _is_word_char(char):
+            if current:
+                words.append("".join(current))
+                current = []
+            continue
+        if current and char.isupper():
+            previous = current[-1]
+            following = input_str[index + 1 : index + 2]
+            if (
+                previous.islower()
+                or previous.isdigit()
+                or (previous.isupper() and following.islower())
+            ):
+                words.append("".join(current))
+                current = []
+        current.append(char)
+    if current:
+        words.append("".join(current))
+    return words
+
+
+def _capitalize_word(word: str) -> str:
+    """Uppercase the first character of a word and lowercase the rest."""
+    return word[:1].upper() + word[1:].lower()
+
+
+def to_camel_case(input_str: str) -> str:
+    """
+    Convert a string to camelCase.
+    
+    Words are split as in to_snake_case, at separators and case
+    transitions, so existing identifiers and acronyms are handled. The
+    first word is lowercased and every later word gets an initial capital
+    with the rest lowercased. A word that starts with a digit has no letter
+    to uppercase, so numbers attach to the preceding word.
+    
+    Args:
+        input_str: The string to convert
+        
+    Returns:
+        The camelCase identifier
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> to_camel_case("hello world")
+        'helloWorld'
+        >>> to_camel_case("item 2 count")
+        'item2Count'
+        >>> to_camel_case("XMLHttpRequest")
+        'xmlHttpRequest'
+    """
+    _validate_input(input_str)
+    
+    words = _split_identifier_words(input_str)
+    if not words:
+        return ""
+    return words[0].lower() + "".join(map(_capitalize_word, words[1:]))
+
+
+def to_pascal_case(input_str: str) -> str:
+    """
+    Convert a string to PascalCase.
+    
+    Identical to to_camel_case except that the first word is capitalized
+    as well.
+    
+    Args:
+        input_str: The string to convert
+        
+    Returns:
+        The PascalCase identifier
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> to_pascal_case("hello_world")
+        'HelloWorld'
+        >>> to_pascal_case("HTTPServer")
+        'HttpServer'
+    """
+    _validate_input(input_str)
+    
+    return "".join(map(_capitalize_word, _split_identifier_words(input_str)))
+
+
+def to_snake_case(input_str: str) -> str:
+    """
+    Convert text or an identifier to snake_case.
+    
+    Words are split at runs of whitespace and punctuation and at case
+    transitions, so existing camelCase and PascalCase input is handled.
+    Consecutive capitals are kept together as an acronym. The words are
+    lowercased and joined with single underscores; no leading or trailing
+    underscore is produced.
+    
+    Args:
+        input_str: The string to convert
+        
+    Returns:
+        The snake_case identifier
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> to_snake_case("helloWorld")
+        'hello_world'
+        >>> to_snake_case("parseHTTPResponse")
+        'parse_http_response'
+    """
+    _validate_input(input_str)
+    
+    return "_".join(word.lower() for word in _split_identifier_words(input_str))
+
+
+def to_kebab_case(input_str: str) -> str:
+    """
+    Convert text or an identifier to kebab-case.
+    
+    Identical to to_snake_case except that words are joined with hyphens.
+    
+    Args:
+        input_str: The string to convert
+        
+    Returns:
+        The kebab-case identifier
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> to_kebab_case("HelloWorld")
+        'hello-world'
+        >>> to_kebab_case("  Hello,   World! ")
+        'hello-world'
+    """
+    _validate_input(input_str)
+    
+    return "-".join(word.lower() for word in _split_identifier_words(input_str))
+
+
+def to_screaming_snake_case(input_str: str) -> str:
+    """
+    Convert text or an identifier to SCREAMING_SNAKE_CASE.
+    
+    Identical to to_snake_case except that words are uppercased.
+    
+    Args:
+        input_str: The string to convert
+        
+    Returns:
+        The SCREAMING_SNAKE_CASE identifier
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> to_screaming_snake_case("maxRetryCount")
+        'MAX_RETRY_COUNT'
+    """
+    _validate_input(input_str)
+    
+    return "_".join(word.upper() for word in _split_identifier_words(input_str))
+
+
+def to_dot_case(input_str: str) -> str:
+    """
+    Convert text or an identifier to dot.case.
+    
+    Identical to to_snake_case except that words are joined with periods.
+    
+    Args:
+        input_str: The string to convert
+        
+    Returns:
+        The dot.case identifier
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> to_dot_case("userProfileId")
+        'user.profile.id'
+    """
+    _validate_input(input_str)
+    
+    return ".".join(word.lower() for word in _split_identifier_words(input_str))
+
+
+def to_train_case(input_str: str) -> str:
+    """
+    Convert text or an identifier to Train-Case.
+    
+    Words are split as in to_snake_case. Each word is written with an
+    initial capital and the rest lowercased, and words are joined with
+    hyphens, as in HTTP header names.
+    
+    Args:
+        input_str: The string to convert
+        
+    Returns:
+        The Train-Case identifier
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> to_train_case("user_profile_id")
+        'User-Profile-Id'
+        >>> to_train_case("contentType")
+        'Content-Type'
+    """
+    _validate_input(input_str)
+    
+    return "-".join(
+        word[:1].upper() + word[1:].lower()
+        for word in _split_identifier_words(input_str)
+    )
+
+
+def to_path_case(input_str: str) -> str:
+    """
+    Convert text or an identifier to path/case.
+    
+    Identical to to_snake_case except that words are joined with slashes.
+    
+    Args:
+        input_str: The string to convert
+        
+    Returns:
+        The path/case identifier
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> to_path_case("User Profile ID")
+        'user/profile/id'
+    """
+    _validate_input(input_str)
+    
+    return "/".join(word.lower() for word in _split_identifier_words(input_str))
+
+
+class CaseConverter:
+    """
+    Identifier case conversion with a configurable table of acronyms.
+    
+    Like the module functions, a CaseConverter splits its input at case
+    transitions as well as separators, but acronyms from its table are
+    written in their canonical form instead of being capitalized like other
+    words. This makes conversions round-trip, e.g. "userID" to "user_id"
+    and back.
+    
+    Acronyms with internal capitals, such as "iOS" or "eBay", are kept as
+    one word when splitting instead of being broken at their capitals.
+    
+    Attributes:
+        acronyms (Dict[str, str]): Canonical acronym spellings, keyed by
+            their case-folded form.
+        
+    Example:
+        >>> converter = CaseConverter(acronyms=["ID", "HTTP"])
+        >>> converter.to_snake_case("userID")
+        'user_id'
+        >>> converter.to_camel_case("user_id")
+        'userID'
+        >>> converter.to_pascal_case("http-server")
+        'HTTPServer'
+    """
+    
+    # The most words an acronym can be split into by case transitions.
+    _MAX_ACRONYM_PARTS = 4
+    
+    def __init__(self, *, acronyms: Iterable[str] = ()) -> None:
+        """
+        Initialize the converter.
+        
+        Args:
+            acronyms: Words to write in the given spelling, such as "ID",
+                "HTTP" or "iOS", matched case-insensitively. Defaults to
+                none.
+        """
+        self.acronyms = {acronym.casefold(): acronym for acronym in acronyms}
+    
+    def _split_words(self, input_str: str) -> List[str]:
+        """Split like to_snake_case, rejoining acronyms split at capitals."""
+        _validate_input(input_str)
+        
+        words = _split_identifier_words(input_str)
+        if not self.acronyms:
+            return words
+        merged: List[str] = []
+        index = 0
+        while index < len(words):
+            end = min(len(words), index + self._MAX_ACRONYM_PARTS)
+            while end > index + 1:
+                if "".join(words[index:end]).casefold() in self.acronyms:
+                    break
+                end -= 1
+            merged.append("".join(words[index:end]))
+            index = end
+        return merged
+    
+    def _capitalize(self, word: str) -> str:
+        """Write a word in its acronym spelling or with an initial capital."""
+        acronym = self.acronyms.get(word.casefold())
+        if acronym is not None:
+            return acronym
+        return _capitalize_word(word)
+    
+    def to_camel_case(self, input_str: str) -> str:
+        """
+        Convert text or an identifier to camelCase.
+        
+        The first word is lowercased, even if it is an acronym.
+        
+        Raises:
+            TypeError: If input is not a string
+            ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        """
+        words = self._split_words(input_str)
+        if not words:
+            return ""
+        return words[0].lower() + "".join(map(self._capitalize, words[1:]))
+    
+    def to_pascal_case(self, input_str: str) -> str:
+        """
+        Convert text or an identifier to PascalCase.
+        
+        Raises:
+            TypeError: If input is not a string
+            ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        """
+        return "".join(map(self._capitalize, self._split_words(input_str)))
+    
+    def to_snake_case(self, input_str: str) -> str:
+        """
+        Convert text or an identifier to snake_case.
+        
+        Raises:
+            TypeError: If input is not a string
+            ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        """
+        return "_".join(word.lower() for word in self._split_words(input_str))
+    
+    def to_kebab_case(self, input_str: str) -> str:
+        """
+        Convert text or an identifier to kebab-case.
+        
+        Raises:
+            TypeError: If input is not a string
+            ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        """
+        return "-".join(word.lower() for word in self._split_words(input_str))
+    
+    def to_screaming_snake_case(self, input_str: str) -> str:
+        """
+        Convert text or an identifier to SCREAMING_SNAKE_CASE.
+        
+        Raises:
+            TypeError: If input is not a string
+            ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        """
+        return "_".join(word.upper() for word in self._split_words(input_str))
+
+
+class CaseStyle(Enum):
+    """Case styles recognized by detect_case."""
+    
+    TITLE = "Title Case"
+    LOWER = "lower case"
+    UPPER = "UPPER CASE"
+    SNAKE = "snake_case"
+    SCREAMING_SNAKE = "SCREAMING_SNAKE_CASE"
+    KEBAB = "kebab-case"
+    TRAIN = "Train-Case"
+    DOT = "dot.case"
+    PATH = "path/case"
+    CAMEL = "camelCase"
+    PASCAL = "PascalCase"
+    MIXED = "mixed"
+
+
+@dataclass
+class CaseDetection:
+    """
+    The result of detect_case.
+    
+    Attributes:
+        style: The most likely style, or CaseStyle.MIXED if none fits
+        confidence: 1 divided by the number of candidate styles, so 1.0
+            when exactly one style fits; 1.0 for MIXED when the input has
+            words but fits no style, and 0.0 when it has no words at all
+        candidates: Every style the input is valid in, most likely first
+    """
+    
+    style: CaseStyle
+    confidence: float
+    candidates: Tuple[CaseStyle, ...]
+
+
+def _is_lower(word: str) -> bool:
+    """Return True if a word has no uppercase letters."""
+    return word == word.lower()
+
+
+def _is_upper(word: str) -> bool:
+    """Return True if a word has no lowercase letters."""
+    return word == word.upper()
+
+
+def _is_capitalized(word: str) -> bool:
+    """Return True if only the first letter of a word may be uppercase."""
+    return _is_upper(word[:1]) and _is_lower(word[1:])
+
+
+def _is_capitalized_or_acronym(word: str) -> bool:
+    """Return True if a word is capitalized or entirely uppercase."""
+    return _is_capitalized(word) or _is_upper(word)
+
+
+# For each style: the separator between words, and the tests for the
+# first word and for every later word, in order of preference.
+_CASE_STYLE_RULES: Tuple[
+    Tuple[CaseStyle, str, Callable[[str], bool], Callable[[str], bool]], ...
+] = (
+    (CaseStyle.TITLE, " ", _is_capitalized, _is_capitalized),
+    (CaseStyle.LOWER, " ", _is_lower, _is_lower),
+    (CaseStyle.UPPER, " ", _is_upper, _is_upper),
+    (CaseStyle.SNAKE, "_", _is_lower, _is_lower),
+    (CaseStyle.SCREAMING_SNAKE, "_", _is_upper, _is_upper),
+    (CaseStyle.KEBAB, "-", _is_lower, _is_lower),
+    (CaseStyle.TRAIN, "-", _is_capitalized, _is_capitalized),
+    (CaseStyle.DOT, ".", _is_lower, _is_lower),
+    (CaseStyle.PATH, "/", _is_lower, _is_lower),
+    (CaseStyle.CAMEL, "", _is_lower, _is_capitalized_or_acronym),
+    (CaseStyle.PASCAL, "", _is_capitalized_or_acronym, _is_capitalized_or_acronym),
+)
+
+
+def detect_case(input_str: str) -> CaseDetection:
+    """
+    Detect which case style a string is written in.
+    
+    Words are split as in to_snake_case. A style fits if joining the
+    words with its separator reproduces the input exactly and every word
+    has the casing the style requires; camelCase and PascalCase accept
+    all-uppercase acronyms such as "userID". A single word fits several
+    styles ("user" is valid snake_case, kebab-case and camelCase), which
+    lowers the confidence.
+    
+    Args:
+        input_str: The text or identifier to inspect
+        
+    Returns:
+        A CaseDetection with the most likely style, a confidence between
+        0.0 and 1.0 and all candidate styles
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> detect_case("user_profile_id").style
+        <CaseStyle.SNAKE: 'snake_case'>
+        >>> detect_case("userID").confidence
+        1.0
+        >>> len(detect_case("user").candidates)
+        6
+    """
+    _validate_input(input_str)
+    
+    words = _split_identifier_words(input_str)
+    if not words:
+        return CaseDetection(CaseStyle.MIXED, 0.0, ())
+    candidates = tuple(
+        style
+        for style, separator, first_test, rest_test in _CASE_STYLE_RULES
+        if separator.join(words) == input_str
+        and first_test(words[0])
+        and all(map(rest_test, words[1:]))
+    )
+    if not candidates:
+        return CaseDetection(CaseStyle.MIXED, 1.0, ())
+    return CaseDetection(candidates[0], 1.0 / len(candidates), candidates)
@@ -0,0 +1,261 @@
+"""Detection, removal and shortcode conversion of emoji."""
+
+import re
+import unicodedata
+from typing import Dict, Iterator, List, Mapping, Optional, Tuple
+
+from src.string_utils import (
+    _EXTENDED_PICTOGRAPHIC,
+    _grapheme_break_property,
+    _grapheme_end,
+    _validate_input,
+)
+
+
+# Common emoji by shortcode, following the names used by GitHub and Slack.
+# Where an emoji has several names, the first is used when converting to
+# shortcodes. The skin tone shortcodes follow an emoji to modify it, as in
+# ":wave::skin-tone-3:".
+DEFAULT_SHORTCODES: Dict[str, str] = {
+    "smile": "😄", "grin": "😁", "joy": "😂", "rofl": "🤣", "smiley": "😃",
+    "sweat_smile": "😅", "wink": "😉", "blush": "😊", "heart_eyes": "😍",
+    "kissing_heart": "😘", "slightly_smiling_face": "🙂", "upside_down_face": "🙃",
+    "thinking": "🤔", "neutral_face": "😐", "roll_eyes": "🙄", "confused": "😕",
+    "sleeping": "😴", "sunglasses": "😎", "partying_face": "🥳", "cry": "😢", "sob": "😭",
+    "scream": "😱", "angry": "😠", "rage": "😡", "thumbsup": "👍", "+1": "👍",
+    "thumbsdown": "👎", "-1": "👎", "clap": "👏", "wave": "👋", "ok_hand": "👌",
+    "pray": "🙏", "muscle": "💪", "raised_hands": "🙌", "point_up": "☝\ufe0f",
+    "point_right": "👉", "eyes": "👀", "heart": "❤\ufe0f", "broken_heart": "💔",
+    "sparkles": "✨", "fire": "🔥", "star": "⭐", "tada": "🎉", "rocket": "🚀", "100": "💯",
+    "warning": "⚠\ufe0f", "x": "❌", "white_check_mark": "✅",
+    "heavy_check_mark": "✔\ufe0f", "question": "❓", "exclamation": "❗", "bulb": "💡",
+    "bug": "🐛", "zap": "⚡", "coffee": "☕", "beer": "🍺", "pizza": "🍕", "cake": "🍰",
+    "dog": "🐶", "cat": "🐱", "sunny": "☀\ufe0f", "cloud": "☁\ufe0f", "umbrella": "☔",
+    "snowflake": "❄\ufe0f", "rainbow": "🌈", "earth_africa": "🌍", "memo": "📝",
+    "lock": "🔒", "key": "🔑", "email": "📧", "iphone": "📱", "computer": "💻",
+    "gift": "🎁", "bell": "🔔", "hourglass": "⌛", "calendar": "📅",
+    "chart_with_upwards_trend": "📈", "construction": "🚧", "recycle": "♻\ufe0f",
+    "us": "🇺🇸", "gb": "🇬🇧", "family": "👨\u200d👩\u200d👧", "technologist": "🧑\u200d💻",
+    "rainbow_flag": "🏳\ufe0f\u200d🌈", "skin-tone-2": "🏻", "skin-tone-3": "🏼",
+    "skin-tone-4": "🏽", "skin-tone-5": "🏾", "skin-tone-6": "🏿",
+}
+
+_SHORTCODE = re.compile(r":([a-z0-9_+\-]+):")
+# One or more adjacent shortcodes, as in ":wave::skin-tone-3:", set off
+# from surrounding words and colons so that "1:100:1" and "rw:x:r" are not
+# taken for shortcodes.
+_SHORTCODE_RUN = re.compile(r"(?<![\w:])(?::[a-z0-9_+\-]+:)+(?![\w:])")
+
+
+def _is_skin_tone(char: str) -> bool:
+    """Return True for the emoji skin tone modifiers U+1F3FB to U+1F3FF."""
+    return "\U0001f3fb" <= char <= "\U0001f3ff"
+
+
+def _is_emoji(cluster: str) -> bool:
+    """
+    Return True if a grapheme cluster is displayed as an emoji.
+    
+    Flags, keycaps, and clusters with a pictograph that is drawn as an
+    emoji by default (wide), or is made one by the emoji presentation
+    selector U+FE0F, a skin tone or a zero-width joiner, are emoji.
+    Pictographs drawn as text, such as a bare "©", are not.
+    """
+    if "\ufe0e" in cluster:
+        return False
+    if _grapheme_break_property(cluster[0]) == "Regional_Indicator":
+        return len(cluster) > 1
+    if "\u20e3" in cluster:
+        return True
+    for char in cluster:
+        if _EXTENDED_PICTOGRAPHIC.match(char):
+            return (
+                unicodedata.east_asian_width(char) == "W"
+                or "\ufe0f" in cluster
+                or "\u200d" in cluster
+                or any(_is_skin_tone(mark) for mark in cluster)
+            )
+    return False
+
+
+def _iter_clusters(input_str: str) -> Iterator[Tuple[str, bool]]:
+    """Yield each grapheme cluster of input with whether it is an emoji."""
+    start = 0
+    while start < len(input_str):
+        end = _grapheme_end(input_str, start)
+        cluster = input_str[start:end]
+        yield cluster, _is_emoji(cluster)
+        start = end
+
+
+def has_emoji(input_str: str) -> bool:
+    """
+    Check whether a string contains any emoji.
+    
+    Args:
+        input_str: The string to check
+        
+    Returns:
+        True if at least one emoji is present
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> has_emoji("Ship it \\U0001f680")
+        True
+        >>> has_emoji("(c) 2024 \\u00a9")
+        False
+    """
+    _validate_input(input_str)
+    
+    return any(is_emoji for _, is_emoji in _iter_clusters(input_str))
+
+
+def count_emoji(input_str: str) -> int:
+    """
+    Count the emoji in a string.
+    
+    An emoji sequence counts once: a family joined with zero-width
+    joiners, an emoji with a skin tone, a flag or a keycap is one emoji.
+    
+    Args:
+        input_str: The string to scan
+        
+    Returns:
+        The number of emoji
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> family = "\\U0001f468\\u200d\\U0001f469\\u200d\\U0001f467"
+        >>> count_emoji("\\U0001f44d\\U0001f3fd " + family)
+        2
+    """
+    _validate_input(input_str)
+    
+    return sum(1 for _, is_emoji in _iter_clusters(input_str) if is_emoji)
+
+
+def strip_emoji(input_str: str) -> str:
+    """
+    Remove every emoji from a string.
+    
+    Whole emoji sequences are removed, including their joiners, skin tones
+    and presentation selectors. Whitespace around the emoji is kept.
+    
+    Args:
+        input_str: The string to clean
+        
+    Returns:
+        The string without emoji
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> strip_emoji("Done \\u2705!")
+        'Done !'
+    """
+    _validate_input(input_str)
+    
+    return "".join(
+        cluster for cluster, is_emoji in _iter_clusters(input_str) if not is_emoji
+    )
+
+
+def _shortcodes_for(cluster: str, names: Dict[str, str]) -> Optional[str]:
+    """Return the shortcodes for an emoji cluster, or None if it has no name."""
+    key = cluster.replace("\ufe0f", "")
+    if key in names:
+        return f":{names[key]}:"
+    base = "".join(char for char in key if not _is_skin_tone(char))
+    tones = [char for char in key if _is_skin_tone(char)]
+    if base != key and base in names and all(tone in names for tone in tones):
+        return "".join(f":{names[char]}:" for char in [base, *tones])
+    return None
+
+
+def emoji_to_shortcodes(
+    input_str: str, shortcodes: Optional[Mapping[str, str]] = None
+) -> str:
+    """
+    Replace emoji with their :shortcode: names.
+    
+    An emoji is matched with or without its emoji presentation selector.
+    An emoji with a skin tone that has no name of its own is written as
+    the name of the base emoji followed by the skin tone, as in
+    ":wave::skin-tone-3:". Emoji without a name are kept.
+    
+    Args:
+        input_str: The string to convert
+        shortcodes: Names mapped to emoji. Defaults to DEFAULT_SHORTCODES;
+            where several names map to one emoji, the first is used.
+        
+    Returns:
+        The string with named emoji replaced by shortcodes
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> emoji_to_shortcodes("Ship it \\U0001f680")
+        'Ship it :rocket:'
+        >>> emoji_to_shortcodes("\\U0001f44b\\U0001f3fc")
+        ':wave::skin-tone-3:'
+    """
+    _validate_input(input_str)
+    
+    names: Dict[str, str] = {}
+    for name, emoji in (shortcodes or DEFAULT_SHORTCODES).items():
+        names.setdefault(emoji.replace("\ufe0f", ""), name)
+    
+    parts: List[str] = []
+    for cluster, is_emoji in _iter_clusters(input_str):
+        name = _shortcodes_for(cluster, names) if is_emoji else None
+        parts.append(cluster if name is None else name)
+    return "".join(parts)
+
+
+def shortcodes_to_emoji(
+    input_str: str, shortcodes: Optional[Mapping[str, str]] = None
+) -> str:
+    """
+    Replace :shortcode: names with their emoji.
+    
+    Unknown names, and text that only looks like a shortcode because it
+    is joined to a word or another colon, such as the ":30:" in
+    "12:30:45" or the ":100:" in "1:100:1", are kept.
+    
+    Args:
+        input_str: The string to convert
+        shortcodes: Names mapped to emoji. Defaults to DEFAULT_SHORTCODES.
+        
+    Returns:
+        The string with known shortcodes replaced by emoji
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> shortcodes_to_emoji("Ship it :rocket:") == "Ship it \\U0001f680"
+        True
+        >>> shortcodes_to_emoji("at 12:30:45 :unknown:")
+        'at 12:30:45 :unknown:'
+    """
+    _validate_input(input_str)
+    
+    table = shortcodes or DEFAULT_SHORTCODES
+    
+    def replace(match: "re.Match[str]") -> str:
+        return table.get(match.group(1), match.group(0))
+    
+    return _SHORTCODE_RUN.sub(
+        lambda run: _SHORTCODE.sub(replace, run.group(0)), input_str
+    )
@@ -0,0 +1,9 @@
+"""
+Content filtering package initialization.
+
+This module exports the profanity filter for easy importing.
+"""
+
+from .profanity import MatchMode, ProfanityFilter, ProfanityMatch
+
+__all__ = ['MatchMode', 'ProfanityFilter', 'ProfanityMatch']
@@ -0,0 +1,236 @@
+"""Detection and masking of unwanted words from user-supplied word lists."""
+
+import re
+from dataclasses import dataclass
+from enum import Enum
+from typing import Dict, Iterable, List
+
+from src.string_utils import _validate_input
+
+
+class MatchMode(Enum):
+    """How a listed word must appear in text to match."""
+    
+    WHOLE_WORD = "whole_word"
+    SUBSTRING = "substring"
+
+
+@dataclass
+class ProfanityMatch:
+    """
+    An occurrence of a listed word found by a ProfanityFilter.
+    
+    Attributes:
+        word: The listed word that matched, as given to the filter
+        text: The text that matched, such as "h3ll" for "hell"
+        start: Index of its first character in the input
+        end: Index just past its last character, so that
+            text == input[start:end]
+    """
+    
+    word: str
+    text: str
+    start: int
+    end: int
+
+
+# Characters commonly written in place of each letter.
+LEET_SUBSTITUTIONS: Dict[str, str] = {
+    "a": "4@",
+    "b": "8",
+    "e": "3",
+    "g": "69",
+    "i": "1!|",
+    "l": "1|",
+    "o": "0",
+    "s": "5$",
+    "t": "7+",
+    "z": "2",
+}
+
+# Combining marks after a match belong to its last character.
+_COMBINING_MARK = "[\u0300-\u036f\u1ab0-\u1aff\u1dc0-\u1dff\u20d0-\u20ff\ufe20-\ufe2f]"
+_MARKS = re.compile(_COMBINING_MARK)
+
+
+def _letter_pattern(letter: str, normalize_leet: bool) -> str:
+    """Return a pattern matching one letter of a word, case-insensitively."""
+    substitutes = LEET_SUBSTITUTIONS.get(letter, "") if normalize_leet else ""
+    if not substitutes:
+        return re.escape(letter)
+    return "[" + re.escape(letter + substitutes) + "]"
+
+
+class ProfanityFilter:
+    """
+    A reusable filter finding and masking words from a word list.
+    
+    Words are matched case-insensitively. With leet normalization, digits
+    and symbols commonly written in place of letters, such as "@" for "a"
+    and "1" for "i", match the letter they stand for (see
+    LEET_SUBSTITUTIONS), so "h3ll" matches "hell".
+    
+    Attributes:
+        words (List[str]): The listed words, longest first.
+        mode (MatchMode): Whether words match only as whole words or
+            anywhere inside other words.
+        normalize_leet (bool): Whether leetspeak substitutes match the
+            letters they stand for.
+        mask_chars (str): The characters masked text is replaced with.
+        
+    Example:
+        >>> profanity = ProfanityFilter(["darn", "heck"])
+        >>> profanity.mask("Darn it, what the h3ck!")
+        '**** it, what the ****!'
+    """
+    
+    def __init__(
+        self,
+        words: Iterable[str],
+        *,
+        mode: MatchMode = MatchMode.WHOLE_WORD,
+        normalize_leet: bool = True,
+        mask_chars: str = "*",
+    ) -> None:
+        """
+        Initialize the filter.
+        
+        Args:
+            words: The words to find, matched case-insensitively. A word
+                may contain spaces or punctuation, which must then appear
+                as written.
+            mode: MatchMode.WHOLE_WORD to match words only where they are
+                not part of a longer word, or MatchMode.SUBSTRING to match
+                them anywhere, which also finds compounds but flags
+                innocent words that contain a listed one, such as "class"
+                for "ass". Defaults to MatchMode.WHOLE_WORD.
+            normalize_leet: Whether digits and symbols written in place of
+                letters match those letters. Defaults to True.
+            mask_chars: The characters each masked character is replaced
+                with, used in turn, so "*" masks "darn" as "****" and
+                "@#$%&" masks it as "@#$%". Defaults to "*".
+            
+        Raises:
+            TypeError: If words is a string rather than an iterable of
+                strings, a word is not a string, mode is not a MatchMode,
+                or mask_chars is not a string
+            ValueError: If a word or mask_chars is empty
+        """
+        if isinstance(words, str):
+            raise TypeError("Words must be an iterable of strings, not a string")
+        words = list(words)
+        for word in words:
+            if not isinstance(word, str):
+                raise TypeError(f"Words must be strings, got {type(word).__name__}")
+            if not word:
+                raise ValueError("Words cannot be empty")
+        if not isinstance(mode, MatchMode):
+            raise TypeError(f"Mode must be a MatchMode, got {type(mode).__name__}")
+        if not isinstance(mask_chars, str):
+            raise TypeError(
+                f"Mask characters must be a string, got {type(mask_chars).__name__}"
+            )
+        if not mask_chars:
+            raise ValueError("Mask characters cannot be empty")
+        
+        # Longer words first, so "darnit" is found whole rather than "darn".
+        self.words = sorted(dict.fromkeys(words), key=len, reverse=True)
+        self.mode = mode
+        self.normalize_leet = normalize_leet
+        self.mask_chars = mask_chars
+        self._pattern = self._compile()
+    
+    def _compile(self) -> "re.Pattern[str]":
+        """Build one pattern matching any listed word."""
+        # One group per word, so the group that matched names the word.
+        alternatives = [
+            "("
+            + "".join(
+                _letter_pattern(letter, self.normalize_leet)
+                for letter in word.lower()
+            )
+            + ")"
+            for word in self.words
+        ]
+        body = "(?:" + "|".join(alternatives) + ")" if alternatives else "(?!)"
+        if self.mode is MatchMode.SUBSTRING:
+            return re.compile(body + _COMBINING_MARK + "*", re.IGNORECASE)
+        # Letters, digits and combining marks, but not underscores,
+        # continue a word.
+        word_char = "(?:[^\\W_]|" + _COMBINING_MARK + ")"
+        return re.compile(
+            f"(?<!{word_char}){body}{_COMBINING_MARK}*(?!{word_char})",
+            re.IGNORECASE,
+        )
+    
+    def find(self, input_str: str) -> List[ProfanityMatch]:
+        """
+        Find the listed words in a string.
+        
+        Matches do not overlap; where two listed words could match at the
+        same position, the longer one is reported.
+        
+        Args:
+            input_str: The text to search
+            
+        Returns:
+            The matches, in order of position
+            
+        Raises:
+            TypeError: If input is not a string
+            ValueError: If input fails validation (see MAX_STRING_LENGTH)
+            
+        Examples:
+            >>> ProfanityFilter(["heck"]).find("What the H3CK?")
+            [ProfanityMatch(word='heck', text='H3CK', start=9, end=13)]
+        """
+        _validate_input(input_str)
+        
+        return [
+            ProfanityMatch(
+                self.words[match.lastindex - 1],
+                match.group(),
+                match.start(),
+                match.end(),
+            )
+            for match in self._pattern.finditer(input_str)
+        ]
+    
+    def contains(self, input_str: str) -> bool:
+        """
+        Return whether a string contains any listed word.
+        
+        Raises:
+            TypeError: If input is not a string
+            ValueError: If input fails validation (see MAX_STRING_LENGTH)
+            
+        Examples:
+            >>> ProfanityFilter(["ass"]).contains("a classic")
+            False
+        """
+        _validate_input(input_str)
+        
+        return self._pattern.search(input_str) is not None
+    
+    def mask(self, input_str: str) -> str:
+        """
+        Replace each character of each listed word found with mask_chars.
+        
+        Raises:
+            TypeError: If input is not a string
+            ValueError: If input fails validation (see MAX_STRING_LENGTH)
+            
+        Examples:
+            >>> ProfanityFilter(["ass"], mode=MatchMode.SUBSTRING).mask("a classic")
+            'a cl***ic'
+        """
+        _validate_input(input_str)
+        
+        mask_chars = self.mask_chars
+        return self._pattern.sub(
+            lambda match: "".join(
+                mask_chars[index % len(mask_chars)]
+                for index in range(len(_MARKS.sub("", match.group())))
+            ),
+            input_str,
+        )
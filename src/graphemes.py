@@ -0,0 +1,183 @@
+"""Segmentation of text into user-perceived characters (UAX #29 graphemes)."""
+
+from typing import Iterator, List, Optional
+
+from src.string_utils import _grapheme_end, _validate_input
+
+
+def iter_graphemes(input_str: str) -> Iterator[str]:
+    """
+    Iterate over the extended grapheme clusters of a string.
+    
+    A grapheme cluster is what a reader perceives as one character: a
+    letter with its combining marks, a Hangul syllable, a flag made of two
+    regional indicators, or an emoji joined with zero-width joiners and
+    skin-tone modifiers. CR LF counts as one cluster.
+    
+    Args:
+        input_str: The string to segment
+        
+    Returns:
+        An iterator over the clusters, in order; joined together they
+        reproduce the input
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> [ascii(cluster) for cluster in iter_graphemes("e\\u0301!")]
+        ["'e\\\\u0301'", "'!'"]
+    """
+    _validate_input(input_str)
+    
+    return _iter_graphemes(input_str)
+
+
+def _iter_graphemes(input_str: str) -> Iterator[str]:
+    """Yield the clusters of a string that has already passed validation."""
+    start = 0
+    while start < len(input_str):
+        end = _grapheme_end(input_str, start)
+        yield input_str[start:end]
+        start = end
+
+
+def grapheme_count(input_str: str) -> int:
+    """
+    Count the user-perceived characters in a string.
+    
+    Args:
+        input_str: The string to measure
+        
+    Returns:
+        The number of extended grapheme clusters
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> grapheme_count("\\U0001f1f3\\U0001f1ff flag")
+        6
+    """
+    _validate_input(input_str)
+    
+    return sum(1 for _ in _iter_graphemes(input_str))
+
+
+def grapheme_slice(
+    input_str: str, start: Optional[int] = None, end: Optional[int] = None
+) -> str:
+    """
+    Slice a string by grapheme cluster positions instead of code points.
+    
+    Positions follow Python slice semantics, including negative positions
+    counted from the end, but count whole clusters, so the result never
+    splits a character from its combining marks or an emoji sequence.
+    
+    Args:
+        input_str: The string to slice
+        start: Index of the first cluster to keep. Defaults to the start.
+        end: Index just past the last cluster to keep. Defaults to the end.
+        
+    Returns:
+        The clusters from start up to, but not including, end
+        
+    Raises:
+        TypeError: If input is not a string or a position is not an integer
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> ascii(grapheme_slice("ab\\U0001f44d\\U0001f3fdcd", 1, 3))
+        "'b\\\\U0001f44d\\\\U0001f3fd'"
+    """
+    _validate_input(input_str)
+    for position in (start, end):
+        if position is not None and not isinstance(position, int):
+            raise TypeError(
+                f"Position must be an integer, got {type(position).__name__}"
+            )
+    
+    return "".join(list(_iter_graphemes(input_str))[start:end])
+
+
+def _check_chunk_size(size: int) -> None:
+    """Raise unless size is a valid chunk size."""
+    if not isinstance(size, int):
+        raise TypeError(f"Size must be an integer, got {type(size).__name__}")
+    if size < 1:
+        raise ValueError(f"Size must be at least 1, got {size}")
+
+
+def chunk_code_points(input_str: str, size: int) -> List[str]:
+    """
+    Split a string into pieces of at most size code points.
+    
+    Every piece but the last has exactly size code points. A piece may
+    end between a letter and its combining marks or inside an emoji
+    sequence; use chunk_graphemes to keep those together.
+    
+    Args:
+        input_str: The string to split
+        size: The maximum number of code points in each piece
+        
+    Returns:
+        The pieces in order; joined together they reproduce the input.
+        An empty string gives no pieces.
+        
+    Raises:
+        TypeError: If input is not a string or size is not an integer
+        ValueError: If input fails validation (see MAX_STRING_LENGTH) or
+            size is less than 1
+        
+    Examples:
+        >>> chunk_code_points("abcdefg", 3)
+        ['abc', 'def', 'g']
+    """
+    _validate_input(input_str)
+    _check_chunk_size(size)
+    
+    return [input_str[start : start + size] for start in range(0, len(input_str), size)]
+
+
+def chunk_graphemes(input_str: str, size: int) -> List[str]:
+    """
+    Split a string into pieces of at most size grapheme clusters.
+    
+    Pieces only end at cluster boundaries, so a letter is never separated
+    from its combining marks and an emoji sequence is never split. Every
+    piece but the last has exactly size clusters.
+    
+    Args:
+        input_str: The string to split
+        size: The maximum number of clusters in each piece
+        
+    Returns:
+        The pieces in order; joined together they reproduce the input.
+        An empty string gives no pieces.
+        
+    Raises:
+        TypeError: If input is not a string or size is not an integer
+        ValueError: If input fails validation (see MAX_STRING_LENGTH) or
+            size is less than 1
+        
+    Examples:
+        >>> [ascii(piece) for piece in chunk_graphemes("ae\\u0301io", 2)]
+        ["'ae\\\\u0301'", "'io'"]
+    """
+    _validate_input(input_str)
+    _check_chunk_size(size)
+    
+    chunks: List[str] = []
+    start = end = count = 0
+    while end < len(input_str):
+        end = _grapheme_end(input_str, end)
+        count += 1
+        if count == size:
+            chunks.append(input_str[start:end])
+            start = end
+            count = 0
+    if start < len(input_str):
+        chunks.append(input_str[start:])
+    return chunks
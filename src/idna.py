@@ -0,0 +1,418 @@
+"""Punycode and IDNA2008 conversion of internationalized domain names."""
+
+import re
+import unicodedata
+from typing import List, Tuple
+
+from src.string_utils import _char_script, _validate_input
+
+
+_ACE_PREFIX = "xn--"
+_MAX_LABEL_LENGTH = 63
+_MAX_DOMAIN_LENGTH = 253
+
+# Full stops that separate labels (RFC 3490, section 3.1).
+_LABEL_SEPARATORS = re.compile("[.\u3002\uff0e\uff61]")
+
+# Characters whose IDNA2008 status is an exception to the rules derived
+# from their properties (RFC 5892, section 2.6).
+_PVALID_EXCEPTIONS = frozenset("\u00df\u03c2\u06fd\u06fe\u0f0b\u3007")
+_DISALLOWED_EXCEPTIONS = frozenset(
+    "\u0640\u07fa\u302e\u302f\u3031\u3032\u3033\u3034\u3035\u303b"
+)
+
+# Bidi classes allowed in labels of right-to-left and left-to-right
+# direction (RFC 5893, section 2).
+_RTL_BIDI_CLASSES = frozenset(
+    ("R", "AL", "AN", "EN", "ES", "CS", "ET", "ON", "BN", "NSM")
+)
+_LTR_BIDI_CLASSES = frozenset(("L", "EN", "ES", "CS", "ET", "ON", "BN", "NSM"))
+
+# Blocks of the cursive scripts whose letters join on both sides (Joining
+# Type D) unless listed below: Arabic, Syriac, Arabic Supplement, N'Ko,
+# part of Arabic Extended-A, and Mongolian.
+_DUAL_JOINING_RANGES = (
+    (0x0620, 0x06FF),
+    (0x0710, 0x074F),
+    (0x0750, 0x077F),
+    (0x07CA, 0x07EA),
+    (0x08A0, 0x08B4),
+    (0x1820, 0x18AA),
+)
+# Letters in those blocks that only join to the letter before them (Joining
+# Type R), such as Arabic alef, dal and waw, and that do not join at all.
+_RIGHT_JOINING_RANGES = (
+    (0x0622, 0x0625), (0x0627, 0x0627), (0x0629, 0x0629), (0x062F, 0x0632),
+    (0x0648, 0x0648), (0x0671, 0x0673), (0x0675, 0x0677), (0x0688, 0x0699),
+    (0x06C0, 0x06C0), (0x06C3, 0x06CB), (0x06CD, 0x06CD), (0x06CF, 0x06CF),
+    (0x06D2, 0x06D3), (0x06D5, 0x06D5), (0x06EE, 0x06EF), (0x0710, 0x0710),
+    (0x0715, 0x0719), (0x071E, 0x071E), (0x0728, 0x0728), (0x072A, 0x072A),
+    (0x072C, 0x072C), (0x072F, 0x072F), (0x074D, 0x074D), (0x0759, 0x075B),
+    (0x076B, 0x076C), (0x0771, 0x0771), (0x0773, 0x0774), (0x0778, 0x0779),
+    (0x08AA, 0x08AC), (0x08AE, 0x08AE), (0x08B1, 0x08B2),
+)
+_NON_JOINING_RANGES = (
+    (0x0621, 0x0621), (0x0674, 0x0674), (0x08AD, 0x08AD), (0x1880, 0x1884),
+)
+
+
+def to_punycode(input_str: str) -> str:
+    """
+    Encode a string with Punycode (RFC 3492).
+    
+    This is the raw encoding, without the "xn--" prefix of domain labels;
+    use idna_to_ascii for domain names.
+    
+    Args:
+        input_str: The string to encode
+        
+    Returns:
+        The ASCII Punycode encoding
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> to_punycode("bücher")
+        'bcher-kva'
+    """
+    _validate_input(input_str)
+    
+    return input_str.encode("punycode").decode("ascii")
+
+
+def from_punycode(input_str: str) -> str:
+    """
+    Decode a Punycode string (RFC 3492).
+    
+    Args:
+        input_str: The Punycode to decode, without an "xn--" prefix
+        
+    Returns:
+        The decoded string
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH), is
+            not ASCII, or is not valid Punycode
+        
+    Examples:
+        >>> from_punycode("bcher-kva")
+        'bücher'
+    """
+    _validate_input(input_str)
+    if not input_str.isascii():
+        raise ValueError(f"Punycode must be ASCII, got {input_str!r}")
+    
+    try:
+        return input_str.encode("ascii").decode("punycode")
+    except UnicodeError as error:
+        raise ValueError(f"Invalid Punycode: {input_str!r}") from error
+
+
+def _map_label(label: str) -> str:
+    """Lowercase a label, fold full and half width forms, and apply NFC."""
+    chars = []
+    for char in label.lower():
+        if unicodedata.decomposition(char).startswith(("<wide>", "<narrow>")):
+            char = unicodedata.normalize("NFKC", char)
+        chars.append(char)
+    return unicodedata.normalize("NFC", "".join(chars))
+
+
+def _is_pvalid(char: str) -> bool:
+    """
+    Return True if a character is allowed in a domain label (PVALID).
+    
+    Follows the rules of RFC 5892: lowercase and caseless letters, marks
+    and digits are allowed unless case folding or NFKC would change them.
+    Characters needing context, such as U+200D ZERO WIDTH JOINER, are
+    checked separately by _check_context.
+    """
+    if char in _PVALID_EXCEPTIONS:
+        return True
+    if char in _DISALLOWED_EXCEPTIONS:
+        return False
+    if char.isascii():
+        return char.islower() or char.isdigit() or char == "-"
+    if unicodedata.category(char) not in ("Ll", "Lo", "Lm", "Mn", "Mc", "Nd"):
+        return False
+    folded = unicodedata.normalize("NFKC", char).casefold()
+    return unicodedata.normalize("NFKC", folded) == char
+
+
+def _joining_type(char: str) -> str:
+    """
+    Return the Joining_Type of a character: "D", "R", "T" or "U".
+    
+    Marks and format characters are transparent (T). Letters of the
+    cursive scripts in _DUAL_JOINING_RANGES are dual-joining (D) or
+    right-joining (R); everything else is non-joining (U). The few
+    left-joining (L) characters, in scripts such as Manichaean, are not
+    covered, so a label using them with U+200C is rejected.
+    """
+    if unicodedata.category(char) in ("Mn", "Me", "Cf") and char not in "\u200c\u200d":
+        return "T"
+    if unicodedata.category(char) != "Lo":
+        return "U"
+    code = ord(char)
+    for ranges, joining_type in (
+        (_NON_JOINING_RANGES, "U"),
+        (_RIGHT_JOINING_RANGES, "R"),
+        (_DUAL_JOINING_RANGES, "D"),
+    ):
+        if any(start <= code <= end for start, end in ranges):
+            return joining_type
+    return "U"
+
+
+def _joins_across(label: str, index: int) -> bool:
+    """
+    Return True if the U+200C at index separates two joining letters.
+    
+    This is the regular expression of RFC 5892, appendix A.1:
+    (Joining_Type:{L,D})(Joining_Type:T)*U+200C(Joining_Type:T)*
+    (Joining_Type:{R,D}). Persian uses it to keep a suffix such as "ha"
+    from joining the word before it.
+    """
+    before = index - 1
+    while before >= 0 and _joining_type(label[before]) == "T":
+        before -= 1
+    after = index + 1
+    while after < len(label) and _joining_type(label[after]) == "T":
+        after += 1
+    return (
+        before >= 0
+        and after < len(label)
+        and _joining_type(label[before]) == "D"
+        and _joining_type(label[after]) in ("R", "D")
+    )
+
+
+def _check_context(label: str, index: int) -> bool:
+    """Return True if a CONTEXTJ or CONTEXTO character is valid in place."""
+    char = label[index]
+    before = label[index - 1] if index else ""
+    after = label[index + 1 : index + 2]
+    if char in "\u200c\u200d" and before and unicodedata.combining(before) == 9:
+        return True
+    if char == "\u200c":
+        return _joins_across(label, index)
+    if char == "\u200d":
+        return False
+    if char == "\u00b7":
+        return before == "l" and after == "l"
+    if char == "\u0375":
+        return bool(after) and _char_script(after) == "Greek"
+    if char in "\u05f3\u05f4":
+        return bool(before) and _char_script(before) == "Hebrew"
+    if char == "\u30fb":
+        return any(
+            _char_script(other) in ("Hiragana", "Katakana", "Han")
+            for other in label
+            if other != "\u30fb"
+        )
+    if "\u0660" <= char <= "\u0669":
+        return not any("\u06f0" <= other <= "\u06f9" for other in label)
+    if "\u06f0" <= char <= "\u06f9":
+        return not any("\u0660" <= other <= "\u0669" for other in label)
+    return False
+
+
+def _is_context_character(char: str) -> bool:
+    """Return True for characters only allowed in some contexts."""
+    return (
+        char in "\u200c\u200d\u00b7\u0375\u05f3\u05f4\u30fb"
+        or "\u0660" <= char <= "\u0669"
+        or "\u06f0" <= char <= "\u06f9"
+    )
+
+
+def _check_label(label: str) -> None:
+    """Raise ValueError unless label is a valid U-label or LDH label."""
+    if not label:
+        raise ValueError("Domain name contains an empty label")
+    if label[2:4] == "--":
+        raise ValueError(
+            f"Invalid domain label {label!r}: hyphens in the third and "
+            f"fourth positions"
+        )
+    if label.startswith("-") or label.endswith("-"):
+        raise ValueError(
+            f"Invalid domain label {label!r}: starts or ends with a hyphen"
+        )
+    if unicodedata.category(label[0]).startswith("M"):
+        raise ValueError(
+            f"Invalid domain label {label!r}: starts with a combining mark"
+        )
+    if not unicodedata.is_normalized("NFC", label):
+        raise ValueError(f"Invalid domain label {label!r}: not in NFC")
+    for index, char in enumerate(label):
+        if _is_context_character(char):
+            valid = _check_context(label, index)
+        else:
+            valid = _is_pvalid(char)
+        if not valid:
+            raise ValueError(
+                f"Invalid domain label {label!r}: disallowed character "
+                f"<U+{ord(char):04X}> at index {index}"
+            )
+
+
+def _check_bidi(label: str) -> None:
+    """Raise ValueError unless label satisfies the Bidi Rule (RFC 5893)."""
+    classes = [unicodedata.bidirectional(char) for char in label]
+    trimmed = list(classes)
+    while trimmed and trimmed[-1] == "NSM":
+        trimmed.pop()
+    if classes[0] in ("R", "AL"):
+        valid = (
+            all(bidi_class in _RTL_BIDI_CLASSES for bidi_class in classes)
+            and bool(trimmed)
+            and trimmed[-1] in ("R", "AL", "EN", "AN")
+            and not ("EN" in classes and "AN" in classes)
+        )
+    elif classes[0] == "L":
+        valid = (
+            all(bidi_class in _LTR_BIDI_CLASSES for bidi_class in classes)
+            and bool(trimmed)
+            and trimmed[-1] in ("L", "EN")
+        )
+    else:
+        valid = False
+    if not valid:
+        raise ValueError(
+            f"Invalid domain label {label!r}: mixes text directions"
+        )
+
+
+def _domain_labels(domain: str) -> Tuple[List[Tuple[str, str]], bool]:
+    """
+    Validate a domain name and convert its labels.
+    
+    Returns each label as a (U-label, A-label) pair, and whether the name
+    ends with a root full stop.
+    """
+    _validate_input(domain)
+    if not domain:
+        raise ValueError("Domain name cannot be empty")
+    
+    labels = _LABEL_SEPARATORS.split(domain)
+    rooted = len(labels) > 1 and not labels[-1]
+    if rooted:
+        labels.pop()
+    
+    converted: List[Tuple[str, str]] = []
+    for label in labels:
+        label = _map_label(label)
+        if label.startswith(_ACE_PREFIX):
+            try:
+                unicode_label = from_punycode(label[len(_ACE_PREFIX) :])
+                _check_label(unicode_label)
+            except ValueError as error:
+                raise ValueError(
+                    f"Invalid domain label {label!r}: not a valid A-label"
+                ) from error
+            if (
+                unicode_label.isascii()
+                or _ACE_PREFIX + to_punycode(unicode_label) != label
+            ):
+                raise ValueError(
+                    f"Invalid domain label {label!r}: not a valid A-label"
+                )
+            ascii_label = label
+        else:
+            _check_label(label)
+            unicode_label = label
+            if label.isascii():
+                ascii_label = label
+            else:
+                ascii_label = _ACE_PREFIX + to_punycode(label)
+        if len(ascii_label) > _MAX_LABEL_LENGTH:
+            raise ValueError(
+                f"Invalid domain label {unicode_label!r}: longer than "
+                f"{_MAX_LABEL_LENGTH} characters when encoded"
+            )
+        converted.append((unicode_label, ascii_label))
+    
+    if any(
+        unicodedata.bidirectional(char) in ("R", "AL", "AN")
+        for unicode_label, _ in converted
+        for char in unicode_label
+    ):
+        for unicode_label, _ in converted:
+            _check_bidi(unicode_label)
+    
+    length = sum(len(ascii_label) + 1 for _, ascii_label in converted) - 1
+    if length > _MAX_DOMAIN_LENGTH:
+        raise ValueError(
+            f"Domain name is longer than {_MAX_DOMAIN_LENGTH} characters "
+            f"when encoded"
+        )
+    return converted, rooted
+
+
+def idna_to_ascii(domain: str) -> str:
+    """
+    Convert a domain name to its ASCII form (IDNA2008 ToASCII).
+    
+    Labels are lowercased, full-width and half-width characters are folded
+    (as suggested by RFC 5895), and the result is normalized to NFC. Each
+    label is then validated against IDNA2008 (RFC 5891, 5892 and 5893),
+    and labels that are not ASCII are encoded as "xn--" Punycode
+    A-labels. Ideographic full stops separate labels like ".", and a
+    trailing root "." is kept.
+    
+    Args:
+        domain: The domain name, in Unicode or ASCII form
+        
+    Returns:
+        The domain name with every label in ASCII
+        
+    Raises:
+        TypeError: If domain is not a string
+        ValueError: If domain fails validation (see MAX_STRING_LENGTH), is
+            empty, has an empty label, a label of more than 63 or a name
+            of more than 253 characters when encoded, a character not
+            allowed by IDNA2008, a malformed A-label, or breaks the rules
+            for right-to-left labels
+        
+    Examples:
+        >>> idna_to_ascii("Bücher.example")
+        'xn--bcher-kva.example'
+        >>> idna_to_ascii("fass.de") != idna_to_ascii("faß.de")
+        True
+    """
+    labels, rooted = _domain_labels(domain)
+    return ".".join(ascii_label for _, ascii_label in labels) + (
+        "." if rooted else ""
+    )
+
+
+def idna_to_unicode(domain: str) -> str:
+    """
+    Convert a domain name to its Unicode form (IDNA2008 ToUnicode).
+    
+    "xn--" A-labels are decoded, and every label is mapped and validated
+    as in idna_to_ascii, so the result is safe to display and compare.
+    
+    Args:
+        domain: The domain name, in ASCII or Unicode form
+        
+    Returns:
+        The domain name with A-labels decoded
+        
+    Raises:
+        TypeError: If domain is not a string
+        ValueError: If domain is not a valid domain name, as in
+            idna_to_ascii
+        
+    Examples:
+        >>> idna_to_unicode("xn--bcher-kva.EXAMPLE")
+        'bücher.example'
+    """
+    labels, rooted = _domain_labels(domain)
+    return ".".join(unicode_label for unicode_label, _ in labels) + (
+        "." if rooted else ""
+    )
@@ -0,0 +1,180 @@
+"""Conversion of HTML markup to plain text."""
+
+import re
+from html.parser import HTMLParser
+from typing import List, Optional, Tuple
+
+from src.string_utils import ControlPolicy, _apply_control_policy, _validate_input
+
+
+# Elements whose content is not text to be read.
+_HIDDEN_ELEMENTS = frozenset({"script", "style", "template", "noscript"})
+
+# Elements that start a new paragraph, separated by a blank line.
+_PARAGRAPH_ELEMENTS = frozenset(
+    {
+        "p", "h1", "h2", "h3", "h4", "h5", "h6", "blockquote", "pre",
+        "ul", "ol", "dl", "table", "section", "article", "header",
+        "footer", "nav", "aside", "main", "figure", "hr",
+    }
+)
+
+# Elements that start a new line.
+_LINE_ELEMENTS = frozenset(
+    {"div", "li", "dt", "dd", "tr", "caption", "figcaption", "address"}
+)
+
+# Table cells, separated from the previous cell in their row by a tab.
+_CELL_ELEMENTS = frozenset({"td", "th"})
+
+_HTML_WHITESPACE = re.compile("[ \t\n\r\f]+")
+_UNSAFE_LINK_SCHEMES = ("javascript:", "vbscript:", "data:")
+
+
+class _TextExtractor(HTMLParser):
+    """Collect the readable text of an HTML document."""
+    
+    def __init__(self, keep_links: bool) -> None:
+        super().__init__(convert_charrefs=True)
+        self._keep_links = keep_links
+        self._parts: List[str] = []
+        # Line breaks to insert before the next text: 1 or 2 (a paragraph).
+        self._breaks = 0
+        self._hidden_depth = 0
+        self._pre_depth = 0
+        # Whether the text starts inside a pre element, where leading
+        # whitespace is content.
+        self._starts_in_pre = False
+        # For each open link: its target and where its text starts in _parts.
+        self._links: List[Tuple[Optional[str], int]] = []
+    
+    def handle_starttag(
+        self, tag: str, attrs: List[Tuple[str, Optional[str]]]
+    ) -> None:
+        if tag in _HIDDEN_ELEMENTS:
+            self._hidden_depth += 1
+        elif tag == "br":
+            self._emit("\n")
+        elif tag == "a":
+            self._links.append((dict(attrs).get("href"), len(self._parts)))
+        elif tag in _CELL_ELEMENTS:
+            self._start_cell()
+        self._start_block(tag)
+        if tag == "pre":
+            self._pre_depth += 1
+    
+    def handle_endtag(self, tag: str) -> None:
+        if tag in _HIDDEN_ELEMENTS:
+            self._hidden_depth = max(self._hidden_depth - 1, 0)
+        elif tag == "pre":
+            self._pre_depth = max(self._pre_depth - 1, 0)
+        elif tag == "a" and self._links:
+            target, start = self._links.pop()
+            self._end_link(target, start)
+        self._start_block(tag)
+    
+    def handle_startendtag(
+        self, tag: str, attrs: List[Tuple[str, Optional[str]]]
+    ) -> None:
+        if tag == "br":
+            self._emit("\n")
+        else:
+            self._start_block(tag)
+    
+    def handle_data(self, data: str) -> None:
+        if self._hidden_depth:
+            return
+        if not self._pre_depth:
+            data = _HTML_WHITESPACE.sub(" ", data)
+            if data.startswith(" ") and self._at_line_start():
+                data = data[1:]
+        self._emit(data)
+    
+    def text(self) -> str:
+        """Return the text collected so far."""
+        text = re.sub("[ \t]+\n", "\n", "".join(self._parts)).rstrip()
+        return text.lstrip("\n") if self._starts_in_pre else text.lstrip()
+    
+    def _start_block(self, tag: str) -> None:
+        if tag in _PARAGRAPH_ELEMENTS:
+            self._breaks = 2
+        elif tag in _LINE_ELEMENTS:
+            self._breaks = max(self._breaks, 1)
+    
+    def _start_cell(self) -> None:
+        if self._parts and not self._breaks and not self._hidden_depth:
+            self._parts[-1] = self._parts[-1].rstrip(" ")
+            self._parts.append("\t")
+    
+    def _at_line_start(self) -> bool:
+        return (
+            not self._parts
+            or self._breaks > 0
+            or self._parts[-1].endswith((" ", "\t", "\n"))
+        )
+    
+    def _emit(self, text: str) -> None:
+        if not text or self._hidden_depth:
+            return
+        if self._breaks and self._parts:
+            self._parts[-1] = self._parts[-1].rstrip(" ")
+            self._parts.append("\n" * self._breaks)
+            text = text.lstrip(" ")
+        self._breaks = 0
+        if text:
+            if not self._parts:
+                self._starts_in_pre = self._pre_depth > 0
+            self._parts.append(text)
+    
+    def _end_link(self, target: Optional[str], start: int) -> None:
+        if not self._keep_links or target is None:
+            return
+        target = _HTML_WHITESPACE.sub("", target)
+        if not target or target.lower().startswith(_UNSAFE_LINK_SCHEMES):
+            return
+        label = "".join(self._parts[start:]).strip()
+        if target == label:
+            return
+        self._emit(f" [{target}]" if label else f"[{target}]")
+
+
+def strip_html(input_str: str, keep_links: bool = False) -> str:
+    """
+    Convert HTML to plain text by removing tags and decoding entities.
+    
+    Tags and comments are removed, and the content of script, style,
+    template and noscript elements is dropped. Named and numeric
+    character references such as "&amp;" and "&#233;" are decoded.
+    Whitespace is collapsed as a browser would, except inside pre
+    elements; paragraphs, headings and lists are separated by a blank
+    line, other block elements and <br> start a new line, and table
+    cells in a row are separated by a tab. Control characters produced
+    by decoding, such as "&#12;", are removed, so the result can be
+    passed on to capitalize_words.
+    
+    Args:
+        input_str: The HTML to convert
+        keep_links: If True, the target of each link is kept in brackets
+            after its text, as in "docs [https://example.com]". Targets
+            equal to the link text and javascript:, vbscript: and data:
+            targets are left out. Defaults to False.
+        
+    Returns:
+        The text of the document
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> strip_html("<p>Fish &amp; chips</p><p>Caf&#233; <b>open</b></p>")
+        'Fish & chips\\n\\nCafé open'
+        >>> strip_html('See <a href="https://example.com">docs</a>.', keep_links=True)
+        'See docs [https://example.com].'
+    """
+    _validate_input(input_str)
+    
+    extractor = _TextExtractor(keep_links)
+    extractor.feed(input_str)
+    extractor.close()
+    return _apply_control_policy(extractor.text(), ControlPolicy.STRIP)
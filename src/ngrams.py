@@ -0,0 +1,220 @@
+"""Character and word n-grams for search indexes and similarity measures."""
+
+import hashlib
+from collections import deque
+from typing import AbstractSet, Deque, FrozenSet, Hashable, Iterable, Iterator, List
+
+from src.graphemes import _iter_graphemes
+from src.string_utils import _WHITESPACE_WORD, _validate_input
+
+
+def _check_n(n: int) -> None:
+    """Raise unless n is a valid n-gram length."""
+    if not isinstance(n, int):
+        raise TypeError(f"N must be an integer, got {type(n).__name__}")
+    if n < 1:
+        raise ValueError(f"N must be at least 1, got {n}")
+
+
+def _windows(items: Iterable[str], n: int, separator: str) -> Iterator[str]:
+    """Yield each run of n consecutive items, joined with separator."""
+    window: Deque[str] = deque(maxlen=n)
+    for item in items:
+        window.append(item)
+        if len(window) == n:
+            yield separator.join(window)
+
+
+def iter_ngrams(input_str: str, n: int) -> Iterator[str]:
+    """
+    Iterate over the character n-grams of a string.
+    
+    Characters are grapheme clusters (see iter_graphemes), so an n-gram
+    never splits a letter from its combining marks or an emoji sequence.
+    The n-grams are produced one at a time, so memory use does not grow
+    with the length of the input.
+    
+    Args:
+        input_str: The string to scan
+        n: The number of characters in each n-gram
+        
+    Returns:
+        An iterator over the n-grams, in order; none if the string has
+        fewer than n characters
+        
+    Raises:
+        TypeError: If input is not a string or n is not an integer
+        ValueError: If input fails validation (see MAX_STRING_LENGTH) or
+            n is less than 1
+        
+    Examples:
+        >>> list(iter_ngrams("night", 3))
+        ['nig', 'igh', 'ght']
+    """
+    _validate_input(input_str)
+    _check_n(n)
+    
+    return _windows(_iter_graphemes(input_str), n, "")
+
+
+def ngrams(input_str: str, n: int) -> List[str]:
+    """
+    List the character n-grams of a string.
+    
+    Equivalent to list(iter_ngrams(input_str, n)).
+    
+    Args:
+        input_str: The string to scan
+        n: The number of characters in each n-gram
+        
+    Returns:
+        The n-grams in order, including repeats
+        
+    Raises:
+        TypeError: If input is not a string or n is not an integer
+        ValueError: If input fails validation (see MAX_STRING_LENGTH) or
+            n is less than 1
+        
+    Examples:
+        >>> ngrams("cafe\\u0301", 2) == ["ca", "af", "fe\\u0301"]
+        True
+    """
+    return list(iter_ngrams(input_str, n))
+
+
+def iter_word_ngrams(input_str: str, n: int) -> Iterator[str]:
+    """
+    Iterate over the word n-grams of a string.
+    
+    Words are separated by whitespace, as in word_count, and each n-gram
+    is n consecutive words joined by a single space. The n-grams are
+    produced one at a time, so memory use does not grow with the length
+    of the input.
+    
+    Args:
+        input_str: The string to scan
+        n: The number of words in each n-gram
+        
+    Returns:
+        An iterator over the n-grams, in order; none if the string has
+        fewer than n words
+        
+    Raises:
+        TypeError: If input is not a string or n is not an integer
+        ValueError: If input fails validation (see MAX_STRING_LENGTH) or
+            n is less than 1
+        
+    Examples:
+        >>> list(iter_word_ngrams("the quick  brown fox", 2))
+        ['the quick', 'quick brown', 'brown fox']
+    """
+    _validate_input(input_str)
+    _check_n(n)
+    
+    words = (match.group(0) for match in _WHITESPACE_WORD.finditer(input_str))
+    return _windows(words, n, " ")
+
+
+def word_ngrams(input_str: str, n: int) -> List[str]:
+    """
+    List the word n-grams of a string.
+    
+    Equivalent to list(iter_word_ngrams(input_str, n)).
+    
+    Args:
+        input_str: The string to scan
+        n: The number of words in each n-gram
+        
+    Returns:
+        The n-grams in order, including repeats
+        
+    Raises:
+        TypeError: If input is not a string or n is not an integer
+        ValueError: If input fails validation (see MAX_STRING_LENGTH) or
+            n is less than 1
+        
+    Examples:
+        >>> word_ngrams("to be or not to be", 3)[:2]
+        ['to be or', 'be or not']
+    """
+    return list(iter_word_ngrams(input_str, n))
+
+
+def _stable_hash(text: str) -> int:
+    """Return a 64-bit hash of text that is the same in every process."""
+    digest = hashlib.blake2b(text.encode("utf-8"), digest_size=8).digest()
+    return int.from_bytes(digest, "big")
+
+
+def shingles(input_str: str, k: int) -> FrozenSet[int]:
+    """
+    Compute the hashed word k-shingles of a document.
+    
+    A k-shingle is a word n-gram of k words (see iter_word_ngrams). Words
+    are case-folded first, so documents differing only in case or spacing
+    have the same shingles. Each shingle is hashed to a 64-bit integer
+    with BLAKE2b, which, unlike the built-in hash, is stable across
+    processes and Python versions, so shingle sets can be stored and
+    compared later.
+    
+    Args:
+        input_str: The document text
+        k: The number of words in each shingle; 3 to 5 suits short
+            documents, 5 to 10 long ones
+        
+    Returns:
+        The set of shingle hashes; empty if the document has fewer than
+        k words
+        
+    Raises:
+        TypeError: If input is not a string or k is not an integer
+        ValueError: If input fails validation (see MAX_STRING_LENGTH) or
+            k is less than 1
+        
+    Examples:
+        >>> shingles("The quick brown fox", 3) == shingles("the QUICK  brown fox", 3)
+        True
+        >>> len(shingles("the quick brown fox", 3))
+        2
+    """
+    _validate_input(input_str)
+    
+    return frozenset(
+        _stable_hash(shingle)
+        for shingle in iter_word_ngrams(input_str.casefold(), k)
+    )
+
+
+def jaccard_similarity(
+    first: AbstractSet[Hashable], second: AbstractSet[Hashable]
+) -> float:
+    """
+    Compute the Jaccard similarity of two sets, such as shingle sets.
+    
+    The similarity is the size of the intersection divided by the size of
+    the union: 1.0 for identical sets and 0.0 for disjoint ones. Two empty
+    sets are considered identical. Near-duplicate documents typically
+    have a shingle similarity above 0.8.
+    
+    Args:
+        first: The first set
+        second: The second set
+        
+    Returns:
+        The similarity, from 0.0 to 1.0
+        
+    Raises:
+        TypeError: If either argument is not a set
+        
+    Examples:
+        >>> jaccard_similarity({1, 2, 3}, {2, 3, 4})
+        0.5
+    """
+    for value in (first, second):
+        if not isinstance(value, AbstractSet):
+            raise TypeError(f"Arguments must be sets, got {type(value).__name__}")
+    
+    union = len(first | second)
+    if not union:
+        return 1.0
+    return len(first & second) / union
@@ -0,0 +1,227 @@
+"""Incremental segmentation of streams into graphemes, words and sentences."""
+
+import codecs
+from typing import IO, Any, Callable, Iterable, Iterator, List, Tuple
+
+from src.string_utils import (
+    DEFAULT_ABBREVIATIONS,
+    STREAM_CHUNK_SIZE,
+    _check_characters,
+    _grapheme_end,
+    _sentence_spans,
+    _strip_punctuation,
+    _unicode_word_spans,
+)
+
+
+def _check_chunk_size(chunk_size: int) -> None:
+    """Raise unless chunk_size is a valid read size."""
+    if chunk_size < 1:
+        raise ValueError(f"Chunk size must be at least 1, got {chunk_size}")
+
+
+def _read_text(reader: IO[Any], chunk_size: int) -> Iterator[str]:
+    """Yield validated text from a text or binary stream until it is exhausted."""
+    decoder = codecs.getincrementaldecoder("utf-8")()
+    offset = 0
+    while True:
+        chunk = reader.read(chunk_size)
+        if isinstance(chunk, bytes):
+            text = decoder.decode(chunk, final=not chunk)
+        else:
+            text = chunk
+        _check_characters(text, offset)
+        offset += len(text)
+        if text:
+            yield text
+        if not chunk:
+            return
+
+
+def _scan(
+    reader: IO[Any],
+    chunk_size: int,
+    find_spans: Callable[[str], List[Tuple[int, int]]],
+    context_start: Callable[[str, int], int],
+    tail_start: Callable[[str], int],
+) -> Iterator[str]:
+    """
+    Yield the segments of a stream found by find_spans.
+    
+    The last segment found in the text read so far may continue in the
+    next chunk, so it is held back and segmented again with that chunk,
+    together with the text before it from context_start on, which may
+    affect where it ends. When no new segment is found, tail_start gives
+    the index from which the text may still affect later segments;
+    everything before it is dropped.
+    """
+    pending = ""
+    # Index in pending of the first segment not yielded yet.
+    held = 0
+    # Text read since pending was last segmented. Segmenting only once it
+    # is half of pending keeps the time linear when a segment spans many
+    # chunks.
+    unscanned = 0
+    for text in _read_text(reader, chunk_size):
+        pending += text
+        unscanned += len(text)
+        if unscanned * 2 < len(pending):
+            continue
+        unscanned = 0
+        spans = [span for span in find_spans(pending) if span[0] >= held]
+        if not spans:
+            keep = tail_start(pending)
+            pending = pending[keep:]
+            held = max(held - keep, 0)
+            continue
+        for start, end in spans[:-1]:
+            yield pending[start:end]
+        start = spans[-1][0]
+        keep = context_start(pending, start)
+        pending = pending[keep:]
+        held = start - keep
+    for start, end in find_spans(pending):
+        if start >= held:
+            yield pending[start:end]
+
+
+def _grapheme_spans(text: str) -> List[Tuple[int, int]]:
+    """Return the start and end index of each grapheme cluster."""
+    spans: List[Tuple[int, int]] = []
+    start = 0
+    while start < len(text):
+        end = _grapheme_end(text, start)
+        spans.append((start, end))
+        start = end
+    return spans
+
+
+def _segment_start(text: str, start: int) -> int:
+    """Return start: segments that do not depend on the text before them."""
+    return start
+
+
+def _after_last_space(text: str) -> int:
+    """Return the index just past the last whitespace character, or 0."""
+    for index in range(len(text) - 1, -1, -1):
+        if text[index].isspace():
+            return index + 1
+    return 0
+
+
+def scan_graphemes(
+    reader: IO[Any], chunk_size: int = STREAM_CHUNK_SIZE
+) -> Iterator[str]:
+    """
+    Iterate over the grapheme clusters of a stream.
+    
+    The clusters are the same as those iter_graphemes finds in the whole
+    input, even when a cluster is split across chunks. The input is read
+    in chunks of chunk_size as the iterator advances, so memory use stays
+    bounded regardless of the total size and MAX_STRING_LENGTH does not
+    apply. Binary streams are decoded as UTF-8 incrementally.
+    
+    Args:
+        reader: A text or binary stream with a read(size) method
+        chunk_size: The number of characters or bytes to read at a time
+        
+    Returns:
+        An iterator over the clusters, in order
+        
+    Raises:
+        ValueError: If chunk_size is less than 1, or, during iteration, if
+            the input contains a lone surrogate or a disallowed control
+            character or a binary stream is not valid UTF-8
+            (UnicodeDecodeError)
+        
+    Examples:
+        >>> import io
+        >>> stream = io.BytesIO("ne\\u0301e".encode())
+        >>> [ascii(cluster) for cluster in scan_graphemes(stream, 2)]
+        ["'n'", "'e\\\\u0301'", "'e'"]
+    """
+    _check_chunk_size(chunk_size)
+    
+    return _scan(reader, chunk_size, _grapheme_spans, _segment_start, len)
+
+
+def scan_words(reader: IO[Any], chunk_size: int = STREAM_CHUNK_SIZE) -> Iterator[str]:
+    """
+    Iterate over the words of a stream, found with UAX #29 word boundaries.
+    
+    Words are found as by a Capitalizer with WordBoundaries.UNICODE:
+    contractions and numbers with separators stay together, while
+    punctuation, hyphens and whitespace separate words and are skipped.
+    A word split across chunks is found whole. Memory use is bounded by
+    the chunk size and the longest word, and MAX_STRING_LENGTH does not
+    apply. Binary streams are decoded as UTF-8 incrementally.
+    
+    Args:
+        reader: A text or binary stream with a read(size) method
+        chunk_size: The number of characters or bytes to read at a time
+        
+    Returns:
+        An iterator over the words, in order
+        
+    Raises:
+        ValueError: If chunk_size is less than 1, or, during iteration, if
+            the input contains a lone surrogate or a disallowed control
+            character or a binary stream is not valid UTF-8
+            (UnicodeDecodeError)
+        
+    Examples:
+        >>> import io
+        >>> list(scan_words(io.StringIO("Don't stop, 3.14-fold!"), 4))
+        ["Don't", 'stop', '3.14', 'fold']
+    """
+    _check_chunk_size(chunk_size)
+    
+    return _scan(
+        reader, chunk_size, _unicode_word_spans, _segment_start, _after_last_space
+    )
+
+
+def scan_sentences(
+    reader: IO[Any],
+    chunk_size: int = STREAM_CHUNK_SIZE,
+    abbreviations: Iterable[str] = DEFAULT_ABBREVIATIONS,
+) -> Iterator[str]:
+    """
+    Iterate over the sentences of a stream.
+    
+    Sentences are found as by split_sentences, with the whitespace around
+    them removed, and a sentence split across chunks is found whole.
+    Memory use is bounded by the chunk size and the longest sentence, and
+    MAX_STRING_LENGTH does not apply. Binary streams are decoded as UTF-8
+    incrementally.
+    
+    Args:
+        reader: A text or binary stream with a read(size) method
+        chunk_size: The number of characters or bytes to read at a time
+        abbreviations: Words after which a period does not end a
+            sentence, as in split_sentences
+        
+    Returns:
+        An iterator over the sentences, in order
+        
+    Raises:
+        ValueError: If chunk_size is less than 1, or, during iteration, if
+            the input contains a lone surrogate or a disallowed control
+            character or a binary stream is not valid UTF-8
+            (UnicodeDecodeError)
+        
+    Examples:
+        >>> import io
+        >>> list(scan_sentences(io.StringIO("Hi Dr. Smith. How are you?"), 5))
+        ['Hi Dr. Smith.', 'How are you?']
+    """
+    _check_chunk_size(chunk_size)
+    
+    known = frozenset(_strip_punctuation(word).casefold() for word in abbreviations)
+    return _scan(
+        reader,
+        chunk_size,
+        lambda text: _sentence_spans(text, known),
+        lambda text, start: _after_last_space(text[:start]),
+        len,
+    )
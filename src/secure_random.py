@@ -0,0 +1,110 @@
+"""Cryptographically secure random strings for tokens, codes and identifiers."""
+
+import secrets
+from enum import Enum
+from typing import Union
+
+from src.base_encoding import BASE62_ALPHABET
+
+
+class Alphabet(Enum):
+    """The predefined character sets random_string draws from."""
+    
+    ALPHANUMERIC = BASE62_ALPHABET
+    HEX = "0123456789abcdef"
+    URL_SAFE = BASE62_ALPHABET + "-_"
+    # Without 0, 1, I, O, l and o, which are mistaken for one another.
+    NO_LOOKALIKES = "23456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnpqrstuvwxyz"
+
+
+def _check_alphabet(alphabet: Union[Alphabet, str]) -> str:
+    """Return the characters of alphabet, or raise if it would bias output."""
+    if isinstance(alphabet, Alphabet):
+        return alphabet.value
+    if not isinstance(alphabet, str):
+        raise TypeError(
+            f"Alphabet must be an Alphabet or a string, got {type(alphabet).__name__}"
+        )
+    if len(alphabet) < 2:
+        raise ValueError("Alphabet must have at least 2 characters")
+    if len(set(alphabet)) != len(alphabet):
+        raise ValueError("Alphabet cannot repeat characters")
+    return alphabet
+
+
+def random_string(
+    length: int, alphabet: Union[Alphabet, str] = Alphabet.ALPHANUMERIC
+) -> str:
+    """
+    Generate a cryptographically secure random string.
+    
+    Characters are drawn with the secrets module, from the operating
+    system's secure random source, so the result is suitable for
+    passwords, reset codes and API keys; the random module is not.
+    Sampling is bias-free: each character is equally likely, even when
+    the alphabet size is not a power of two, because draws that
+    would favour some characters are rejected and repeated rather than
+    reduced modulo the alphabet size.
+    
+    Each character adds log2(len(alphabet)) bits of entropy: about 5.95
+    for ALPHANUMERIC, so 22 characters give over 128 bits.
+    
+    Args:
+        length: The number of characters to generate
+        alphabet: An Alphabet, or a string of distinct characters to draw
+            from. Defaults to Alphabet.ALPHANUMERIC.
+        
+    Returns:
+        The random string
+        
+    Raises:
+        TypeError: If alphabet is not an Alphabet or a string
+        ValueError: If length is negative, or a custom alphabet has fewer
+            than 2 characters or repeats a character, which would make
+            some characters more likely than others
+        
+    Examples:
+        >>> len(random_string(22))
+        22
+        >>> set(random_string(100, Alphabet.HEX)) <= set("0123456789abcdef")
+        True
+        >>> random_string(6, "0123456789").isdigit()
+        True
+    """
+    characters = _check_alphabet(alphabet)
+    if length < 0:
+        raise ValueError(f"Length cannot be negative, got {length}")
+    
+    return "".join(secrets.choice(characters) for _ in range(length))
+
+
+def random_token(num_bytes: int = 32) -> str:
+    """
+    Generate a random token encoded as unpadded base64url.
+    
+    The token encodes num_bytes random bytes, so it carries 8 * num_bytes
+    bits of entropy and is about 4/3 as many characters long. It only
+    contains letters, digits, "-" and "_", so it can be used in URLs,
+    cookies and headers without escaping. Compare tokens with
+    secure_equal, never with ==.
+    
+    Args:
+        num_bytes: The number of random bytes. Defaults to 32 (256 bits),
+            and should be at least 16 for a secret.
+        
+    Returns:
+        The token
+        
+    Raises:
+        ValueError: If num_bytes is less than 1
+        
+    Examples:
+        >>> len(random_token())
+        43
+        >>> len(random_token(16))
+        22
+    """
+    if num_bytes < 1:
+        raise ValueError(f"Number of bytes must be at least 1, got {num_bytes}")
+    
+    return secrets.token_urlsafe(num_bytes)
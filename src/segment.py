@@ -0,0 +1,218 @@
+"""Dictionary-based word segmentation for languages written without spaces."""
+
+from bisect import bisect_right
+from typing import FrozenSet, Iterable, List, Tuple
+
+from src.string_utils import _char_script, _grapheme_end, _validate_input, _word_spans
+
+
+# Scripts whose words are not separated by spaces, and which are split into
+# words with the dictionary.
+_DICTIONARY_SCRIPTS = frozenset(
+    ("Han", "Hiragana", "Katakana", "Khmer", "Lao", "Myanmar", "Thai")
+)
+
+# Japanese iteration and prolonged sound marks, which have no script of
+# their own but only appear inside Japanese words.
+_JAPANESE_MARKS = "々〆〻ゝゞーヽヾ"
+
+
+def _cluster_script(cluster: str) -> str:
+    """Return the dictionary script of a grapheme cluster, or "" if none."""
+    if cluster[0] in _JAPANESE_MARKS:
+        return "Katakana" if cluster[0] in "ーヽヾ" else "Hiragana"
+    script = _char_script(cluster[0])
+    return script if script in _DICTIONARY_SCRIPTS else ""
+
+
+class Segmenter:
+    """
+    A longest-match word segmenter driven by a dictionary.
+    
+    Text is first split at whitespace, as in word_count. Within each
+    whitespace-delimited word, runs of Han, Hiragana, Katakana, Thai, Lao,
+    Khmer and Myanmar text are split further: at each position the longest
+    dictionary word is taken (forward maximum matching). A Han character
+    not in the dictionary is a word of its own; other unmatched characters
+    of one script, such as a katakana loanword, are kept together.
+    
+    Text in other scripts is left as it is, so "iPhone手机" splits into
+    "iPhone" and "手机". Punctuation stays attached to the word before it,
+    or to the word after it at the start of a whitespace-delimited word,
+    just as the comma in "hello," belongs to "hello".
+    
+    Dictionary words are matched exactly, so they should use the same
+    normalization form as the input (see normalize).
+    
+    Attributes:
+        words (FrozenSet[str]): The dictionary.
+        
+    Example:
+        >>> segmenter = Segmenter(["北京", "欢迎", "你"])
+        >>> segmenter.segment("北京欢迎你！")
+        ['北京', '欢迎', '你！']
+    """
+    
+    def __init__(self, words: Iterable[str]) -> None:
+        """
+        Initialize the segmenter.
+        
+        Args:
+            words: The dictionary words. Words made only of characters of
+                other scripts are accepted but never matched.
+            
+        Raises:
+            TypeError: If a word is not a string
+            ValueError: If a word is empty
+        """
+        self.words = _check_words(words)
+        self._max_length = max(map(len, self.words), default=1)
+    
+    def extend(self, words: Iterable[str]) -> "Segmenter":
+        """
+        Build a segmenter whose dictionary has extra words.
+        
+        Args:
+            words: The words to add
+            
+        Returns:
+            A new Segmenter; this one is unchanged
+            
+        Raises:
+            TypeError: If a word is not a string
+            ValueError: If a word is empty
+        """
+        return Segmenter(self.words | _check_words(words))
+    
+    def _split_run(self, text: str, start: int, end: int) -> List[Tuple[int, int]]:
+        """Split a run of dictionary-script text into word spans."""
+        boundaries = [start]
+        while boundaries[-1] < end:
+            boundaries.append(min(_grapheme_end(text, boundaries[-1]), end))
+        spans: List[Tuple[int, int]] = []
+        unmatched = ""
+        index = 0
+        while index < len(boundaries) - 1:
+            position = boundaries[index]
+            longest = bisect_right(boundaries, position + self._max_length) - 1
+            for next_index in range(longest, index, -1):
+                stop = boundaries[next_index]
+                if text[position:stop] in self.words:
+                    spans.append((position, stop))
+                    unmatched = ""
+                    index = next_index
+                    break
+            else:
+                stop = boundaries[index + 1]
+                script = _cluster_script(text[position:stop])
+                if script == unmatched and script != "Han":
+                    spans[-1] = (spans[-1][0], stop)
+                else:
+                    spans.append((position, stop))
+                unmatched = script
+                index += 1
+        return spans
+    
+    def _split_word(self, text: str, start: int, end: int) -> List[Tuple[int, int]]:
+        """Split a whitespace-delimited word into word spans."""
+        pieces: List[Tuple[int, int]] = []
+        run_start = index = start
+        in_run = False
+        while index < end:
+            cluster_end = min(_grapheme_end(text, index), end)
+            is_run = bool(_cluster_script(text[index:cluster_end]))
+            if is_run != in_run and index > run_start:
+                if in_run:
+                    pieces.extend(self._split_run(text, run_start, index))
+                else:
+                    pieces.append((run_start, index))
+                run_start = index
+            in_run = is_run
+            index = cluster_end
+        if in_run:
+            pieces.extend(self._split_run(text, run_start, end))
+        else:
+            pieces.append((run_start, end))
+        
+        spans: List[Tuple[int, int]] = []
+        for piece_start, piece_end in pieces:
+            if any(char.isalnum() for char in text[piece_start:piece_end]):
+                if spans and not any(
+                    char.isalnum() for char in text[spans[-1][0] : spans[-1][1]]
+                ):
+                    spans[-1] = (spans[-1][0], piece_end)
+                else:
+                    spans.append((piece_start, piece_end))
+            elif spans:
+                spans[-1] = (spans[-1][0], piece_end)
+            else:
+                spans.append((piece_start, piece_end))
+        return spans
+    
+    def spans(self, input_str: str) -> List[Tuple[int, int]]:
+        """
+        Find the words of a string as (start, end) index pairs.
+        
+        Spans index into input_str, so they can be used to change words in
+        place while keeping the text between them.
+        
+        Args:
+            input_str: The text to segment
+            
+        Returns:
+            The start and end index of each word, in order
+            
+        Raises:
+            TypeError: If input is not a string
+            ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        """
+        _validate_input(input_str)
+        
+        spans: List[Tuple[int, int]] = []
+        for start, end in _word_spans(input_str):
+            spans.extend(self._split_word(input_str, start, end))
+        return spans
+    
+    def segment(self, input_str: str) -> List[str]:
+        """
+        Split a string into words.
+        
+        Args:
+            input_str: The text to segment
+            
+        Returns:
+            The words, in order, without the whitespace between them
+            
+        Raises:
+            TypeError: If input is not a string
+            ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        """
+        return [input_str[start:end] for start, end in self.spans(input_str)]
+    
+    def word_count(self, input_str: str) -> int:
+        """
+        Count the words in a string.
+        
+        Args:
+            input_str: The text to count
+            
+        Returns:
+            The number of words
+            
+        Raises:
+            TypeError: If input is not a string
+            ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        """
+        return len(self.spans(input_str))
+
+
+def _check_words(words: Iterable[str]) -> FrozenSet[str]:
+    """Validate dictionary words and return them as a frozen set."""
+    checked = set()
+    for word in words:
+        if not isinstance(word, str):
+            raise TypeError(f"Words must be strings, got {type(word).__name__}")
+        if not word:
+            raise ValueError("Dictionary words cannot be empty")
+        checked.add(word)
+    return frozenset(checked)
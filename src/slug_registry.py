@@ -0,0 +1,210 @@
+"""Generation of unique slugs, with pluggable strategies for collisions."""
+
+import hashlib
+import secrets
+import threading
+from typing import Callable, Iterable, Optional, Set
+
+from src.base_encoding import BASE62_ALPHABET
+from src.transliterate import (
+    SlugOptions,
+    _check_slug_options,
+    _trim_slug,
+    slugify_transliterated,
+)
+
+
+# A collision strategy: given a slug that is taken and the attempt number,
+# starting at 2, return the suffix to try appending to it.
+SuffixStrategy = Callable[[str, int], str]
+
+
+def numeric_suffix(slug: str, attempt: int) -> str:
+    """
+    Suffix the attempt number, giving "post", "post-2", "post-3" and so on.
+    
+    Examples:
+        >>> numeric_suffix("post", 2)
+        '2'
+    """
+    return str(attempt)
+
+
+def hash_suffix(slug: str, attempt: int) -> str:
+    """
+    Suffix 7 hex digits of a SHA-256 hash of the slug and attempt number.
+    
+    The suffixes are deterministic, so the same sequence of collisions
+    gives the same slugs, but do not reveal how many similar slugs exist.
+    
+    Examples:
+        >>> hash_suffix("post", 2)
+        'de1690f'
+    """
+    return hashlib.sha256(f"{slug}:{attempt}".encode("utf-8")).hexdigest()[:7]
+
+
+def random_suffix(slug: str, attempt: int) -> str:
+    """
+    Suffix 6 random base62 characters, from the secrets module.
+    
+    With 62**6, about 57 billion, possible suffixes, the slugs cannot be
+    guessed from one another.
+    """
+    return "".join(secrets.choice(BASE62_ALPHABET) for _ in range(6))
+
+
+class SlugRegistry:
+    """
+    Issues slugs that are unique among all those it has issued.
+    
+    Text is converted with slugify_transliterated, and when the slug is
+    already taken a suffix from the collision strategy is appended after
+    the separator, trying again until a free slug is found. Slugs already
+    in use elsewhere, such as in a database, can be loaded at
+    construction so they are not issued again. All methods are
+    thread-safe: concurrent calls to issue never return the same slug.
+    
+    Attributes:
+        strategy (SuffixStrategy): Returns the suffix to try for a taken
+            slug and an attempt number, starting at 2.
+        options (SlugOptions): How text is converted to slugs.
+        max_attempts (int): How many suffixes are tried before giving up.
+        
+    Example:
+        >>> registry = SlugRegistry()
+        >>> registry.issue("Hello, World!")
+        'hello-world'
+        >>> registry.issue("Hello World")
+        'hello-world-2'
+        >>> registry.release("hello-world")
+        True
+        >>> registry.issue("hello world")
+        'hello-world'
+    """
+    
+    def __init__(
+        self,
+        strategy: SuffixStrategy = numeric_suffix,
+        *,
+        options: Optional[SlugOptions] = None,
+        issued: Iterable[str] = (),
+        max_attempts: int = 100,
+    ) -> None:
+        """
+        Initialize the registry.
+        
+        Args:
+            strategy: The collision strategy, such as numeric_suffix,
+                hash_suffix, random_suffix or a function of the same
+                signature. Defaults to numeric_suffix.
+            options: How text is converted to slugs. With a max_length,
+                slugs are shortened so the suffix still fits. Defaults to
+                SlugOptions().
+            issued: Slugs that are already taken. Defaults to none.
+            max_attempts: How many suffixes to try before issue raises.
+                Defaults to 100.
+            
+        Raises:
+            TypeError: If strategy is not callable, or an issued slug or
+                an option is of the wrong type
+            ValueError: If an option is invalid (see SlugOptions) or
+                max_attempts is less than 1
+        """
+        if not callable(strategy):
+            raise TypeError(
+                f"Strategy must be callable, got {type(strategy).__name__}"
+            )
+        if options is None:
+            options = SlugOptions()
+        _check_slug_options(options)
+        if max_attempts < 1:
+            raise ValueError(f"Maximum attempts must be at least 1, got {max_attempts}")
+        
+        self.strategy = strategy
+        self.options = options
+        self.max_attempts = max_attempts
+        self._issued: Set[str] = set()
+        self._lock = threading.Lock()
+        for slug in issued:
+            if not isinstance(slug, str):
+                raise TypeError(f"Slugs must be strings, got {type(slug).__name__}")
+            self._issued.add(slug)
+    
+    def __contains__(self, slug: object) -> bool:
+        """Return True if slug has been issued and not released."""
+        with self._lock:
+            return slug in self._issued
+    
+    def __len__(self) -> int:
+        """Return the number of slugs currently taken."""
+        with self._lock:
+            return len(self._issued)
+    
+    def issue(self, text: str) -> str:
+        """
+        Issue a unique slug for text.
+        
+        Args:
+            text: The text to make a slug from, typically a title
+            
+        Returns:
+            The slug of text, or that slug with the first free suffix
+            
+        Raises:
+            TypeError: If text is not a string, or the strategy returns
+                something other than a string
+            ValueError: If text fails validation (see MAX_STRING_LENGTH),
+                has no characters a slug can be made from, or no free slug
+                is found within max_attempts suffixes
+        """
+        slug = slugify_transliterated(text, self.options)
+        if not slug:
+            raise ValueError(f"Cannot make a slug from {text!r}")
+        
+        with self._lock:
+            if slug not in self._issued:
+                self._issued.add(slug)
+                return slug
+            for attempt in range(2, self.max_attempts + 2):
+                candidate = self._with_suffix(slug, self.strategy(slug, attempt))
+                if candidate not in self._issued:
+                    self._issued.add(candidate)
+                    return candidate
+        raise ValueError(
+            f"No free slug for {slug!r} after {self.max_attempts} attempts"
+        )
+    
+    def release(self, slug: str) -> bool:
+        """
+        Make an issued slug available again, as when its page is deleted.
+        
+        Args:
+            slug: The slug to release
+            
+        Returns:
+            True if the slug was taken, False if it was not
+        """
+        with self._lock:
+            if slug in self._issued:
+                self._issued.remove(slug)
+                return True
+            return False
+    
+    def _with_suffix(self, slug: str, suffix: str) -> str:
+        """Append suffix to slug, shortening slug if max_length requires."""
+        if not isinstance(suffix, str):
+            raise TypeError(
+                f"Strategy must return a string, got {type(suffix).__name__}"
+            )
+        separator = self.options.separator
+        max_length = self.options.max_length
+        if max_length is not None:
+            room = max_length - len(separator) - len(suffix)
+            if room < 1:
+                raise ValueError(
+                    f"Suffix {suffix!r} does not fit in a slug of at most "
+                    f"{max_length} characters"
+                )
+            slug = _trim_slug(slug, separator, room)
+        return f"{slug}{separator}{suffix}"
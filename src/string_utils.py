@@ -1,6 +1,690 @@
 """String utility functions for text manipulation."""
 
-from typing import Any
+import codecs
+import hashlib
+import hmac
+import re
+import secrets
+import unicodedata
+from dataclasses import dataclass
+from enum import Enum
+from typing import (
+    IO,
+    Any,
+    Callable,
+    Dict,
+    FrozenSet,
+    Iterable,
+    Iterator,
+    List,
+    Optional,
+    Sequence,
+    Set,
+    Tuple,
+)
+
+
+# Upper bound on the length of input accepted by the validating functions.
+MAX_STRING_LENGTH = 1_000_000
+
+# Number of characters (or bytes, for binary streams) read per chunk when
+# streaming.
+STREAM_CHUNK_SIZE = 64 * 1024
+
+# The control characters rejected by _validate_input: all of category Cc
+# other than tab, newline and carriage return.
+_DISALLOWED_CONTROLS = frozenset(
+    char
+    for char in map(chr, range(0xA0))
+    if unicodedata.category(char) == "Cc" and char not in "\t\n\r"
+)
+
+# The disallowed control characters and lone surrogates (category Cs),
+# matched in a single scan.
+_INVALID_CHARACTERS = re.compile(
+    "[" + "".join(sorted(_DISALLOWED_CONTROLS)) + "\ud800-\udfff]"
+)
+
+# Characters that occupy no space and have no visible glyph of their own:
+# zero-width spaces, the byte-order mark, the soft hyphen, word joiners,
+# invisible operators, Hangul fillers and the bidi controls. The joiners
+# U+200C and U+200D and the variation selectors are not included, since
+# they change how emoji and scripts such as Persian and Devanagari render.
+_INVISIBLE_CHARACTERS = re.compile(
+    "[\u00ad\u034f\u061c\u115f\u1160\u17b4\u17b5\u180e\u200b\u200e\u200f"
+    "\u202a-\u202e\u2060-\u2064\u2066-\u206f\u3164\ufeff\uffa0]"
+)
+
+# The Default_Ignorable_Code_Point characters of DerivedCoreProperties.txt:
+# the invisible characters above plus the joiners, variation selectors,
+# Mongolian free variation selectors, tag characters and other format
+# characters that renderers draw as nothing.
+_DEFAULT_IGNORABLE = re.compile(
+    "[\u00ad\u034f\u061c\u115f\u1160\u17b4\u17b5\u180b-\u180f"
+    "\u200b-\u200f\u202a-\u202e\u2060-\u206f\u3164\ufe00-\ufe0f\ufeff"
+    "\uffa0\ufff0-\ufff8\U0001bca0-\U0001bca3\U0001d173-\U0001d17a"
+    "\U000e0000-\U000e0fff]"
+)
+
+# A whitespace-delimited word.
+_WHITESPACE_WORD = re.compile(r"\S+")
+
+
+class ControlPolicy(Enum):
+    """What validation does with disallowed control characters."""
+    
+    REJECT = "reject"
+    STRIP = "strip"
+    REPLACE = "replace"
+    ALLOW = "allow"
+
+
+def _check_control_policy(policy: ControlPolicy) -> None:
+    """Raise TypeError unless policy is a ControlPolicy."""
+    if not isinstance(policy, ControlPolicy):
+        raise TypeError(
+            f"Control policy must be a ControlPolicy, got {type(policy).__name__}"
+        )
+
+
+def _validate_input(
+    input_str: Any,
+    max_length: int = MAX_STRING_LENGTH,
+    allowed_controls: FrozenSet[str] = frozenset(),
+    reject_invisible: bool = False,
+    control_policy: ControlPolicy = ControlPolicy.REJECT,
+) -> str:
+    """
+    Validate input shared by the word-level functions in this module.
+    
+    Args:
+        input_str: The value to validate
+        max_length: The maximum accepted length in characters
+        allowed_controls: Additional control characters to accept, as
+            returned by _allowed_controls
+        reject_invisible: Whether to also reject invisible characters
+            such as U+200B ZERO WIDTH SPACE (see strip_invisible)
+        control_policy: What to do with disallowed control characters
+            (see apply_control_policy)
+        
+    Returns:
+        The input with control_policy applied; unchanged unless the
+        policy is STRIP or REPLACE
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input exceeds max_length, contains a lone
+            surrogate, contains a control character other than tab,
+            newline, carriage return or one of allowed_controls that
+            control_policy does not remove or accept, or contains an
+            invisible character when reject_invisible is set
+    """
+    if not isinstance(input_str, str):
+        raise TypeError(
+            f"Input must be a string, got {type(input_str).__name__}"
+        )
+    
+    if len(input_str) > max_length:
+        raise ValueError(f"Input exceeds maximum length of {max_length} characters")
+    
+    input_str = _apply_control_policy(input_str, control_policy, allowed_controls)
+    if reject_invisible:
+        match = _INVISIBLE_CHARACTERS.search(input_str)
+        if match:
+            raise ValueError(
+                f"Input contains invisible character {_code_point(match)} "
+                f"at index {match.start()}"
+            )
+    return input_str
+
+
+def _apply_control_policy(
+    text: str, policy: ControlPolicy, allowed: FrozenSet[str] = frozenset()
+) -> str:
+    """
+    Apply a control character policy to text.
+    
+    Control characters in allowed are always kept, lone surrogates are
+    always rejected, and NUL is only ever stripped or replaced.
+    """
+    if policy is ControlPolicy.REJECT:
+        _check_characters(text, allowed=allowed)
+        return text
+    if policy is ControlPolicy.ALLOW:
+        _check_characters(text, allowed=_DISALLOWED_CONTROLS - {"\x00"})
+        return text
+    
+    replacement = "\ufffd" if policy is ControlPolicy.REPLACE else ""
+    
+    def replace(match: "re.Match[str]") -> str:
+        char = match.group(0)
+        if unicodedata.category(char) == "Cs":
+            raise ValueError(
+                f"Input contains a lone surrogate at index {match.start()}"
+            )
+        return char if char in allowed else replacement
+    
+    return _INVALID_CHARACTERS.sub(replace, text)
+
+
+def _code_point(match: "re.Match[str]") -> str:
+    """Format the character matched by a pattern as a marker like <U+200B>."""
+    return f"<U+{ord(match.group(0)):04X}>"
+
+
+def _check_characters(
+    text: str, offset: int = 0, allowed: FrozenSet[str] = frozenset()
+) -> None:
+    """
+    Reject lone surrogates and disallowed control characters.
+    
+    Args:
+        text: The text to check
+        offset: Index of text within the overall input, used in messages
+        allowed: Additional control characters to accept
+        
+    Raises:
+        ValueError: If text contains a lone surrogate or a control
+            character other than tab, newline, carriage return or one of
+            allowed
+    """
+    for match in _INVALID_CHARACTERS.finditer(text):
+        char, index = match.group(0), offset + match.start()
+        if char in allowed:
+            continue
+        if unicodedata.category(char) == "Cs":
+            raise ValueError(f"Input contains a lone surrogate at index {index}")
+        raise ValueError(
+            f"Input contains invalid control character {char!r} at index {index}"
+        )
+
+
+def _allowed_controls(chars: Iterable[str]) -> FrozenSet[str]:
+    """
+    Validate a collection of control characters that callers want to accept.
+    
+    Raises:
+        ValueError: If an entry is not a single control character, or is
+            NUL, which is always rejected
+    """
+    allowed = frozenset(chars)
+    for char in allowed:
+        if len(char) != 1 or unicodedata.category(char) != "Cc":
+            raise ValueError(
+                f"Allowed controls must be control characters, got {char!r}"
+            )
+        if char == "\x00":
+            raise ValueError("The null character cannot be allowed")
+    return allowed
+
+
+def _word_spans(input_str: str) -> List[Tuple[int, int]]:
+    """
+    Locate the whitespace-delimited words in a string.
+    
+    Args:
+        input_str: The string to scan
+        
+    Returns:
+        A list of (start, end) index pairs, one per word, in order
+    """
+    return [match.span() for match in _WHITESPACE_WORD.finditer(input_str)]
+
+
+# Character categories that occupy no terminal columns.
+_ZERO_WIDTH_CATEGORIES = frozenset(("Cc", "Cf", "Me", "Mn"))
+
+
+def _cluster_width(cluster: str) -> int:
+    """
+    Compute the number of terminal columns a grapheme cluster occupies.
+    
+    The width is that of the first character that is not a combining mark,
+    format character or control character: two columns for East Asian wide
+    and fullwidth characters, one otherwise, and none if there is no such
+    character. Flags and characters followed by the emoji presentation
+    selector (U+FE0F) are drawn as emoji, two columns wide.
+    """
+    for char in cluster:
+        if unicodedata.category(char) in _ZERO_WIDTH_CATEGORIES:
+            continue
+        if unicodedata.east_asian_width(char) in ("W", "F"):
+            return 2
+        if "\ufe0f" in cluster or _grapheme_break_property(char) == (
+            "Regional_Indicator"
+        ):
+            return 2
+        return 1
+    return 0
+
+
+def _display_width(text: str) -> int:
+    """
+    Compute the number of terminal columns a string occupies.
+    
+    The string is measured one grapheme cluster at a time, so combining
+    marks and whole emoji sequences never add columns of their own.
+    """
+    width = 0
+    start = 0
+    while start < len(text):
+        end = _grapheme_end(text, start)
+        width += _cluster_width(text[start:end])
+        start = end
+    return width
+
+
+# Extended_Pictographic characters (emoji and pictographs), which are kept
+# together with a following zero-width joiner sequence.
+_EXTENDED_PICTOGRAPHIC = re.compile(
+    "[\u00a9\u00ae\u203c\u2049\u2122\u2139\u2194-\u2199\u21a9\u21aa"
+    "\u231a\u231b\u2328\u2388\u23cf\u23e9-\u23f3\u23f8-\u23fa\u24c2"
+    "\u25aa\u25ab\u25b6\u25c0\u25fb-\u25fe\u2600-\u27bf\u2934\u2935"
+    "\u2b05-\u2b07\u2b1b\u2b1c\u2b50\u2b55\u3030\u303d\u3297\u3299"
+    "\U0001f000-\U0001f0ff\U0001f10d-\U0001f10f\U0001f12f"
+    "\U0001f16c-\U0001f171\U0001f17e\U0001f17f\U0001f18e"
+    "\U0001f191-\U0001f19a\U0001f1ad-\U0001f1e5\U0001f201-\U0001f20f"
+    "\U0001f21a\U0001f22f\U0001f232-\U0001f23a\U0001f23c-\U0001f23f"
+    "\U0001f249-\U0001f3fa\U0001f400-\U0001f53d\U0001f546-\U0001f64f"
+    "\U0001f680-\U0001f6ff\U0001f774-\U0001f77f\U0001f7d5-\U0001f7ff"
+    "\U0001f80c-\U0001f80f\U0001f848-\U0001f84f\U0001f85a-\U0001f85f"
+    "\U0001f888-\U0001f88f\U0001f8ae-\U0001f8ff\U0001f90c-\U0001f93a"
+    "\U0001f93c-\U0001f945\U0001f947-\U0001faff\U0001fc00-\U0001fffd]"
+)
+
+# Characters with Grapheme_Cluster_Break=Prepend.
+_GRAPHEME_PREPEND = frozenset(
+    "\u0600\u0601\u0602\u0603\u0604\u0605\u06dd\u070f\u0890\u0891\u08e2"
+    "\u0d4e\U000110bd\U000110cd\U000111c2\U000111c3\U0001193f\U00011941"
+    "\U00011a3a\U00011a84\U00011a85\U00011a86\U00011a87\U00011a88\U00011a89"
+    "\U00011d46"
+)
+
+
+def _grapheme_break_property(char: str) -> str:
+    """
+    Return the Grapheme_Cluster_Break property of a character (UAX #29).
+    
+    The property is derived from the general category and a few code point
+    ranges, which matches the Unicode data for all but a handful of rare
+    spacing marks.
+    """
+    code = ord(char)
+    if char == "\r":
+        return "CR"
+    if char == "\n":
+        return "LF"
+    if char == "\u200d":
+        return "ZWJ"
+    if 0x1F1E6 <= code <= 0x1F1FF:
+        return "Regional_Indicator"
+    if (
+        char == "\u200c"
+        or 0x1F3FB <= code <= 0x1F3FF
+        or 0xE0020 <= code <= 0xE007F
+        or 0xFF9E <= code <= 0xFF9F
+    ):
+        return "Extend"
+    if char in _GRAPHEME_PREPEND:
+        return "Prepend"
+    if 0x1100 <= code <= 0x115F or 0xA960 <= code <= 0xA97C:
+        return "L"
+    if 0x1160 <= code <= 0x11A7 or 0xD7B0 <= code <= 0xD7C6:
+        return "V"
+    if 0x11A8 <= code <= 0x11FF or 0xD7CB <= code <= 0xD7FB:
+        return "T"
+    if 0xAC00 <= code <= 0xD7A3:
+        return "LV" if (code - 0xAC00) % 28 == 0 else "LVT"
+    category = unicodedata.category(char)
+    if category in ("Mn", "Me"):
+        return "Extend"
+    if category == "Mc":
+        return "SpacingMark"
+    if category in ("Cc", "Cf", "Zl", "Zp"):
+        return "Control"
+    return "Other"
+
+
+def _grapheme_end(text: str, start: int) -> int:
+    """
+    Find the end of the extended grapheme cluster starting at an index.
+    
+    Implements the UAX #29 boundary rules: CR LF, Hangul syllables,
+    combining and spacing marks, prepended characters, emoji zero-width
+    joiner sequences and regional indicator (flag) pairs stay together.
+    
+    Args:
+        text: The string to scan
+        start: Index of the first character of a cluster
+        
+    Returns:
+        The index just past the cluster, or len(text) if start is at or
+        past the end
+    """
+    if start >= len(text):
+        return len(text)
+    previous = _grapheme_break_property(text[start])
+    # Whether the cluster so far is an emoji followed by extenders, and
+    # whether a zero-width joiner just followed such a sequence (GB11).
+    pictographic = _EXTENDED_PICTOGRAPHIC.match(text[start]) is not None
+    joined_pictographic = False
+    regional_indicators = 1 if previous == "Regional_Indicator" else 0
+    index = start + 1
+    while index < len(text):
+        char = text[index]
+        current = _grapheme_break_property(char)
+        is_pictographic = _EXTENDED_PICTOGRAPHIC.match(char) is not None
+        if previous == "CR":
+            joined = current == "LF"
+        elif previous in ("Control", "LF") or current in ("Control", "CR", "LF"):
+            joined = False
+        elif previous == "L":
+            joined = current in ("L", "V", "LV", "LVT", "Extend", "ZWJ", "SpacingMark")
+        elif previous in ("LV", "V") and current in ("V", "T"):
+            joined = True
+        elif previous in ("LVT", "T") and current == "T":
+            joined = True
+        elif current in ("Extend", "ZWJ", "SpacingMark") or previous == "Prepend":
+            joined = True
+        elif previous == "ZWJ" and is_pictographic:
+            joined = joined_pictographic
+        elif previous == "Regional_Indicator" and current == "Regional_Indicator":
+            joined = regional_indicators % 2 == 1
+        else:
+            joined = False
+        if not joined:
+            break
+        joined_pictographic = current == "ZWJ" and pictographic
+        if current != "Extend":
+            pictographic = is_pictographic
+        if current == "Regional_Indicator":
+            regional_indicators += 1
+        previous = current
+        index += 1
+    return index
+
+
+# Characters with Word_Break=MidLetter, MidNum and MidNumLet, which join
+# letters ("can't", "e.g") or digits ("3.14", "1,000") on both sides.
+_WORD_MID_LETTER = frozenset(":\u00b7\u0387\u055f\u05f4\u2027\ufe13\ufe55\uff1a")
+_WORD_MID_NUM = frozenset(
+    ",;\u037e\u0589\u060c\u060d\u066c\u07f8\u2044\ufe10\ufe14\ufe50\ufe54"
+    "\uff0c\uff1b"
+)
+_WORD_MID_NUM_LET = frozenset(".\u2018\u2019\u2024\ufe52\uff07\uff0e")
+
+# Letters that are not Word_Break=ALetter: ideographs, Hiragana, and the
+# Southeast Asian scripts, which are split with a dictionary instead.
+_NON_ALETTER_RANGES = (
+    (0x0E00, 0x0EFF),
+    (0x1000, 0x109F),
+    (0x1780, 0x17FF),
+    (0x1950, 0x19DF),
+    (0x3005, 0x3007),
+    (0x3040, 0x309F),
+    (0x3400, 0x4DBF),
+    (0x4E00, 0x9FFF),
+    (0xAA60, 0xAADF),
+    (0xF900, 0xFAFF),
+    (0x20000, 0x3FFFF),
+)
+
+
+def _word_break_property(char: str) -> str:
+    """
+    Return the Word_Break property of a character (UAX #29).
+    
+    As with _grapheme_break_property, the property is derived from the
+    general category and a few code point ranges.
+    """
+    code = ord(char)
+    if char == "\r":
+        return "CR"
+    if char == "\n":
+        return "LF"
+    if char in "\x0b\x0c\x85\u2028\u2029":
+        return "Newline"
+    if char == "\u200d":
+        return "ZWJ"
+    if 0x1F1E6 <= code <= 0x1F1FF:
+        return "Regional_Indicator"
+    if char == "'":
+        return "Single_Quote"
+    if char == '"':
+        return "Double_Quote"
+    if char in _WORD_MID_LETTER:
+        return "MidLetter"
+    if char in _WORD_MID_NUM:
+        return "MidNum"
+    if char in _WORD_MID_NUM_LET:
+        return "MidNumLet"
+    category = unicodedata.category(char)
+    if (
+        category in ("Mn", "Me", "Mc")
+        or char == "\u200c"
+        or 0x1F3FB <= code <= 0x1F3FF
+    ):
+        return "Extend"
+    if category == "Cf":
+        return "Format"
+    if category == "Zs":
+        return "WSegSpace"
+    if category == "Nd":
+        return "Numeric"
+    if category == "Pc" or char == "\u202f":
+        return "ExtendNumLet"
+    if (
+        (0x30A0 <= code <= 0x30FF and code != 0x30FB)
+        or 0x31F0 <= code <= 0x31FF
+        or 0xFF66 <= code <= 0xFF9D
+        or 0x3031 <= code <= 0x3035
+        or code in (0x309B, 0x309C)
+    ):
+        return "Katakana"
+    if 0x05D0 <= code <= 0x05F2 or 0xFB1D <= code <= 0xFB4F:
+        return "Hebrew_Letter"
+    if category[0] == "L" and not any(
+        low <= code <= high for low, high in _NON_ALETTER_RANGES
+    ):
+        return "ALetter"
+    return "Other"
+
+
+_LETTER_PROPERTIES = ("ALetter", "Hebrew_Letter")
+_MID_LETTER_PROPERTIES = ("MidLetter", "MidNumLet", "Single_Quote")
+_MID_NUM_PROPERTIES = ("MidNum", "MidNumLet", "Single_Quote")
+
+
+def _unicode_word_spans(text: str) -> List[Tuple[int, int]]:
+    """
+    Find the words of a string using UAX #29 word boundaries.
+    
+    The text is split at every default word boundary, and the segments
+    containing a letter or digit are the words. Contractions ("can't"),
+    numbers with separators ("3.14", "1,000"), katakana runs and words
+    joined by underscores stay together, while hyphens, slashes and other
+    punctuation separate words. Ideographs and Hiragana are words of one
+    character each.
+    """
+    properties = [_word_break_property(char) for char in text]
+    # The property of the next character not ignored under rule WB4, for the
+    # rules that look ahead (WB6, WB7b and WB12).
+    following_properties = [""] * len(text)
+    following = ""
+    for index in range(len(text) - 1, -1, -1):
+        following_properties[index] = following
+        if properties[index] not in ("Extend", "Format", "ZWJ"):
+            following = properties[index]
+    # The properties of the last two characters that are not ignored under
+    # rule WB4, and the length of the current run of regional indicators.
+    last = before_last = ""
+    regional_indicators = 0
+    spans: List[Tuple[int, int]] = []
+    start = 0
+    for index, current in enumerate(properties):
+        previous = properties[index - 1] if index else ""
+        following = following_properties[index]
+        if not index:
+            joined = False
+        elif previous == "CR" and current == "LF":
+            joined = True
+        elif previous in ("CR", "LF", "Newline") or current in ("CR", "LF", "Newline"):
+            joined = False
+        elif previous == "ZWJ" and _EXTENDED_PICTOGRAPHIC.match(text[index]):
+            joined = True
+        elif previous == "WSegSpace" and current == "WSegSpace":
+            joined = True
+        elif current in ("Extend", "Format", "ZWJ"):
+            continue
+        elif last in _LETTER_PROPERTIES:
+            joined = (
+                current in (*_LETTER_PROPERTIES, "Numeric", "ExtendNumLet")
+                or (
+                    current in _MID_LETTER_PROPERTIES
+                    and following in _LETTER_PROPERTIES
+                )
+                or (last == "Hebrew_Letter" and current == "Single_Quote")
+                or (
+                    last == "Hebrew_Letter"
+                    and current == "Double_Quote"
+                    and following == "Hebrew_Letter"
+                )
+            )
+        elif last in _MID_LETTER_PROPERTIES and before_last in _LETTER_PROPERTIES:
+            joined = current in _LETTER_PROPERTIES
+        elif last == "Double_Quote" and before_last == "Hebrew_Letter":
+            joined = current == "Hebrew_Letter"
+        elif last == "Numeric":
+            joined = (
+                current in (*_LETTER_PROPERTIES, "Numeric", "ExtendNumLet")
+                or (current in _MID_NUM_PROPERTIES and following == "Numeric")
+            )
+        elif last in _MID_NUM_PROPERTIES and before_last == "Numeric":
+            joined = current == "Numeric"
+        elif last == "Katakana":
+            joined = current in ("Katakana", "ExtendNumLet")
+        elif last == "ExtendNumLet":
+            joined = current in (
+                *_LETTER_PROPERTIES, "Numeric", "Katakana", "ExtendNumLet"
+            )
+        elif last == "Regional_Indicator":
+            joined = current == "Regional_Indicator" and regional_indicators % 2 == 1
+        else:
+            joined = False
+        if not joined:
+            if index:
+                spans.append((start, index))
+            start = index
+        regional_indicators = (
+            regional_indicators + 1 if current == "Regional_Indicator" else 0
+        )
+        before_last, last = last, current
+    if text:
+        spans.append((start, len(text)))
+    return [
+        (start, end)
+        for start, end in spans
+        if any(char.isalnum() for char in text[start:end])
+    ]
+
+
+def _is_leading_punctuation(char: str) -> bool:
+    """
+    Return True for quotes and opening brackets that may precede a word.
+    
+    Such characters are skipped when looking for the letter to capitalize,
+    so "'twas", '"hello"' and "(hello)" capitalize their first letter.
+    """
+    return char in "\"'`" or unicodedata.category(char) in ("Ps", "Pi", "Pf")
+
+
+# Case mappings that differ from the default Unicode mappings, by language.
+_LOCALE_UPPER_MAPPINGS = {
+    "tr": {"i": "İ", "ı": "I"},
+    "az": {"i": "İ", "ı": "I"},
+}
+_LOCALE_LOWER_MAPPINGS = {
+    "tr": {"I": "ı", "İ": "i"},
+    "az": {"I": "ı", "İ": "i"},
+    "lt": {"Ì": "i\u0307\u0300", "Í": "i\u0307\u0301", "Ĩ": "i\u0307\u0303"},
+}
+
+# Lithuanian keeps the dot of i, j and į when an accent is placed above
+# them, so the dot is explicit in lowercase and dropped in uppercase.
+_LITHUANIAN_DOT_ABOVE = re.compile("(?<=[ijį])\u0307")
+_LITHUANIAN_ACCENTED_I = re.compile(
+    "[IJĮ](?=[\u0300-\u0314\u033d-\u0344\u0346\u034a-\u034c\u0350-\u0352"
+    "\u0357\u035b\u0363-\u036f])"
+)
+
+
+def _locale_language(locale: Optional[str]) -> str:
+    """Extract the lowercase language subtag from a locale such as "tr-TR"."""
+    if locale is None:
+        return ""
+    if not isinstance(locale, str):
+        raise TypeError(f"Locale must be a string, got {type(locale).__name__}")
+    return re.split(r"[-_]", locale, maxsplit=1)[0].lower()
+
+
+def _apply_upper_mappings(text: str, language: str) -> str:
+    """Apply the language-specific steps that precede uppercasing."""
+    mapping = _LOCALE_UPPER_MAPPINGS.get(language)
+    if mapping:
+        text = "".join(mapping.get(char, char) for char in text)
+    elif language == "lt":
+        text = _LITHUANIAN_DOT_ABOVE.sub("", text)
+    return text
+
+
+def _upper(text: str, language: str = "") -> str:
+    """Uppercase text using the special mappings of a language, if any."""
+    return _apply_upper_mappings(text, language).upper()
+
+
+def _title(text: str, language: str = "") -> str:
+    """
+    Titlecase a letter using the special mappings of a language, if any.
+    
+    The titlecase mapping differs from uppercase for ligatures and
+    digraphs: "ß" becomes "Ss", "ﬁ" becomes "Fi" and "ǆ" becomes "ǅ".
+    """
+    return _apply_upper_mappings(text, language).title()
+
+
+def _lower(text: str, language: str = "") -> str:
+    """Lowercase text using the special mappings of a language, if any."""
+    if language == "lt":
+        text = _LITHUANIAN_ACCENTED_I.sub(
+            lambda match: match.group(0).lower() + "\u0307", text
+        )
+    mapping = _LOCALE_LOWER_MAPPINGS.get(language)
+    if mapping:
+        text = "".join(mapping.get(char, char) for char in text)
+    return text.lower()
+
+
+def _capitalize_first(word: str, language: str = "") -> str:
+    """
+    Titlecase the first letter of a word, skipping leading quotes and brackets.
+    
+    A word whose first character after any leading punctuation is not a
+    letter is returned unchanged. The whole grapheme cluster of the letter
+    is titlecased, so its combining marks stay attached, using the special
+    mappings of language, if any; the titlecase of most letters is their
+    uppercase.
+    """
+    first = word[:1]
+    if first.isalpha() and not language and (len(word) < 2 or word[1] < "\u0300"):
+        return first.title() + word[1:]
+    index = 0
+    while index < len(word) and _is_leading_punctuation(word[index]):
+        index += 1
+    if index < len(word) and word[index].isalpha():
+        # Language mappings may depend on the marks following the letter.
+        end = _grapheme_end(word, index)
+        return word[:index] + _title(word[index:end], language) + word[end:]
+    return word
 
 
 def reverse_string(input_str: str) -> str:
@@ -69,4 +753,4874 @@ def capitalize_string(input_str: str) -> str:
             f"Input must be a string, got {type(input_str).__name__}"
         )
     
-    return input_str.capitalize()
\ No newline at end of file
+    return input_str.capitalize()
+
+
+def capitalize_words_at_indices(input_str: str, indices: Iterable[int]) -> str:
+    """
+    Capitalize the first letter of only the words at the given positions.
+    
+    Words are whitespace-delimited and numbered from zero. Words that are
+    not selected, and all whitespace between words, are left exactly as
+    they were. A selected word that starts with a non-letter is unchanged.
+    
+    Args:
+        input_str: The string whose words to capitalize
+        indices: Zero-based word positions to capitalize; positions that
+            are negative or past the last word are ignored
+        
+    Returns:
+        The string with the selected words capitalized
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> capitalize_words_at_indices("first middle last", [0, 2])
+        'First middle Last'
+        >>> capitalize_words_at_indices("one  two", [5])
+        'one  two'
+    """
+    _validate_input(input_str)
+    
+    selected = set(indices)
+    parts: List[str] = []
+    position = 0
+    for word_index, (start, end) in enumerate(_word_spans(input_str)):
+        parts.append(input_str[position:start])
+        word = input_str[start:end]
+        if word_index in selected:
+            word = _capitalize_first(word)
+        parts.append(word)
+        position = end
+    parts.append(input_str[position:])
+    return "".join(parts)
+
+
+def strip_bom(input_str: str) -> str:
+    """
+    Remove a leading UTF-8 byte-order mark (U+FEFF) from a string.
+    
+    Only a single BOM at the very start of the string is removed; any
+    U+FEFF appearing later in the string is left untouched.
+    
+    Args:
+        input_str: The string to clean
+        
+    Returns:
+        The string without its leading byte-order mark
+        
+    Raises:
+        TypeError: If input is not a string
+        
+    Examples:
+        >>> strip_bom("\ufeffhello")
+        'hello'
+        >>> strip_bom("hello")
+        'hello'
+    """
+    if not isinstance(input_str, str):
+        raise TypeError(
+            f"Input must be a string, got {type(input_str).__name__}"
+        )
+    
+    if input_str.startswith("\ufeff"):
+        return input_str[1:]
+    return input_str
+
+
+class NormalizationForm(Enum):
+    """The Unicode normalization forms."""
+    
+    NFC = "NFC"
+    NFD = "NFD"
+    NFKC = "NFKC"
+    NFKD = "NFKD"
+
+
+def _check_normalization_form(form: NormalizationForm) -> None:
+    """Raise TypeError unless form is a NormalizationForm."""
+    if not isinstance(form, NormalizationForm):
+        raise TypeError(
+            f"Form must be a NormalizationForm, got {type(form).__name__}"
+        )
+
+
+def normalize(input_str: str, form: NormalizationForm) -> str:
+    """
+    Convert a string to a Unicode normalization form.
+    
+    NFC composes letters and combining marks into single characters where
+    possible and NFD decomposes them, so the two spellings of "café"
+    compare equal once both are in the same form. The compatibility forms
+    NFKC and NFKD also replace variants such as ligatures, full-width
+    letters and superscripts with their plain equivalents.
+    
+    Args:
+        input_str: The string to normalize
+        form: The normalization form to convert to
+        
+    Returns:
+        The string in the requested normalization form
+        
+    Raises:
+        TypeError: If input is not a string or form is not a
+            NormalizationForm
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> normalize("cafe\\u0301", NormalizationForm.NFC) == "caf\\u00e9"
+        True
+        >>> normalize("\\ufb01x \\u2460", NormalizationForm.NFKC)
+        'fix 1'
+    """
+    _validate_input(input_str)
+    _check_normalization_form(form)
+    
+    return unicodedata.normalize(form.value, input_str)
+
+
+def is_normalized(input_str: str, form: NormalizationForm) -> bool:
+    """
+    Check whether a string is already in a Unicode normalization form.
+    
+    Args:
+        input_str: The string to check
+        form: The normalization form to check against
+        
+    Returns:
+        True if normalizing the string to form would leave it unchanged
+        
+    Raises:
+        TypeError: If input is not a string or form is not a
+            NormalizationForm
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> is_normalized("cafe\\u0301", NormalizationForm.NFD)
+        True
+        >>> is_normalized("cafe\\u0301", NormalizationForm.NFC)
+        False
+    """
+    _validate_input(input_str)
+    _check_normalization_form(form)
+    
+    return unicodedata.is_normalized(form.value, input_str)
+
+
+def _caseless_key(input_str: str, fold_diacritics: bool) -> str:
+    """Return the form of a string compared by equal_fold_normalized."""
+    if fold_diacritics:
+        input_str = _fold_accents(input_str)
+    folded = unicodedata.normalize("NFD", input_str).casefold()
+    return unicodedata.normalize("NFC", folded)
+
+
+def equal_fold_normalized(
+    first: str, second: str, fold_diacritics: bool = False
+) -> bool:
+    """
+    Compare two strings case-insensitively, ignoring normalization form.
+    
+    Both strings are normalized and case-folded (Unicode canonical caseless
+    matching), so a precomposed "É" equals a decomposed "e" plus combining
+    acute accent, and "STRASSE" equals "straße". A plain
+    first.lower() == second.lower() gets both wrong.
+    
+    Args:
+        first: The first string to compare
+        second: The second string to compare
+        fold_diacritics: Whether to also ignore accents, as in
+            remove_accents, so "resume" equals "Résumé". Defaults to
+            False.
+        
+    Returns:
+        True if the strings are equal under case folding and normalization
+        
+    Raises:
+        TypeError: If either input is not a string
+        ValueError: If either input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> equal_fold_normalized("CAFE\\u0301", "café")
+        True
+        >>> equal_fold_normalized("STRASSE", "straße")
+        True
+        >>> equal_fold_normalized("resume", "Résumé")
+        False
+        >>> equal_fold_normalized("resume", "Résumé", fold_diacritics=True)
+        True
+    """
+    _validate_input(first)
+    _validate_input(second)
+    
+    return _caseless_key(first, fold_diacritics) == _caseless_key(
+        second, fold_diacritics
+    )
+
+
+# Key for the digests secure_equal compares, chosen afresh in each process
+# so that digests cannot be precomputed.
+_SECURE_EQUAL_KEY = secrets.token_bytes(32)
+
+
+def secure_equal(first: str, second: str) -> bool:
+    """
+    Compare two strings in constant time, for secrets such as tokens.
+    
+    With ==, the comparison stops at the first differing character, so
+    the time it takes reveals how much of a guess is right and a secret
+    can be recovered one character at a time. secure_equal takes the same
+    time wherever the strings differ.
+    
+    The strings are not compared directly: hmac.compare_digest returns
+    early when its arguments differ in length, which would reveal the
+    length of the secret. Instead both are hashed with HMAC-SHA256 under
+    a random per-process key and the fixed-length digests are compared.
+    Hashing still takes time proportional to the length of each string,
+    so a secret's length is only hidden up to the SHA-256 block size of
+    64 bytes; use secrets of a fixed length where that matters.
+    
+    The strings are compared exactly, without normalization or
+    validation, so that no character of a secret can cause an early
+    error.
+    
+    Args:
+        first: The first string to compare, such as the expected secret
+        second: The second string to compare, such as the one supplied
+        
+    Returns:
+        True if the strings are equal
+        
+    Raises:
+        TypeError: If either input is not a string
+        
+    Examples:
+        >>> secure_equal("s3cret-token", "s3cret-token")
+        True
+        >>> secure_equal("s3cret-token", "s3cret-tokem")
+        False
+        >>> secure_equal("s3cret-token", "s3cret")
+        False
+    """
+    for value in (first, second):
+        if not isinstance(value, str):
+            raise TypeError(f"Input must be a string, got {type(value).__name__}")
+    
+    digests = [
+        hmac.new(
+            _SECURE_EQUAL_KEY,
+            value.encode("utf-8", "surrogatepass"),
+            hashlib.sha256,
+        ).digest()
+        for value in (first, second)
+    ]
+    return hmac.compare_digest(digests[0], digests[1])
+
+
+class WordBoundaries(Enum):
+    """How a Capitalizer finds the words of its input."""
+    
+    WHITESPACE = "whitespace"
+    UNICODE = "unicode"
+
+
+class Capitalizer:
+    """
+    A reusable, configured word capitalizer.
+    
+    All settings are fixed at construction, so one Capitalizer can be
+    built once and applied to many inputs. capitalize_words,
+    capitalize_words_with_delimiters and title_case are thin wrappers
+    around Capitalizer instances.
+    
+    Attributes:
+        delimiters (Optional[str]): Characters separating words, or None
+            to separate words by whitespace.
+        minor_words (FrozenSet[str]): Case-folded words kept lowercase
+            unless they are the first or last word.
+        skip_words (FrozenSet[str]): Case-folded words left unchanged
+            unless they are the first word.
+        acronyms (Dict[str, str]): Forced spellings of acronyms and brand
+            names, keyed by their case-folded form.
+        max_length (int): The maximum accepted input length.
+        lowercase_rest (bool): Whether letters after the first letter of
+            each word are lowercased.
+        locale (Optional[str]): Language tag selecting special case
+            mappings, or None for the default Unicode mappings.
+        allowed_controls (FrozenSet[str]): Control characters accepted in
+            input in addition to tab, newline and carriage return.
+        normalization (Optional[NormalizationForm]): The form input is
+            normalized to before capitalizing, or None to leave it as is.
+        reject_invisible (bool): Whether input containing invisible
+            characters such as U+200B is rejected.
+        word_boundaries (WordBoundaries): Whether words are separated by
+            whitespace or by the Unicode word segmentation rules.
+        control_policy (ControlPolicy): What is done with disallowed
+            control characters in input.
+        
+    Example:
+        >>> capitalizer = Capitalizer(minor_words=["of"], lowercase_rest=True)
+        >>> capitalizer.capitalize("TALE OF TWO CITIES")
+        'Tale of Two Cities'
+    """
+    
+    def __init__(
+        self,
+        *,
+        delimiters: Optional[str] = None,
+        minor_words: Iterable[str] = (),
+        skip_words: Iterable[str] = (),
+        acronyms: Iterable[str] = (),
+        max_length: int = MAX_STRING_LENGTH,
+        lowercase_rest: bool = False,
+        locale: Optional[str] = None,
+        allowed_controls: Iterable[str] = (),
+        normalization: Optional[NormalizationForm] = None,
+        reject_invisible: bool = False,
+        word_boundaries: WordBoundaries = WordBoundaries.WHITESPACE,
+        control_policy: ControlPolicy = ControlPolicy.REJECT,
+    ) -> None:
+        """
+        Initialize the capitalizer.
+        
+        Args:
+            delimiters: Characters separating words. Defaults to None,
+                which separates words by whitespace.
+            minor_words: Words kept lowercase unless they are the first
+                or last word, matched case-insensitively. Defaults to none.
+            skip_words: Words left exactly as written unless they are the
+                first word, matched case-insensitively. Defaults to none.
+            acronyms: Words such as "NASA", "iOS" or "McDonald's" always
+                written in the given spelling, wherever they appear and
+                regardless of the other settings. Matched
+                case-insensitively, ignoring punctuation around the word.
+                Defaults to none.
+            max_length: The maximum accepted input length. Defaults to
+                MAX_STRING_LENGTH.
+            lowercase_rest: Whether to lowercase each word after its
+                first letter. Defaults to False.
+            locale: A language tag such as "tr" or "tr-TR" selecting
+                language-specific case mappings. Defaults to None, which
+                uses the default Unicode mappings.
+            allowed_controls: Control characters to accept in input, such
+                as "\\f" and "\\v". NUL is always rejected. Defaults to none.
+            normalization: A normalization form, usually
+                NormalizationForm.NFC, applied to input before it is
+                capitalized, so decomposed and precomposed spellings give
+                the same result. Defaults to None, which keeps the input's
+                form.
+            reject_invisible: Whether to reject input containing invisible
+                characters such as U+200B ZERO WIDTH SPACE or U+FEFF, which
+                otherwise pass validation (see strip_invisible). Defaults
+                to False.
+            word_boundaries: WordBoundaries.UNICODE to find words with the
+                UAX #29 word boundary rules instead of whitespace, so that
+                "well-known" is two words and "can't", "3.14" and katakana
+                runs are one, in any language. Defaults to
+                WordBoundaries.WHITESPACE.
+            control_policy: What to do with control characters that are
+                not allowed: raise ValueError (REJECT), remove them
+                (STRIP), replace each with U+FFFD (REPLACE) or keep them
+                (ALLOW), as in apply_control_policy. Useful for logs and
+                terminal captures containing escape sequences. Defaults to
+                ControlPolicy.REJECT.
+            
+        Raises:
+            TypeError: If delimiters or locale is neither None nor a string,
+                normalization is neither None nor a NormalizationForm,
+                word_boundaries is not a WordBoundaries, or control_policy
+                is not a ControlPolicy
+            ValueError: If max_length is negative, allowed_controls
+                contains NUL or a character that is not a control character,
+                or delimiters are combined with Unicode word boundaries
+        """
+        if delimiters is not None and not isinstance(delimiters, str):
+            raise TypeError(
+                f"Delimiters must be a string, got {type(delimiters).__name__}"
+            )
+        if max_length < 0:
+            raise ValueError(f"Maximum length cannot be negative, got {max_length}")
+        if normalization is not None:
+            _check_normalization_form(normalization)
+        if not isinstance(word_boundaries, WordBoundaries):
+            raise TypeError(
+                f"Word boundaries must be a WordBoundaries, "
+                f"got {type(word_boundaries).__name__}"
+            )
+        if delimiters is not None and word_boundaries is WordBoundaries.UNICODE:
+            raise ValueError(
+                "Delimiters cannot be combined with Unicode word boundaries"
+            )
+        _check_control_policy(control_policy)
+        
+        self.delimiters = delimiters
+        self.minor_words = frozenset(word.casefold() for word in minor_words)
+        self.skip_words = frozenset(word.casefold() for word in skip_words)
+        self.acronyms = {word.casefold(): word for word in acronyms}
+        self.max_length = max_length
+        self.lowercase_rest = lowercase_rest
+        self.locale = locale
+        self._language = _locale_language(locale)
+        self.allowed_controls = _allowed_controls(allowed_controls)
+        self.normalization = normalization
+        self.reject_invisible = reject_invisible
+        self.word_boundaries = word_boundaries
+        self.control_policy = control_policy
+        self._word_pattern: Optional["re.Pattern[str]"]
+        if word_boundaries is WordBoundaries.UNICODE:
+            self._word_pattern = None
+        elif delimiters is None:
+            self._word_pattern = _WHITESPACE_WORD
+        elif delimiters:
+            self._word_pattern = re.compile("[^" + re.escape(delimiters) + "]+")
+        else:
+            self._word_pattern = re.compile(".+", re.DOTALL)
+    
+    def _acronym_form(self, word: str) -> Optional[str]:
+        """Return word with an acronym respelled, or None if it is not one."""
+        core = _strip_punctuation(word)
+        acronym = self.acronyms.get(core.casefold())
+        if acronym is None:
+            return None
+        start = word.find(core)
+        return word[:start] + acronym + word[start + len(core) :]
+    
+    def _capitalize_word(self, word: str) -> str:
+        """Capitalize a single word according to the settings."""
+        if self.acronyms:
+            acronym = self._acronym_form(word)
+            if acronym is not None:
+                return acronym
+        word = _capitalize_first(word, self._language)
+        if not self.lowercase_rest:
+            return word
+        index = 0
+        while index < len(word) and _is_leading_punctuation(word[index]):
+            index += 1
+        if index < len(word) and word[index].isalpha():
+            index += 1
+        return word[:index] + _lower(word[index:], self._language)
+    
+    def _find_words(self, input_str: str) -> List[Tuple[int, int]]:
+        """Return the start and end index of each word of input."""
+        if self._word_pattern is None:
+            return _unicode_word_spans(input_str)
+        return [match.span() for match in self._word_pattern.finditer(input_str)]
+    
+    def _capitalize_part(
+        self, text: str, capitalize_next: bool = True
+    ) -> Tuple[str, bool, bool]:
+        """
+        Capitalize one part of a longer text, such as a chunk of a stream.
+        
+        Args:
+            text: A part of the text that has already passed validation
+            capitalize_next: Whether a word starting the part is to be
+                capitalized: True unless it continues a word of the
+                previous part that already has its first letter
+            
+        Returns:
+            A tuple of the capitalized part, whether any character changed,
+            and the capitalize_next to pass with the following part
+        """
+        parts: List[str] = []
+        changed = False
+        position = 0
+        for start, end in self._find_words(text):
+            parts.append(text[position:start])
+            word = text[start:end]
+            if start > 0 or capitalize_next:
+                capitalized = self._capitalize_word(word)
+                changed = changed or capitalized != word
+                parts.append(capitalized)
+                # A word of only quotes or brackets may continue in the
+                # next part, whose first letter is then capitalized.
+                capitalize_next = all(map(_is_leading_punctuation, word))
+            else:
+                parts.append(word)
+            position = end
+        parts.append(text[position:])
+        if position < len(text):
+            capitalize_next = True
+        return "".join(parts), changed, capitalize_next
+    
+    def _words(self, input_str: str) -> List[str]:
+        """Return the words of input, as separated by the settings."""
+        return [input_str[start:end] for start, end in self._find_words(input_str)]
+    
+    def capitalize(self, input_str: str) -> str:
+        """
+        Capitalize the words of a string according to the settings.
+        
+        Args:
+            input_str: The string whose words to capitalize
+            
+        Returns:
+            The capitalized string, with delimiters preserved
+            
+        Raises:
+            TypeError: If input is not a string
+            ValueError: If input is longer than max_length, contains a lone
+                surrogate, contains a disallowed control character that
+                control_policy does not remove or accept, or contains an
+                invisible character when reject_invisible is set
+        """
+        input_str = _validate_input(
+            input_str,
+            self.max_length,
+            self.allowed_controls,
+            self.reject_invisible,
+            self.control_policy,
+        )
+        if self.normalization is not None:
+            input_str = unicodedata.normalize(self.normalization.value, input_str)
+        
+        if (
+            self._word_pattern is not None
+            and not self.minor_words
+            and not self.skip_words
+        ):
+            return self._word_pattern.sub(
+                lambda match: self._capitalize_word(match.group(0)), input_str
+            )
+        
+        spans = self._find_words(input_str)
+        parts: List[str] = []
+        position = 0
+        for word_index, (start, end) in enumerate(spans):
+            parts.append(input_str[position:start])
+            word = input_str[start:end]
+            key = _strip_punctuation(word).casefold()
+            is_edge = word_index in (0, len(spans) - 1)
+            if key in self.acronyms:
+                parts.append(self._capitalize_word(word))
+            elif word_index > 0 and key in self.skip_words:
+                parts.append(word)
+            elif not is_edge and key in self.minor_words:
+                parts.append(_lower(word, self._language))
+            else:
+                parts.append(self._capitalize_word(word))
+            position = end
+        parts.append(input_str[position:])
+        return "".join(parts)
+
+
+_DEFAULT_CAPITALIZER = Capitalizer()
+
+
+def capitalize_words(
+    input_str: str,
+    *,
+    control_policy: ControlPolicy = ControlPolicy.REJECT,
+    reject_invisible: bool = False,
+) -> str:
+    """
+    Capitalize the first letter of every whitespace-delimited word.
+    
+    Only the first letter of each word is touched; the rest of each word
+    and all whitespace are preserved. Quotes, apostrophes and opening
+    brackets at the start of a word are skipped, so "'twas" becomes
+    "'Twas". A word that starts with any other non-letter, such as a
+    digit, is left unchanged.
+    Use capitalize_words_strict to also lowercase the rest of each word.
+    
+    Args:
+        input_str: The string whose words to capitalize
+        control_policy: What to do with disallowed control characters, as
+            in apply_control_policy. Defaults to ControlPolicy.REJECT.
+        reject_invisible: Whether to reject input containing invisible
+            characters such as U+200B (see strip_invisible). Defaults to
+            False.
+        
+    Returns:
+        The string with each word's first letter in uppercase
+        
+    Raises:
+        TypeError: If input is not a string or control_policy is not a
+            ControlPolicy
+        ValueError: If input fails validation (see MAX_STRING_LENGTH), or
+            contains an invisible character when reject_invisible is set
+        
+    Examples:
+        >>> capitalize_words("hello world")
+        'Hello World'
+        >>> capitalize_words("hELLO  3d")
+        'HELLO  3d'
+        >>> capitalize_words('"quoted" (words)')
+        '"Quoted" (Words)'
+        >>> capitalize_words("\x1b[1mhello", control_policy=ControlPolicy.STRIP)
+        '[1mhello'
+    """
+    if control_policy is ControlPolicy.REJECT and not reject_invisible:
+        return _DEFAULT_CAPITALIZER.capitalize(input_str)
+    return Capitalizer(
+        control_policy=control_policy, reject_invisible=reject_invisible
+    ).capitalize(input_str)
+
+
+def capitalize_words_with_delimiters(
+    input_str: str,
+    delimiters: str,
+    *,
+    control_policy: ControlPolicy = ControlPolicy.REJECT,
+    reject_invisible: bool = False,
+) -> str:
+    """
+    Capitalize the first letter of every word, using custom delimiters.
+    
+    Behaves like capitalize_words, except that words are separated by the
+    characters in delimiters instead of by whitespace. Delimiters are
+    preserved in the output.
+    
+    Args:
+        input_str: The string whose words to capitalize
+        delimiters: The characters that separate words
+        control_policy: What to do with disallowed control characters, as
+            in apply_control_policy. Defaults to ControlPolicy.REJECT.
+        reject_invisible: Whether to reject input containing invisible
+            characters such as U+200B (see strip_invisible). Defaults to
+            False.
+        
+    Returns:
+        The string with each word's first letter in uppercase
+        
+    Raises:
+        TypeError: If input or delimiters is not a string, or
+            control_policy is not a ControlPolicy
+        ValueError: If input fails validation (see MAX_STRING_LENGTH), or
+            contains an invisible character when reject_invisible is set
+        
+    Examples:
+        >>> capitalize_words_with_delimiters("hello-world.foo bar", "-.")
+        'Hello-World.Foo bar'
+    """
+    return Capitalizer(
+        delimiters=delimiters,
+        control_policy=control_policy,
+        reject_invisible=reject_invisible,
+    ).capitalize(input_str)
+
+
+def capitalize_words_changed(input_str: str) -> Tuple[str, bool]:
+    """
+    Capitalize words and report whether the result differs from the input.
+    
+    The change flag is computed during the capitalization pass, so callers
+    can skip work for already-capitalized text without comparing strings.
+    
+    Args:
+        input_str: The string whose words to capitalize
+        
+    Returns:
+        A tuple of the result of capitalize_words and True if any character
+        was changed
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> capitalize_words_changed("hello world")
+        ('Hello World', True)
+        >>> capitalize_words_changed("Hello World")
+        ('Hello World', False)
+        >>> capitalize_words_changed("")
+        ('', False)
+    """
+    _validate_input(input_str)
+    
+    result, changed, _ = _DEFAULT_CAPITALIZER._capitalize_part(input_str)
+    return result, changed
+
+
+# Leading characters that spreadsheet applications interpret as a formula.
+_CSV_FORMULA_TRIGGERS = frozenset("=+-@")
+
+
+def escape_csv_field(input_str: str) -> str:
+    """
+    Escape a string for safe embedding as a single CSV field.
+    
+    The field is always wrapped in double quotes and any double quote
+    inside it is doubled, as described in RFC 4180. To mitigate CSV
+    (formula) injection, a field starting with '=', '+', '-' or '@' is
+    prefixed with a single quote so spreadsheet applications treat it as
+    text rather than evaluating it.
+    
+    Args:
+        input_str: The field value to escape
+        
+    Returns:
+        The quoted and escaped field
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> escape_csv_field("a,b")
+        '"a,b"'
+        >>> escape_csv_field("=SUM(A1:A9)")
+        '"\\'=SUM(A1:A9)"'
+    """
+    _validate_input(input_str)
+    
+    if input_str and input_str[0] in _CSV_FORMULA_TRIGGERS:
+        input_str = "'" + input_str
+    return '"' + input_str.replace('"', '""') + '"'
+
+
+def split_csv_line(input_str: str, delimiter: str = ",") -> List[str]:
+    """
+    Split one line of CSV into its fields.
+    
+    Follows RFC 4180: a field wrapped in double quotes may contain the
+    delimiter, newlines and doubled double quotes, which stand for one
+    double quote. Quotes inside an unquoted field are kept as they are.
+    A trailing line break is ignored. This is the inverse of joining
+    fields escaped with escape_csv_field, except that the single quote
+    escape_csv_field adds before formula characters is kept.
+    
+    Args:
+        input_str: The line to split
+        delimiter: The single character separating fields. Defaults to ",".
+        
+    Returns:
+        The field values with quoting removed; an empty line is a single
+        empty field
+        
+    Raises:
+        TypeError: If input or delimiter is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH), a
+            quoted field is not terminated or is followed by text other
+            than a delimiter, or delimiter is not a single character other
+            than a double quote or line break
+        
+    Examples:
+        >>> split_csv_line('a,"b,c","6"" tall",')
+        ['a', 'b,c', '6" tall', '']
+        >>> split_csv_line("x;y", delimiter=";")
+        ['x', 'y']
+    """
+    _validate_input(input_str)
+    if not isinstance(delimiter, str):
+        raise TypeError(
+            f"Delimiter must be a string, got {type(delimiter).__name__}"
+        )
+    if len(delimiter) != 1 or delimiter in '"\r\n':
+        raise ValueError(
+            f"Delimiter must be a single character other than a double quote "
+            f"or line break, got {delimiter!r}"
+        )
+    
+    if input_str.endswith("\r\n"):
+        input_str = input_str[:-2]
+    elif input_str.endswith("\n"):
+        input_str = input_str[:-1]
+    
+    fields: List[str] = []
+    index = 0
+    while True:
+        if input_str.startswith('"', index):
+            chars: List[str] = []
+            end = index + 1
+            while True:
+                quote = input_str.find('"', end)
+                if quote == -1:
+                    raise ValueError(f"Unterminated quoted field at index {index}")
+                chars.append(input_str[end:quote])
+                if input_str.startswith('"', quote + 1):
+                    chars.append('"')
+                    end = quote + 2
+                else:
+                    break
+            fields.append("".join(chars))
+            index = quote + 1
+            if index < len(input_str) and input_str[index] != delimiter:
+                raise ValueError(
+                    f"Unexpected character after closing quote at index {index}"
+                )
+        else:
+            end = input_str.find(delimiter, index)
+            if end == -1:
+                end = len(input_str)
+            fields.append(input_str[index:end])
+            index = end
+        if index >= len(input_str):
+            return fields
+        index += 1
+        if index == len(input_str):
+            fields.append("")
+            return fields
+
+
+_HTML_ESCAPES = {
+    ord("&"): "&amp;",
+    ord("<"): "&lt;",
+    ord(">"): "&gt;",
+    ord('"'): "&quot;",
+    ord("'"): "&#x27;",
+}
+
+
+def escape_html(input_str: str) -> str:
+    """
+    Escape a string for HTML element content or a quoted attribute value.
+    
+    The characters &, <, >, " and ' are replaced with character
+    references, so the text is displayed as written and cannot open a tag
+    or end the attribute it is placed in. The result is not safe in other
+    contexts: unquoted attribute values, URLs such as href values, and the
+    content of script and style elements each need their own encoding.
+    
+    Args:
+        input_str: The text to escape
+        
+    Returns:
+        The escaped text
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> escape_html('<a href="x">Tom & Jerry\\'s</a>')
+        '&lt;a href=&quot;x&quot;&gt;Tom &amp; Jerry&#x27;s&lt;/a&gt;'
+    """
+    _validate_input(input_str)
+    
+    return input_str.translate(_HTML_ESCAPES)
+
+
+_JS_STRING_ESCAPES = {
+    ord("\\"): "\\\\",
+    ord("'"): "\\'",
+    ord('"'): '\\"',
+    ord("`"): "\\`",
+    ord("$"): "\\$",
+    ord("\n"): "\\n",
+    ord("\r"): "\\r",
+    ord("\t"): "\\t",
+    ord("<"): "\\u003C",
+    ord(">"): "\\u003E",
+    ord("&"): "\\u0026",
+    ord("\u2028"): "\\u2028",
+    ord("\u2029"): "\\u2029",
+}
+
+
+def escape_js_string(input_str: str) -> str:
+    """
+    Escape a string for use inside a JavaScript string literal.
+    
+    The result is meant to go between the quotes of a '...', "..." or
+    `...` literal; it does not add the quotes itself. Backslashes, all
+    three quote characters and $ are escaped, so the string can neither
+    end the literal nor start a ${...} substitution in a template literal.
+    Line breaks, including U+2028 and U+2029, become escape sequences,
+    and <, > and & are written as \\u escapes so the literal can also sit
+    inside an HTML script element without closing it. The result is not
+    safe outside a string literal, such as in an identifier or a regular
+    expression.
+    
+    Args:
+        input_str: The text to escape
+        
+    Returns:
+        The escaped text, without surrounding quotes
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> escape_js_string("it's </script>")
+        "it\\\\'s \\\\u003C/script\\\\u003E"
+        >>> print(escape_js_string('say "hi"\\n'))
+        say \\"hi\\"\\n
+    """
+    _validate_input(input_str)
+    
+    return input_str.translate(_JS_STRING_ESCAPES)
+
+
+def escape_sql_like(input_str: str, escape_char: str = "\\") -> str:
+    """
+    Escape the wildcards in a string for use as a literal SQL LIKE pattern.
+    
+    The LIKE wildcards % and _ and the escape character itself are each
+    prefixed with escape_char, so the pattern matches the text literally.
+    The query must declare the same character, as in
+    "name LIKE ? ESCAPE '\\'", because databases differ in their default.
+    Wildcards can be added around the result, as in "%" + result + "%" to
+    search for a substring. This only neutralizes wildcards: the pattern
+    must still be passed as a query parameter, never spliced into the SQL.
+    
+    Args:
+        input_str: The text to match literally
+        escape_char: The escape character named in the ESCAPE clause.
+            Defaults to a backslash.
+        
+    Returns:
+        The pattern with wildcards and escape characters escaped
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH), or
+            escape_char is not a single character or is % or _
+        
+    Examples:
+        >>> print(escape_sql_like("50%_off"))
+        50\\%\\_off
+        >>> escape_sql_like("a!b%", "!")
+        'a!!b!%'
+    """
+    _validate_input(input_str)
+    if not isinstance(escape_char, str) or len(escape_char) != 1:
+        raise ValueError("Escape character must be a single character")
+    if escape_char in "%_":
+        raise ValueError("Escape character cannot be a LIKE wildcard")
+    
+    return "".join(
+        escape_char + char if char in ("%", "_", escape_char) else char
+        for char in input_str
+    )
+
+
+def escape_shell_arg(input_str: str) -> str:
+    """
+    Quote a string as one argument for a POSIX shell command line.
+    
+    The argument is always wrapped in single quotes, inside which sh,
+    bash and zsh treat every character literally, and each single quote
+    in it is written as '\\''. The result can be joined into a command
+    line with spaces and is read back by split_quoted; quote_posix quotes
+    only arguments that need it. It is not valid for Windows cmd.exe or
+    PowerShell (see quote_windows). Prefer passing an argument list to
+    subprocess without a shell where possible.
+    
+    Args:
+        input_str: The argument to quote
+        
+    Returns:
+        The quoted argument
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> print(escape_shell_arg("it's $HOME"))
+        'it'\\''s $HOME'
+        >>> split_quoted("rm " + escape_shell_arg("-rf; reboot"))
+        ['rm', '-rf; reboot']
+    """
+    _validate_input(input_str)
+    
+    return "'" + input_str.replace("'", "'\\''") + "'"
+
+
+# Characters that never need quoting in a POSIX shell word.
+_POSIX_SAFE_ARGUMENT = re.compile(r"[A-Za-z0-9@%+=:,./_-]+")
+
+
+def quote_posix(input_str: str) -> str:
+    """
+    Quote a string as one POSIX shell argument, only if it needs quoting.
+    
+    Arguments made only of letters, digits and @ % + = : , . / _ - are
+    returned unchanged, since no POSIX shell treats them specially;
+    anything else, including the empty string, is quoted as by
+    escape_shell_arg. The result is read back by split_quoted.
+    
+    Args:
+        input_str: The argument to quote
+        
+    Returns:
+        The argument, quoted if necessary
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> quote_posix("--output=out/file.txt")
+        '--output=out/file.txt'
+        >>> print(quote_posix("it's here"))
+        'it'\\''s here'
+    """
+    _validate_input(input_str)
+    
+    if _POSIX_SAFE_ARGUMENT.fullmatch(input_str):
+        return input_str
+    return escape_shell_arg(input_str)
+
+
+def quote_windows(input_str: str) -> str:
+    """
+    Quote a string as one argument of a Windows command line.
+    
+    The argument is quoted so that CommandLineToArgvW and the Microsoft C
+    runtime, which most Windows programs use to parse their command line,
+    read it back unchanged. It is returned as is unless it is empty or
+    contains whitespace or a double quote; otherwise it is wrapped in
+    double quotes, each double quote inside is escaped with a backslash,
+    and backslashes before a double quote or the closing quote are
+    doubled. Other backslashes are literal on Windows and are not
+    changed.
+    
+    This does not protect against cmd.exe, which expands %VARIABLES% and
+    interprets ^ & | < > before the program sees its arguments: do not
+    pass untrusted arguments through cmd /c or a .bat file.
+    
+    Args:
+        input_str: The argument to quote
+        
+    Returns:
+        The argument, quoted if necessary
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> print(quote_windows("C:\\\\Program Files\\\\"))
+        "C:\\Program Files\\\\"
+        >>> print(quote_windows('say "hi"'))
+        "say \\"hi\\""
+    """
+    _validate_input(input_str)
+    
+    if input_str and not any(char.isspace() or char == '"' for char in input_str):
+        return input_str
+    parts = ['"']
+    backslashes = 0
+    for char in input_str:
+        if char == "\\":
+            backslashes += 1
+            continue
+        if char == '"':
+            parts.append("\\" * (backslashes * 2 + 1) + '"')
+        else:
+            parts.append("\\" * backslashes + char)
+        backslashes = 0
+    parts.append("\\" * (backslashes * 2) + '"')
+    return "".join(parts)
+
+
+def _check_arguments(args: Iterable[str]) -> List[str]:
+    """Return args as a list, raising unless it is an iterable of strings."""
+    if isinstance(args, str):
+        raise TypeError("Arguments must be an iterable of strings, not a string")
+    return list(args)
+
+
+def join_posix(args: Iterable[str]) -> str:
+    """
+    Join arguments into a POSIX shell command line.
+    
+    Each argument is quoted as by quote_posix and the results are
+    separated by spaces, so split_quoted returns the original arguments.
+    
+    Args:
+        args: The program and its arguments
+        
+    Returns:
+        The command line
+        
+    Raises:
+        TypeError: If args is a string or an argument is not a string
+        ValueError: If an argument fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> print(join_posix(["grep", "-r", "it's", "my dir"]))
+        grep -r 'it'\\''s' 'my dir'
+    """
+    return " ".join(quote_posix(arg) for arg in _check_arguments(args))
+
+
+def join_windows(args: Iterable[str]) -> str:
+    """
+    Join arguments into a Windows command line.
+    
+    Each argument is quoted as by quote_windows and the results are
+    separated by spaces, so CommandLineToArgvW returns the original
+    arguments. The same caveats about cmd.exe apply.
+    
+    Args:
+        args: The program and its arguments
+        
+    Returns:
+        The command line
+        
+    Raises:
+        TypeError: If args is a string or an argument is not a string
+        ValueError: If an argument fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> print(join_windows(["notepad.exe", "C:\\\\My Notes\\\\todo.txt", ""]))
+        notepad.exe "C:\\My Notes\\todo.txt" ""
+    """
+    return " ".join(quote_windows(arg) for arg in _check_arguments(args))
+
+
+# Characters RFC 3986 allows unencoded in each URI component (section 3).
+_URI_UNRESERVED = frozenset(
+    "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-._~"
+)
+_URI_SUB_DELIMS = frozenset("!$&'()*+,;=")
+_URI_PCHAR = _URI_UNRESERVED | _URI_SUB_DELIMS | frozenset(":@")
+_URI_PATH = _URI_PCHAR | frozenset("/")
+_URI_FRAGMENT = _URI_PCHAR | frozenset("/?")
+# A query value must also encode the delimiters of key=value&... pairs,
+# and "+", which form decoding reads as a space.
+_URI_QUERY_VALUE = _URI_FRAGMENT - frozenset("&=+")
+
+_PERCENT_ENCODED = re.compile("%([0-9A-Fa-f]{2})")
+
+
+def _percent_encode(input_str: str, safe: FrozenSet[str]) -> str:
+    """Percent-encode the UTF-8 bytes of every character not in safe."""
+    return "".join(
+        char
+        if char in safe
+        else "".join(f"%{byte:02X}" for byte in char.encode("utf-8"))
+        for char in input_str
+    )
+
+
+def escape_path(input_str: str, segment: bool = False) -> str:
+    """
+    Percent-encode a string for the path component of a URI.
+    
+    Every character RFC 3986 does not allow in a path is encoded as the
+    %XX escapes of its UTF-8 bytes, in uppercase hex: everything except
+    letters, digits, "-._~", the sub-delimiters "!$&'()*+,;=", ":", "@"
+    and "/". A "%" is always encoded, so the input is treated as text
+    rather than as something already escaped.
+    
+    Args:
+        input_str: The path, or a single path segment
+        segment: If True, "/" is encoded too, so the input stays one
+            segment, as for a file name or an identifier placed in a path.
+            Defaults to False.
+        
+    Returns:
+        The escaped path
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> escape_path("/docs/my report (final).pdf")
+        '/docs/my%20report%20(final).pdf'
+        >>> escape_path("AC/DC?", segment=True)
+        'AC%2FDC%3F'
+        >>> escape_path("/caf\u00e9/100%")
+        '/caf%C3%A9/100%25'
+    """
+    _validate_input(input_str)
+    
+    return _percent_encode(input_str, _URI_PCHAR if segment else _URI_PATH)
+
+
+def escape_query(input_str: str) -> str:
+    """
+    Percent-encode a string for use as a key or value in a URI query.
+    
+    Characters RFC 3986 does not allow in a query are encoded as in
+    escape_path. "/", "?", ":" and "@" are allowed in a query and kept,
+    but "&", "=" and "+" are encoded as well, although the RFC allows
+    them, because they separate and decode the key=value pairs of a
+    conventional query string. Spaces become "%20", never "+", which
+    every decoder reads as a space.
+    
+    Args:
+        input_str: A single key or value
+        
+    Returns:
+        The escaped key or value
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> "?q=" + escape_query("fish & chips") + "&next=" + escape_query("/a?b=1")
+        '?q=fish%20%26%20chips&next=/a?b%3D1'
+        >>> escape_query("1+1=2")
+        '1%2B1%3D2'
+    """
+    _validate_input(input_str)
+    
+    return _percent_encode(input_str, _URI_QUERY_VALUE)
+
+
+def escape_fragment(input_str: str) -> str:
+    """
+    Percent-encode a string for the fragment component of a URI.
+    
+    Characters RFC 3986 does not allow in a fragment are encoded as in
+    escape_path: everything except letters, digits, "-._~", the
+    sub-delimiters "!$&'()*+,;=", ":", "@", "/" and "?". In particular
+    "#" is encoded, since a URI has only one fragment.
+    
+    Args:
+        input_str: The fragment, without the leading "#"
+        
+    Returns:
+        The escaped fragment
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> "#" + escape_fragment("section 2.1 #notes")
+        '#section%202.1%20%23notes'
+    """
+    _validate_input(input_str)
+    
+    return _percent_encode(input_str, _URI_FRAGMENT)
+
+
+def unescape_strict(input_str: str) -> str:
+    """
+    Decode the percent-encoding of any URI component, rejecting bad input.
+    
+    Each %XX escape is decoded to its byte, and consecutive bytes are
+    decoded as UTF-8. Unlike lenient decoders, which leave malformed
+    escapes in place or substitute U+FFFD, any doubt is an error: a "%"
+    not followed by two hex digits, bytes that are not valid UTF-8, and
+    escapes of control characters such as "%00", which could truncate
+    or split the value where it is used next. "+" is left as it is; it
+    only means a space in HTML form data.
+    
+    Args:
+        input_str: The escaped text, such as a path segment or query value
+        
+    Returns:
+        The decoded text
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH), or
+            contains a malformed escape, escaped bytes that are not valid
+            UTF-8 or an escaped control character other than tab, newline
+            and carriage return
+        
+    Examples:
+        >>> unescape_strict("caf%C3%A9%20au%20lait+sucre")
+        'café au lait+sucre'
+        >>> unescape_strict("100%")
+        Traceback (most recent call last):
+            ...
+        ValueError: Malformed percent-encoding '%' at index 3
+    """
+    _validate_input(input_str)
+    
+    parts: List[str] = []
+    index = 0
+    while index < len(input_str):
+        percent = input_str.find("%", index)
+        if percent < 0:
+            parts.append(input_str[index:])
+            break
+        parts.append(input_str[index:percent])
+        encoded = bytearray()
+        index = percent
+        while index < len(input_str) and input_str[index] == "%":
+            match = _PERCENT_ENCODED.match(input_str, index)
+            if match is None:
+                raise ValueError(
+                    "Malformed percent-encoding "
+                    f"{input_str[index : index + 3]!r} at index {index}"
+                )
+            encoded.append(int(match.group(1), 16))
+            index = match.end()
+        try:
+            decoded = encoded.decode("utf-8")
+        except UnicodeDecodeError as error:
+            raise ValueError(
+                "Percent-encoded bytes at index "
+                f"{percent + 3 * error.start} are not valid UTF-8"
+            ) from None
+        for offset, char in enumerate(decoded):
+            if char in _DISALLOWED_CONTROLS:
+                raise ValueError(
+                    "Percent-encoding at index "
+                    f"{percent + 3 * len(decoded[:offset].encode('utf-8'))} "
+                    f"decodes to control character {char!r}"
+                )
+        parts.append(decoded)
+    return "".join(parts)
+
+
+def _kmp_failure_table(needle: str) -> List[int]:
+    """Build the Knuth-Morris-Pratt longest proper prefix-suffix table."""
+    table = [0] * len(needle)
+    length = 0
+    for index in range(1, len(needle)):
+        while length and needle[index] != needle[length]:
+            length = table[length - 1]
+        if needle[index] == needle[length]:
+            length += 1
+        table[index] = length
+    return table
+
+
+def index_all(haystack: str, needle: str) -> List[int]:
+    """
+    Find the offsets of all non-overlapping occurrences of a substring.
+    
+    Uses the Knuth-Morris-Pratt algorithm, so the scan is linear in the
+    combined length of haystack and needle. Offsets are character indices
+    into haystack, suitable for slicing.
+    
+    Args:
+        haystack: The string to search
+        needle: The substring to find
+        
+    Returns:
+        The start offsets of every non-overlapping match, in ascending
+        order; an empty list if needle is empty or not found
+        
+    Raises:
+        TypeError: If either argument is not a string
+        
+    Examples:
+        >>> index_all("abcabcab", "ab")
+        [0, 3, 6]
+        >>> index_all("aaaa", "aa")
+        [0, 2]
+        >>> index_all("abc", "")
+        []
+    """
+    for value in (haystack, needle):
+        if not isinstance(value, str):
+            raise TypeError(
+                f"Input must be a string, got {type(value).__name__}"
+            )
+    
+    if not needle:
+        return []
+    
+    table = _kmp_failure_table(needle)
+    offsets: List[int] = []
+    matched = 0
+    for index, char in enumerate(haystack):
+        while matched and char != needle[matched]:
+            matched = table[matched - 1]
+        if char == needle[matched]:
+            matched += 1
+        if matched == len(needle):
+            offsets.append(index - matched + 1)
+            matched = 0
+    return offsets
+
+
+def _match_case(template: str, word: str) -> str:
+    """
+    Recase a word to follow the casing pattern of a template.
+    
+    The pattern is taken from the cased letters of template: if all of
+    them are lowercase the word is lowercased; if there are at least two
+    and all are uppercase the word is uppercased; if the first is
+    uppercase and the rest lowercase (including a lone uppercase letter)
+    the word is title-cased. Any other pattern, or a template without
+    cased letters, leaves the word as given.
+    """
+    letters = [char for char in template if char.isupper() or char.islower()]
+    if not letters:
+        return word
+    if all(char.islower() for char in letters):
+        return word.lower()
+    if len(letters) > 1 and all(char.isupper() for char in letters):
+        return word.upper()
+    if letters[0].isupper() and all(char.islower() for char in letters[1:]):
+        return word[:1].upper() + word[1:].lower()
+    return word
+
+
+def replace_word_preserve_case(input_str: str, old_word: str, new_word: str) -> str:
+    """
+    Replace whole-word matches, adapting the replacement to the match's case.
+    
+    Matching is case-insensitive and only whole words are replaced: a match
+    must not be preceded or followed by a letter, digit or underscore. Each
+    replacement is recased to follow the matched text: all-lowercase
+    matches give a lowercase replacement, ALL-CAPS matches (two or more
+    letters) give an uppercase replacement, and Title matches (first letter
+    uppercase, rest lowercase) give a title-cased replacement. Matches with
+    any other casing receive new_word unchanged.
+    
+    Args:
+        input_str: The text to search
+        old_word: The word to replace
+        new_word: The replacement word
+        
+    Returns:
+        The text with every whole-word match replaced
+        
+    Raises:
+        TypeError: If any argument is not a string
+        ValueError: If input fails validation or old_word is empty
+        
+    Examples:
+        >>> replace_word_preserve_case("Cat and cat and CAT", "cat", "dog")
+        'Dog and dog and DOG'
+        >>> replace_word_preserve_case("concatenate", "cat", "dog")
+        'concatenate'
+    """
+    _validate_input(input_str)
+    for value in (old_word, new_word):
+        if not isinstance(value, str):
+            raise TypeError(
+                f"Input must be a string, got {type(value).__name__}"
+            )
+    if not old_word:
+        raise ValueError("Word to replace must be a non-empty string")
+    
+    pattern = re.compile(
+        r"(?<!\w)" + re.escape(old_word) + r"(?!\w)", re.IGNORECASE
+    )
+    return pattern.sub(
+        lambda match: _match_case(match.group(0), new_word), input_str
+    )
+
+
+def replace_preserving_case(input_str: str, old: str, new: str) -> str:
+    """
+    Replace every occurrence of a substring, mirroring the case of each match.
+    
+    Like replace_word_preserve_case, but matches anywhere, including inside
+    longer words, which suits identifier refactoring and spelling
+    localization: with "color" replaced by "colour", "backgroundColor"
+    becomes "backgroundColour". Matching is case-insensitive and matches
+    do not overlap.
+    
+    Args:
+        input_str: The text to search
+        old: The substring to replace
+        new: The replacement, recased to follow each match
+        
+    Returns:
+        The text with every match replaced
+        
+    Raises:
+        TypeError: If any argument is not a string
+        ValueError: If input fails validation or old is empty
+        
+    Examples:
+        >>> replace_preserving_case("Color, COLOR, color", "color", "colour")
+        'Colour, COLOUR, colour'
+        >>> replace_preserving_case("backgroundColor", "color", "colour")
+        'backgroundColour'
+    """
+    _validate_input(input_str)
+    for value in (old, new):
+        if not isinstance(value, str):
+            raise TypeError(
+                f"Input must be a string, got {type(value).__name__}"
+            )
+    if not old:
+        raise ValueError("Text to replace must be a non-empty string")
+    
+    pattern = re.compile(re.escape(old), re.IGNORECASE)
+    return pattern.sub(lambda match: _match_case(match.group(0), new), input_str)
+
+
+def hamming_distance(first: str, second: str) -> int:
+    """
+    Count the positions at which two equal-length strings differ.
+    
+    Comparison is per character (code point), so multi-byte characters
+    count as a single position.
+    
+    Args:
+        first: The first string
+        second: The second string
+        
+    Returns:
+        The number of positions holding different characters
+        
+    Raises:
+        TypeError: If either argument is not a string
+        ValueError: If the strings have different lengths
+        
+    Examples:
+        >>> hamming_distance("karolin", "kathrin")
+        3
+        >>> hamming_distance("café", "cafe")
+        1
+    """
+    for value in (first, second):
+        if not isinstance(value, str):
+            raise TypeError(
+                f"Input must be a string, got {type(value).__name__}"
+            )
+    if len(first) != len(second):
+        raise ValueError(
+            f"Strings must have equal length, got {len(first)} and {len(second)}"
+        )
+    
+    return sum(1 for left, right in zip(first, second) if left != right)
+
+
+@dataclass
+class TableOptions:
+    """
+    Formatting options for render_table.
+    
+    Attributes:
+        separator: The string placed between adjacent columns
+        header_underline: Whether to draw a line of dashes under the first row
+    """
+    
+    separator: str = " | "
+    header_underline: bool = False
+
+
+def render_table(
+    rows: Sequence[Sequence[str]], options: Optional[TableOptions] = None
+) -> str:
+    """
+    Render rows of cells as an aligned, fixed-width text table.
+    
+    Column widths are computed from the display width of the cells, so
+    wide East Asian characters and combining marks line up correctly in a
+    monospace terminal. Ragged rows are padded with empty cells up to the
+    widest row. The last column is not padded, so lines carry no trailing
+    whitespace.
+    
+    Args:
+        rows: The table rows; each row is a sequence of cell strings
+        options: Formatting options; defaults to TableOptions()
+        
+    Returns:
+        The rendered table, with lines joined by newlines and no trailing
+        newline; an empty string if there are no rows
+        
+    Raises:
+        TypeError: If any cell is not a string
+        
+    Examples:
+        >>> print(render_table([["name", "qty"], ["apple", "3"]]))
+        name  | qty
+        apple | 3
+    """
+    if options is None:
+        options = TableOptions()
+    
+    for row in rows:
+        for cell in row:
+            if not isinstance(cell, str):
+                raise TypeError(
+                    f"Table cells must be strings, got {type(cell).__name__}"
+                )
+    
+    column_count = max((len(row) for row in rows), default=0)
+    if column_count == 0:
+        return ""
+    
+    grid = [list(row) + [""] * (column_count - len(row)) for row in rows]
+    widths = [
+        max(_display_width(row[column]) for row in grid)
+        for column in range(column_count)
+    ]
+    
+    def render_row(cells: List[str]) -> str:
+        padded = [
+            cell + " " * (width - _display_width(cell))
+            for cell, width in zip(cells[:-1], widths)
+        ]
+        return options.separator.join(padded + [cells[-1]])
+    
+    lines = [render_row(row) for row in grid]
+    if options.header_underline:
+        underline = options.separator.join("-" * width for width in widths)
+        lines.insert(1, underline)
+    return "\n".join(lines)
+
+
+def capitalize_words_preserve(input_str: str, protect: "re.Pattern[str]") -> str:
+    """
+    Capitalize words while leaving text matching a pattern untouched.
+    
+    Every non-overlapping match of protect (URLs, mentions, code, version
+    numbers, ...) is copied to the output unchanged; everything else is
+    capitalized like capitalize_words. Capitalization state carries across
+    protected regions: a region without whitespace neither starts nor ends
+    a word, so a protected tag before a word does not stop it from being
+    capitalized and a protected run in the middle of a word does not cause
+    the rest of it to be capitalized. A region containing whitespace ends
+    the current word, and the text after it starts a new word only if the
+    region itself ends with whitespace.
+    
+    Args:
+        input_str: The string whose words to capitalize
+        protect: A compiled pattern whose matches must be preserved
+        
+    Returns:
+        The capitalized string with protected regions unchanged
+        
+    Raises:
+        TypeError: If input is not a string or protect is not a pattern
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> url = re.compile(r"https?://\\S+")
+        >>> capitalize_words_preserve("see https://a.io/x now", url)
+        'See https://a.io/x Now'
+        >>> capitalize_words_preserve("<b>bold</b> text", re.compile(r"<[^>]*>"))
+        '<b>Bold</b> Text'
+    """
+    _validate_input(input_str)
+    if not isinstance(protect, re.Pattern):
+        raise TypeError(
+            f"Protect must be a compiled pattern, got {type(protect).__name__}"
+        )
+    
+    parts: List[str] = []
+    capitalize_next = True
+    position = 0
+    for match in protect.finditer(input_str):
+        start, end = match.span()
+        if start == end:
+            continue
+        segment, _, capitalize_next = _DEFAULT_CAPITALIZER._capitalize_part(
+            input_str[position:start], capitalize_next
+        )
+        parts.append(segment)
+        protected = match.group(0)
+        parts.append(protected)
+        if any(char.isspace() for char in protected):
+            capitalize_next = protected[-1].isspace()
+        position = end
+    segment, _, _ = _DEFAULT_CAPITALIZER._capitalize_part(
+        input_str[position:], capitalize_next
+    )
+    parts.append(segment)
+    return "".join(parts)
+
+
+def split_keep(input_str: str, is_sep: Callable[[str], bool]) -> List[str]:
+    """
+    Split a string into alternating runs of separators and non-separators.
+    
+    Separator runs are kept as their own elements, so "".join() of the
+    result always reproduces the input. A leading separator run is the
+    first element and a trailing separator run is the last element.
+    
+    Args:
+        input_str: The string to split
+        is_sep: Predicate called with each character; True marks a separator
+        
+    Returns:
+        The runs in order; an empty list for an empty string
+        
+    Raises:
+        TypeError: If input is not a string
+        
+    Examples:
+        >>> split_keep("  hello, world", str.isspace)
+        ['  ', 'hello,', ' ', 'world']
+        >>> split_keep("a--b", lambda char: char == "-")
+        ['a', '--', 'b']
+    """
+    if not isinstance(input_str, str):
+        raise TypeError(
+            f"Input must be a string, got {type(input_str).__name__}"
+        )
+    
+    runs: List[str] = []
+    start = 0
+    for index in range(1, len(input_str)):
+        if is_sep(input_str[index]) != is_sep(input_str[index - 1]):
+            runs.append(input_str[start:index])
+            start = index
+    if input_str:
+        runs.append(input_str[start:])
+    return runs
+
+
+def split_fields(
+    input_str: str, is_sep: Callable[[str], bool] = str.isspace
+) -> Tuple[List[str], List[str]]:
+    """
+    Split a string into tokens and the separator runs around them.
+    
+    There is always one more separator run than there are tokens: the
+    first run comes before the first token and the last run after the
+    last token, and either may be empty. Joining the runs and tokens
+    alternately, starting and ending with a run, reproduces the input,
+    so a transformation can change the tokens and keep the spacing:
+    
+        tokens, separators = split_fields(text)
+        tokens = [token.upper() for token in tokens]
+        result = separators[0] + "".join(
+            token + separator for token, separator in zip(tokens, separators[1:])
+        )
+        
+    Args:
+        input_str: The string to split
+        is_sep: Predicate called with each character; True marks a
+            separator. Defaults to str.isspace.
+        
+    Returns:
+        The tokens and the separator runs, in order
+        
+    Raises:
+        TypeError: If input is not a string
+        
+    Examples:
+        >>> split_fields("  hello,  world")
+        (['hello,', 'world'], ['  ', '  ', ''])
+        >>> split_fields("")
+        ([], [''])
+    """
+    runs = split_keep(input_str, is_sep)
+    tokens: List[str] = []
+    separators = [""]
+    for run in runs:
+        if is_sep(run[0]):
+            separators[-1] = run
+        else:
+            tokens.append(run)
+            separators.append("")
+    return tokens, separators
+
+
+# Characters a backslash escapes inside double quotes in a POSIX shell;
+# before any other character the backslash is kept.
+_DOUBLE_QUOTE_ESCAPES = frozenset('$`"\\\n')
+
+
+def split_quoted(input_str: str) -> List[str]:
+    """
+    Split a command string into arguments, as a POSIX shell does.
+    
+    Arguments are separated by unquoted whitespace. Single quotes keep
+    everything up to the next single quote literally. Double quotes keep
+    whitespace and single quotes, and a backslash inside them escapes only
+    $, `, ", \\ and newline. Outside quotes a backslash escapes any
+    character, and a backslash before a newline joins lines. Quotes can
+    be adjacent to other text in one argument, and "" is an empty
+    argument. No variable, glob or tilde expansion is done.
+    
+    Args:
+        input_str: The command string to split
+        
+    Returns:
+        The arguments with quotes and escapes removed
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH), has
+            an unterminated quote, or ends with an unescaped backslash
+        
+    Examples:
+        >>> split_quoted('git commit -m "fix the bug" --author=\\'Jo Ng\\'')
+        ['git', 'commit', '-m', 'fix the bug', '--author=Jo Ng']
+        >>> split_quoted(r"say \\"hi\\" it\\'s ''")
+        ['say', '"hi"', "it's", '']
+    """
+    _validate_input(input_str)
+    
+    args: List[str] = []
+    chars: List[str] = []
+    in_arg = False
+    index = 0
+    while index < len(input_str):
+        char = input_str[index]
+        if char.isspace():
+            if in_arg:
+                args.append("".join(chars))
+                chars = []
+                in_arg = False
+            index += 1
+            continue
+        if char == "\\":
+            if index + 1 == len(input_str):
+                raise ValueError("Input ends with an unescaped backslash")
+            if input_str[index + 1] != "\n":
+                chars.append(input_str[index + 1])
+                in_arg = True
+            index += 2
+            continue
+        in_arg = True
+        if char not in "'\"":
+            chars.append(char)
+            index += 1
+            continue
+        end = index + 1
+        while end < len(input_str) and input_str[end] != char:
+            if char == '"' and input_str[end] == "\\" and end + 1 < len(input_str):
+                escaped = input_str[end + 1]
+                if escaped != "\n":
+                    if escaped not in _DOUBLE_QUOTE_ESCAPES:
+                        chars.append("\\")
+                    chars.append(escaped)
+                end += 2
+            else:
+                chars.append(input_str[end])
+                end += 1
+        if end >= len(input_str):
+            kind = "single" if char == "'" else "double"
+            raise ValueError(f"Unterminated {kind} quote at index {index}")
+        index = end + 1
+    if in_arg:
+        args.append("".join(chars))
+    return args
+
+
+def _strip_punctuation(word: str) -> str:
+    """Remove leading and trailing punctuation characters from a word."""
+    start, end = 0, len(word)
+    while start < end and unicodedata.category(word[start]).startswith("P"):
+        start += 1
+    while end > start and unicodedata.category(word[end - 1]).startswith("P"):
+        end -= 1
+    return word[start:end]
+
+
+def jaccard_similarity(
+    first: str, second: str, fold_case: bool = False, strip_punctuation: bool = False
+) -> float:
+    """
+    Compute the Jaccard similarity of the word sets of two strings.
+    
+    The similarity is the size of the intersection of the two word sets
+    divided by the size of their union. Words are whitespace-delimited,
+    the same boundaries used by capitalize_words.
+    
+    Args:
+        first: The first text
+        second: The second text
+        fold_case: Whether to compare words case-insensitively
+        strip_punctuation: Whether to remove leading and trailing
+            punctuation from each word before comparing
+        
+    Returns:
+        A value between 0.0 (no shared words) and 1.0 (identical word
+        sets); two texts without any words are considered identical
+        
+    Raises:
+        TypeError: If either argument is not a string
+        ValueError: If either argument fails validation
+        
+    Examples:
+        >>> jaccard_similarity("the cat sat", "the cat ran")
+        0.5
+        >>> jaccard_similarity("Hello, world", "hello world", True, True)
+        1.0
+    """
+    _validate_input(first)
+    _validate_input(second)
+    
+    def word_set(text: str) -> Set[str]:
+        words: Set[str] = set()
+        for start, end in _word_spans(text):
+            word = text[start:end]
+            if strip_punctuation:
+                word = _strip_punctuation(word)
+            if fold_case:
+                word = word.casefold()
+            if word:
+                words.add(word)
+        return words
+    
+    first_words, second_words = word_set(first), word_set(second)
+    union = first_words | second_words
+    if not union:
+        return 1.0
+    return len(first_words & second_words) / len(union)
+
+
+def normalize_indent(input_str: str, use_tabs: bool, tab_width: int = 4) -> str:
+    """
+    Rewrite the leading whitespace of every line consistently.
+    
+    The indentation of each line (its leading spaces and tabs) is measured
+    in columns, with a tab advancing to the next multiple of tab_width, and
+    is then rewritten as spaces only or as tabs. When converting to tabs,
+    indentation that is not a whole number of tab stops keeps the remainder
+    as spaces after the tabs, so alignment is never lost. Everything after
+    the indentation, and the line endings, are preserved.
+    
+    Args:
+        input_str: The text to re-indent
+        use_tabs: True to indent with tabs, False to indent with spaces
+        tab_width: The number of columns per tab stop
+        
+    Returns:
+        The text with normalized indentation
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation or tab_width is less than 1
+        
+    Examples:
+        >>> normalize_indent("\\tif x:\\n    \\ty = 1", use_tabs=False)
+        '    if x:\\n        y = 1'
+        >>> normalize_indent("      z", use_tabs=True)
+        '\\t  z'
+    """
+    _validate_input(input_str)
+    if tab_width < 1:
+        raise ValueError(f"Tab width must be at least 1, got {tab_width}")
+    
+    lines: List[str] = []
+    for line in input_str.splitlines(keepends=True):
+        content_start = len(line) - len(line.lstrip(" \t"))
+        column = 0
+        for char in line[:content_start]:
+            if char == "\t":
+                column += tab_width - column % tab_width
+            else:
+                column += 1
+        if use_tabs:
+            indent = "\t" * (column // tab_width) + " " * (column % tab_width)
+        else:
+            indent = " " * column
+        lines.append(indent + line[content_start:])
+    return "".join(lines)
+
+
+class LineEnding(Enum):
+    """The line terminators recognized by iter_lines."""
+    
+    LF = "\n"
+    CRLF = "\r\n"
+    CR = "\r"
+    NONE = ""
+
+
+@dataclass
+class Line:
+    """
+    A line of text found by iter_lines.
+    
+    Attributes:
+        text: The line without its terminator
+        ending: The terminator that ended the line; NONE for a last line
+            with no terminator
+    """
+    
+    text: str
+    ending: LineEnding
+
+
+_LINE_BREAK = re.compile("\r\n|\r|\n")
+
+
+def iter_lines(input_str: str) -> Iterator[Line]:
+    """
+    Iterate over the lines of a string together with their terminators.
+    
+    Lines end at LF, CRLF or a lone CR; unlike str.splitlines, other
+    separators such as U+2028 LINE SEPARATOR are part of the line.
+    Joining each line's text and terminator reproduces the input, so a
+    tool can change the lines and keep the original line endings.
+    
+    Args:
+        input_str: The text to split
+        
+    Returns:
+        An iterator over the lines, in order. A terminator at the end of
+        the text does not start another line, and an empty string has no
+        lines.
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> [(line.text, line.ending.name) for line in iter_lines("a\\r\\nb")]
+        [('a', 'CRLF'), ('b', 'NONE')]
+    """
+    _validate_input(input_str)
+    
+    return _iter_lines(input_str)
+
+
+def _iter_lines(input_str: str) -> Iterator[Line]:
+    """Yield the lines of a string that has already passed validation."""
+    start = 0
+    for match in _LINE_BREAK.finditer(input_str):
+        yield Line(input_str[start : match.start()], LineEnding(match.group(0)))
+        start = match.end()
+    if start < len(input_str):
+        yield Line(input_str[start:], LineEnding.NONE)
+
+
+def detect_line_ending(input_str: str) -> LineEnding:
+    """
+    Find the line terminator a text mostly uses.
+    
+    Args:
+        input_str: The text to inspect
+        
+    Returns:
+        The most common terminator; on a tie, the one that appears first.
+        NONE if the text has no line breaks.
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> detect_line_ending("one\\r\\ntwo\\r\\nthree\\n")
+        <LineEnding.CRLF: '\\r\\n'>
+        >>> detect_line_ending("single line")
+        <LineEnding.NONE: ''>
+    """
+    _validate_input(input_str)
+    
+    counts: Dict[LineEnding, int] = {}
+    for match in _LINE_BREAK.finditer(input_str):
+        ending = LineEnding(match.group(0))
+        counts[ending] = counts.get(ending, 0) + 1
+    return max(counts, key=counts.__getitem__, default=LineEnding.NONE)
+
+
+# Marker that replaces the path, query and fragment removed from a URL.
+URL_REDACTION_MARKER = "/…"
+
+_URL_PATTERN = re.compile(
+    r"(?<![\w+.-])(?P<scheme>[A-Za-z][A-Za-z0-9+.-]*://)"
+    r"(?P<authority>[^\s/?#]*)(?P<rest>\S*)"
+)
+
+# Punctuation at the end of a URL token that belongs to the surrounding prose.
+_URL_TRAILING_PUNCTUATION = re.compile(r"[.,;:!?)\]}'\"]+$")
+
+
+def redact_url_paths(input_str: str) -> str:
+    """
+    Strip the path, query and fragment from every URL in a text.
+    
+    URLs are detected heuristically as a scheme followed by "://" inside a
+    whitespace-delimited token. Each URL is reduced to its scheme and host
+    (with port); any user information before the host is dropped, and if a
+    non-empty path, query or fragment was removed it is replaced by
+    URL_REDACTION_MARKER ("/…"). Punctuation ending the token, such as a
+    full stop or closing parenthesis, is kept. All other text is preserved
+    exactly.
+    
+    Args:
+        input_str: The text to redact
+        
+    Returns:
+        The text with URL paths and queries removed
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> redact_url_paths("GET https://site.com/user/123?token=abc done")
+        'GET https://site.com/… done'
+        >>> redact_url_paths("see (http://example.org).")
+        'see (http://example.org).'
+    """
+    _validate_input(input_str)
+    
+    def redact(match: "re.Match[str]") -> str:
+        authority, rest = match.group("authority"), match.group("rest")
+        trailing = ""
+        if rest:
+            punctuation = _URL_TRAILING_PUNCTUATION.search(rest)
+            if punctuation is not None:
+                trailing, rest = punctuation.group(0), rest[: punctuation.start()]
+        else:
+            punctuation = _URL_TRAILING_PUNCTUATION.search(authority)
+            if punctuation is not None:
+                trailing = punctuation.group(0)
+                authority = authority[: punctuation.start()]
+        host = authority.rpartition("@")[2]
+        if rest not in ("", "/"):
+            rest = URL_REDACTION_MARKER
+        return match.group("scheme") + host + rest + trailing
+    
+    return _URL_PATTERN.sub(redact, input_str)
+
+
+# Articles, conjunctions and short prepositions kept lowercase by title_case.
+DEFAULT_MINOR_WORDS = frozenset(
+    (
+        "a", "an", "the",
+        "and", "but", "or", "for", "nor",
+        "of", "on", "at", "to", "by", "in",
+    )
+)
+
+
+def title_case(input_str: str) -> str:
+    """
+    Convert a string to English title case.
+    
+    Like capitalize_words, but words in DEFAULT_MINOR_WORDS (articles,
+    conjunctions and short prepositions) are lowercased unless they are the
+    first or last word. Whitespace is preserved exactly.
+    
+    Args:
+        input_str: The string to convert
+        
+    Returns:
+        The title-cased string
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> title_case("the lord of the rings")
+        'The Lord of the Rings'
+        >>> title_case("what it is for")
+        'What It Is For'
+    """
+    return title_case_with_minor_words(input_str, DEFAULT_MINOR_WORDS)
+
+
+def title_case_with_minor_words(input_str: str, minor_words: Iterable[str]) -> str:
+    """
+    Convert a string to title case using a custom list of minor words.
+    
+    Minor words are matched case-insensitively, ignoring punctuation around
+    the word, and are lowercased unless they are the first or last word.
+    Every other word has its first letter capitalized as in
+    capitalize_words.
+    
+    Args:
+        input_str: The string to convert
+        minor_words: The words to keep lowercase inside the title
+        
+    Returns:
+        The title-cased string
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> title_case_with_minor_words("war and peace", ["and"])
+        'War and Peace'
+        >>> title_case_with_minor_words("a tale of two cities", [])
+        'A Tale Of Two Cities'
+    """
+    return Capitalizer(minor_words=minor_words).capitalize(input_str)
+
+
+class TitleStyle(Enum):
+    """Style guides supported by title_case_with_style."""
+    
+    AP = "ap"
+    CHICAGO = "chicago"
+    MLA = "mla"
+
+
+_ARTICLES = ("a", "an", "the")
+_PREPOSITIONS = (
+    "about", "above", "across", "after", "against", "along", "among",
+    "around", "at", "before", "behind", "below", "beneath", "beside",
+    "between", "beyond", "by", "down", "during", "except", "for", "from",
+    "in", "inside", "into", "like", "near", "of", "off", "on", "onto", "out",
+    "outside", "over", "past", "per", "since", "through", "throughout",
+    "till", "to", "toward", "under", "underneath", "until", "up", "upon",
+    "via", "with", "within", "without",
+)
+
+# Words each style guide keeps lowercase inside a title. AP lowercases
+# only prepositions of up to three letters; Chicago and MLA lowercase all
+# prepositions and differ on "so", "yet" and "as".
+_STYLE_MINOR_WORDS = {
+    TitleStyle.AP: frozenset(
+        _ARTICLES
+        + ("and", "but", "for", "nor", "or", "so", "yet")
+        + tuple(word for word in _PREPOSITIONS if len(word) <= 3)
+    ),
+    TitleStyle.CHICAGO: frozenset(
+        _ARTICLES + ("and", "but", "for", "nor", "or", "as") + _PREPOSITIONS
+    ),
+    TitleStyle.MLA: frozenset(
+        _ARTICLES + ("and", "but", "for", "nor", "or", "so", "yet") + _PREPOSITIONS
+    ),
+}
+
+
+def _title_case_word(
+    word: str, minor_words: FrozenSet[str], is_first: bool, is_last: bool
+) -> str:
+    """Title-case one word, treating each part of a hyphenated compound."""
+    parts = word.split("-")
+    for index, part in enumerate(parts):
+        forced = (is_first and index == 0) or (is_last and index == len(parts) - 1)
+        if not forced and _strip_punctuation(part).casefold() in minor_words:
+            parts[index] = part.lower()
+        else:
+            parts[index] = _capitalize_first(part)
+    return "-".join(parts)
+
+
+def title_case_with_style(input_str: str, style: TitleStyle) -> str:
+    """
+    Convert a string to title case following a style guide.
+    
+    Words in the style's list of articles, conjunctions and prepositions
+    are lowercased; every other word has its first letter capitalized as
+    in capitalize_words. The first and last words of the title, and of
+    each colon-separated subtitle, are always capitalized. Each part of a
+    hyphenated compound is treated as a word, so "run-of-the-mill" becomes
+    "Run-of-the-Mill".
+    
+    Args:
+        input_str: The string to convert
+        style: The style guide to follow
+        
+    Returns:
+        The title-cased string
+        
+    Raises:
+        TypeError: If input is not a string or style is not a TitleStyle
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> title_case_with_style("a walk through the woods", TitleStyle.AP)
+        'A Walk Through the Woods'
+        >>> title_case_with_style("a walk through the woods", TitleStyle.CHICAGO)
+        'A Walk through the Woods'
+        >>> title_case_with_style("star wars: a new hope", TitleStyle.MLA)
+        'Star Wars: A New Hope'
+    """
+    if not isinstance(style, TitleStyle):
+        raise TypeError(f"Style must be a TitleStyle, got {type(style).__name__}")
+    _validate_input(input_str)
+    
+    minor_words = _STYLE_MINOR_WORDS[style]
+    spans = _word_spans(input_str)
+    parts: List[str] = []
+    position = 0
+    for index, (start, end) in enumerate(spans):
+        word = input_str[start:end]
+        is_first = index == 0 or input_str[spans[index - 1][1] - 1] == ":"
+        is_last = index == len(spans) - 1 or word.endswith(":")
+        parts.append(input_str[position:start])
+        parts.append(_title_case_word(word, minor_words, is_first, is_last))
+        position = end
+    parts.append(input_str[position:])
+    return "".join(parts)
+
+
+def capitalize_words_stream(
+    reader: IO[Any], writer: IO[Any], chunk_size: int = STREAM_CHUNK_SIZE
+) -> None:
+    """
+    Capitalize words from a stream, writing the result incrementally.
+    
+    The input is read in chunks of chunk_size, so memory use stays bounded
+    regardless of the total size and MAX_STRING_LENGTH does not apply.
+    Capitalization state is carried from one chunk to the next, so a word
+    split across a chunk boundary is handled exactly as capitalize_words
+    would handle it. Text streams are written as text; binary streams are
+    decoded and re-encoded as UTF-8 incrementally, so a multi-byte
+    character split across a chunk boundary is never corrupted.
+    
+    Output already written before an invalid character is found is not
+    rolled back.
+    
+    Args:
+        reader: A text or binary stream with a read(size) method
+        writer: A stream of the same kind with a write() method
+        chunk_size: The number of characters or bytes to read at a time
+        
+    Raises:
+        ValueError: If the input contains a lone surrogate or a disallowed
+            control character, if a binary stream is not valid UTF-8
+            (UnicodeDecodeError), or if chunk_size is less than 1
+        
+    Examples:
+        >>> import io
+        >>> output = io.StringIO()
+        >>> capitalize_words_stream(io.StringIO("hello world"), output, 3)
+        >>> output.getvalue()
+        'Hello World'
+    """
+    if chunk_size < 1:
+        raise ValueError(f"Chunk size must be at least 1, got {chunk_size}")
+    
+    decoder = codecs.getincrementaldecoder("utf-8")()
+    binary = False
+    capitalize_next = True
+    offset = 0
+    while True:
+        chunk = reader.read(chunk_size)
+        if isinstance(chunk, bytes):
+            binary = True
+            text = decoder.decode(chunk, final=not chunk)
+        else:
+            text = chunk
+        if text:
+            _check_characters(text, offset)
+            offset += len(text)
+            result, _, capitalize_next = _DEFAULT_CAPITALIZER._capitalize_part(
+                text, capitalize_next
+            )
+            writer.write(result.encode("utf-8") if binary else result)
+        if not chunk:
+            break
+
+
+# Latin letters whose ASCII base cannot be found by Unicode decomposition.
+_ASCII_FOLDING_EXCEPTIONS = {
+    "ß": "ss", "ẞ": "SS", "æ": "ae", "Æ": "AE", "œ": "oe", "Œ": "OE",
+    "ø": "o", "Ø": "O", "đ": "d", "Đ": "D", "ł": "l", "Ł": "L",
+    "þ": "th", "Þ": "TH", "ð": "d", "Ð": "D", "ı": "i",
+}
+
+
+def _fold_accents(input_str: str) -> str:
+    """
+    Replace accented Latin letters with their unaccented base letters.
+    
+    Characters are decomposed (NFKD) and combining marks removed, with
+    _ASCII_FOLDING_EXCEPTIONS covering letters that do not decompose.
+    Characters without a decomposition are kept as they are.
+    """
+    decomposed = unicodedata.normalize("NFKD", input_str)
+    return "".join(
+        _ASCII_FOLDING_EXCEPTIONS.get(char, char)
+        for char in decomposed
+        if not unicodedata.combining(char)
+    )
+
+
+def slugify(input_str: str) -> str:
+    """
+    Convert a string into a lowercase, hyphen-separated URL slug.
+    
+    Equivalent to slugify_with_separator(input_str, "-").
+    
+    Args:
+        input_str: The text to convert, typically a title
+        
+    Returns:
+        The URL-safe slug
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> slugify("Café Crème: A Guide!")
+        'cafe-creme-a-guide'
+    """
+    return slugify_with_separator(input_str, "-")
+
+
+def slugify_with_separator(input_str: str, separator: str) -> str:
+    """
+    Convert a string into a lowercase URL slug with a custom separator.
+    
+    Accented letters are transliterated to their ASCII base letters
+    ("café" becomes "cafe") and everything is lowercased. Runs of
+    whitespace and punctuation become a single separator, any other
+    character that is not an ASCII letter or digit is removed, and the
+    separator never appears at the start or end of the slug.
+    
+    Args:
+        input_str: The text to convert, typically a title
+        separator: The string placed between words, for example "_"
+        
+    Returns:
+        The URL-safe slug
+        
+    Raises:
+        TypeError: If input or separator is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> slugify_with_separator("Hello, World", "_")
+        'hello_world'
+    """
+    _validate_input(input_str)
+    if not isinstance(separator, str):
+        raise TypeError(
+            f"Separator must be a string, got {type(separator).__name__}"
+        )
+    
+    words: List[str] = []
+    current: List[str] = []
+    for char in _fold_accents(input_str).lower():
+        if char.isascii() and char.isalnum():
+            current.append(char)
+        elif char.isspace() or unicodedata.category(char).startswith("P"):
+            if current:
+                words.append("".join(current))
+                current = []
+    if current:
+        words.append("".join(current))
+    return separator.join(words)
+
+
+# Characters that are not allowed in file names on at least one of Windows,
+# macOS and Linux.
+_FILENAME_ILLEGAL_PATTERN = re.compile('[<>:"/\\\\|?*\x00-\x1f\x7f-\x9f]')
+
+# Device names Windows reserves in every directory, with any extension.
+_WINDOWS_RESERVED_NAMES = frozenset(
+    ["CON", "PRN", "AUX", "NUL"]
+    + [f"{device}{number}" for device in ("COM", "LPT") for number in "123456789¹²³"]
+)
+
+
+@dataclass
+class FilenameOptions:
+    """
+    Settings applied by sanitize_filename.
+    
+    Attributes:
+        replacement: What each illegal character is replaced with; may be
+            empty to remove them
+        max_bytes: The maximum length of the name in UTF-8 bytes. 255 is
+            the limit of most file systems.
+        fallback: The name used when nothing is left of the input
+    """
+    
+    replacement: str = "_"
+    max_bytes: int = 255
+    fallback: str = "file"
+
+
+def _truncate_utf8(input_str: str, max_bytes: int) -> str:
+    """Return the longest prefix of whole grapheme clusters within max_bytes."""
+    size = 0
+    start = 0
+    while start < len(input_str):
+        end = _grapheme_end(input_str, start)
+        size += len(input_str[start:end].encode("utf-8"))
+        if size > max_bytes:
+            break
+        start = end
+    return input_str[:start]
+
+
+def _check_filename_options(options: FilenameOptions) -> None:
+    """Raise unless options can only produce valid file names."""
+    for name, value in (
+        ("Replacement", options.replacement),
+        ("Fallback", options.fallback),
+    ):
+        if not isinstance(value, str):
+            raise TypeError(f"{name} must be a string, got {type(value).__name__}")
+        if _FILENAME_ILLEGAL_PATTERN.search(value):
+            raise ValueError(f"{name} contains a character not allowed in file names")
+    if not options.fallback.strip(". "):
+        raise ValueError("Fallback must be a valid file name")
+    if not isinstance(options.max_bytes, int):
+        raise TypeError(
+            f"Maximum bytes must be an integer, got {type(options.max_bytes).__name__}"
+        )
+    if options.max_bytes < 1:
+        raise ValueError(f"Maximum bytes must be at least 1, got {options.max_bytes}")
+
+
+def sanitize_filename(
+    input_str: str, options: Optional[FilenameOptions] = None
+) -> str:
+    """
+    Make a string safe to use as a file name on Windows, macOS and Linux.
+    
+    The rules of all three systems are applied, so the name can be used
+    on any of them:
+    
+    - The characters < > : " / \\ | ? *, control characters and
+      bidirectional formatting characters, which can disguise an
+      extension, are each replaced with options.replacement
+    - Spaces and dots at the end, and spaces at the start, are removed
+    - The name is shortened to options.max_bytes UTF-8 bytes, never
+      splitting a grapheme cluster and keeping the extension unless it
+      would take more than half of the limit
+    - Windows device names such as CON, NUL and COM1, with or without an
+      extension, are prefixed with "_", so "con.txt" becomes "_con.txt"
+    - A name with nothing left, such as "" or "..", becomes
+      options.fallback
+    
+    The result is a single path component: separators are replaced, so
+    it cannot point into another directory.
+    
+    Args:
+        input_str: The proposed file name, without a directory
+        options: The replacement, length limit and fallback to use.
+            Defaults to FilenameOptions().
+        
+    Returns:
+        A valid file name
+        
+    Raises:
+        TypeError: If input is not a string, or the replacement, fallback
+            or max_bytes of options has the wrong type
+        ValueError: If input is too long (see MAX_STRING_LENGTH) or
+            contains NUL or a lone surrogate, or options has a replacement
+            or fallback with illegal characters or a max_bytes below 1
+        
+    Examples:
+        >>> sanitize_filename('report: "Q1/Q2"?.pdf')
+        'report_ _Q1_Q2__.pdf'
+        >>> sanitize_filename("CON.txt")
+        '_CON.txt'
+        >>> sanitize_filename("notes. . ", FilenameOptions(max_bytes=4))
+        'note'
+    """
+    input_str = _validate_input(input_str, control_policy=ControlPolicy.ALLOW)
+    if options is None:
+        options = FilenameOptions()
+    _check_filename_options(options)
+    
+    replacement = options.replacement
+    name = _FILENAME_ILLEGAL_PATTERN.sub(replacement, input_str)
+    name = _BIDI_CONTROL_PATTERN.sub(replacement, name).lstrip(" ").rstrip(". ")
+    
+    dot = name.rfind(".")
+    extension = name[dot:] if dot > 0 else ""
+    if len(extension.encode("utf-8")) * 2 > options.max_bytes:
+        extension = ""
+    stem = name[: len(name) - len(extension)]
+    room = options.max_bytes - len(extension.encode("utf-8"))
+    short_stem = _truncate_utf8(stem, room)
+    if short_stem != stem:
+        short_stem = short_stem.rstrip(" ")
+    name = (short_stem + extension).rstrip(". ")
+    if not name:
+        return _truncate_utf8(options.fallback, options.max_bytes)
+    
+    device = name.split(".", 1)[0].rstrip(" ")
+    if device.upper() in _WINDOWS_RESERVED_NAMES:
+        name = _truncate_utf8("_" + name, options.max_bytes).rstrip(". ")
+    return name
+
+
+def capitalize_words_locale(input_str: str, locale: str) -> str:
+    """
+    Capitalize the first letter of every word using locale case rules.
+    
+    Behaves like capitalize_words, but applies the special case mappings
+    of the given language: in Turkish ("tr") and Azeri ("az") the
+    uppercase of "i" is "İ" and the uppercase of "ı" is "I". In Lithuanian
+    ("lt") the explicit dot above an accented "i" or "j" is dropped when
+    uppercasing and restored when lowercasing. Locales without special
+    mappings behave exactly like capitalize_words.
+    
+    Args:
+        input_str: The string whose words to capitalize
+        locale: A language tag such as "tr", "tr-TR" or "en_US"
+        
+    Returns:
+        The string with each word's first letter in uppercase
+        
+    Raises:
+        TypeError: If input or locale is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> capitalize_words_locale("istanbul ırmak", "tr")
+        'İstanbul Irmak'
+        >>> capitalize_words_locale("istanbul", "en")
+        'Istanbul'
+        >>> capitalize_words_locale("i\u0307\u0301s", "lt") == "I\u0301s"
+        True
+    """
+    if not isinstance(locale, str):
+        raise TypeError(f"Locale must be a string, got {type(locale).__name__}")
+    
+    return Capitalizer(locale=locale).capitalize(input_str)
+
+
+def _check_truncation_arguments(max_length: int, ellipsis: str) -> None:
+    """Validate the length limit and ellipsis shared by the truncate functions."""
+    if not isinstance(ellipsis, str):
+        raise TypeError(
+            f"Ellipsis must be a string, got {type(ellipsis).__name__}"
+        )
+    if max_length < 0:
+        raise ValueError(f"Maximum length cannot be negative, got {max_length}")
+
+
+def truncate(input_str: str, max_length: int, ellipsis: str = "…") -> str:
+    """
+    Shorten a string to at most max_length characters, adding an ellipsis.
+    
+    Lengths are counted in characters (code points), never bytes. The
+    ellipsis is appended only when the string is actually shortened and
+    counts towards max_length. If max_length is too small to hold the
+    ellipsis, the first max_length characters are returned without one.
+    
+    Args:
+        input_str: The string to shorten
+        max_length: The maximum length of the result
+        ellipsis: The marker appended when the string is shortened
+        
+    Returns:
+        The original string if it fits, otherwise the shortened string
+        
+    Raises:
+        TypeError: If input or ellipsis is not a string
+        ValueError: If input fails validation or max_length is negative
+        
+    Examples:
+        >>> truncate("Hello World", 8)
+        'Hello W…'
+        >>> truncate("Hello", 5, "...")
+        'Hello'
+        >>> truncate("Hello World", 2, "...")
+        'He'
+    """
+    _validate_input(input_str)
+    _check_truncation_arguments(max_length, ellipsis)
+    
+    if len(input_str) <= max_length:
+        return input_str
+    if max_length < len(ellipsis):
+        return input_str[:max_length]
+    return input_str[: max_length - len(ellipsis)] + ellipsis
+
+
+def truncate_words(input_str: str, max_length: int, ellipsis: str = "…") -> str:
+    """
+    Shorten a string at a word boundary, adding an ellipsis.
+    
+    Like truncate, but the cut is moved back to the end of the last
+    whitespace-delimited word that fits, so no word is ever cut in half;
+    whitespace before the ellipsis is dropped. If not even the first word
+    fits, only the ellipsis is returned. If max_length is too small to
+    hold the ellipsis, an empty string is returned.
+    
+    Args:
+        input_str: The string to shorten
+        max_length: The maximum length of the result
+        ellipsis: The marker appended when the string is shortened
+        
+    Returns:
+        The original string if it fits, otherwise the shortened string
+        
+    Raises:
+        TypeError: If input or ellipsis is not a string
+        ValueError: If input fails validation or max_length is negative
+        
+    Examples:
+        >>> truncate_words("The quick brown fox", 14)
+        'The quick…'
+        >>> truncate_words("The quick brown fox", 16, "...")
+        'The quick...'
+    """
+    _validate_input(input_str)
+    _check_truncation_arguments(max_length, ellipsis)
+    
+    if len(input_str) <= max_length:
+        return input_str
+    if max_length < len(ellipsis):
+        return ""
+    
+    limit = max_length - len(ellipsis)
+    kept = ""
+    for start, end in _word_spans(input_str):
+        if end > limit:
+            break
+        kept = input_str[:end]
+    return kept + ellipsis
+
+
+def display_width(input_str: str) -> int:
+    """
+    Compute the number of terminal columns a string occupies.
+    
+    East Asian wide and fullwidth characters, such as CJK ideographs, and
+    emoji occupy two columns. Combining marks and zero-width characters
+    such as U+200B occupy none, and an emoji sequence joined with
+    zero-width joiners or skin-tone modifiers occupies two columns as a
+    whole. Tab, newline and carriage return are counted as zero; expand
+    tabs first if they matter.
+    
+    Args:
+        input_str: The string to measure
+        
+    Returns:
+        The display width in terminal columns
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> display_width("abc")
+        3
+        >>> display_width("日本語")
+        6
+        >>> display_width("e\\u0301\\u200b")
+        1
+    """
+    _validate_input(input_str)
+    
+    return _display_width(input_str)
+
+
+def truncate_to_width(input_str: str, max_width: int, ellipsis: str = "…") -> str:
+    """
+    Shorten a string to at most max_width terminal columns, adding an ellipsis.
+    
+    Like truncate, but measured in display columns (see display_width)
+    rather than characters, so text mixing Latin and CJK characters lines
+    up in a terminal or table. Whole grapheme clusters are kept or dropped,
+    so a letter never loses its marks and an emoji sequence is never split.
+    A wide character that would only half fit is dropped, so the result
+    may be one column narrower than max_width. If max_width is too small
+    to hold the ellipsis, the clusters that fit are returned without one.
+    
+    Args:
+        input_str: The string to shorten
+        max_width: The maximum display width of the result
+        ellipsis: The marker appended when the string is shortened
+        
+    Returns:
+        The original string if it fits, otherwise the shortened string
+        
+    Raises:
+        TypeError: If input or ellipsis is not a string
+        ValueError: If input fails validation or max_width is negative
+        
+    Examples:
+        >>> truncate_to_width("日本語テキスト", 7)
+        '日本語…'
+        >>> truncate_to_width("Hello World", 8, "...")
+        'Hello...'
+    """
+    _validate_input(input_str)
+    _check_truncation_arguments(max_width, ellipsis)
+    
+    if _display_width(input_str) <= max_width:
+        return input_str
+    ellipsis_width = _display_width(ellipsis)
+    if max_width < ellipsis_width:
+        ellipsis = ""
+    else:
+        max_width -= ellipsis_width
+    
+    width = 0
+    start = 0
+    while start < len(input_str):
+        end = _grapheme_end(input_str, start)
+        width += _cluster_width(input_str[start:end])
+        if width > max_width:
+            break
+        start = end
+    return input_str[:start] + ellipsis
+
+
+def truncate_bytes_safe(input_str: str, max_bytes: int, ellipsis: str = "") -> str:
+    """
+    Shorten a string to at most max_bytes bytes of UTF-8.
+    
+    The string is cut at the last grapheme cluster boundary that fits, so
+    the encoded result is always valid UTF-8 and never ends with half a
+    character, a letter without its combining marks, or part of an emoji
+    sequence. This is what byte-limited fields such as database columns,
+    HTTP headers and file names need. Unlike the other truncate
+    functions, no ellipsis is added by default; if one is given and
+    max_bytes is too small to hold it, the clusters that fit are
+    returned without one.
+    
+    Args:
+        input_str: The string to shorten
+        max_bytes: The maximum length of the result in UTF-8 bytes
+        ellipsis: The marker appended when the string is shortened.
+            Defaults to none.
+        
+    Returns:
+        The original string if it fits, otherwise the shortened string
+        
+    Raises:
+        TypeError: If input or ellipsis is not a string
+        ValueError: If input fails validation or max_bytes is negative
+        
+    Examples:
+        >>> truncate_bytes_safe("café crème", 4)
+        'caf'
+        >>> ascii(truncate_bytes_safe("ok \\U0001f44d\\U0001f3fd", 9))
+        "'ok '"
+        >>> truncate_bytes_safe("Hello World", 8, "...")
+        'Hello...'
+    """
+    _validate_input(input_str)
+    _check_truncation_arguments(max_bytes, ellipsis)
+    
+    if len(input_str.encode("utf-8")) <= max_bytes:
+        return input_str
+    ellipsis_bytes = len(ellipsis.encode("utf-8"))
+    if max_bytes < ellipsis_bytes:
+        ellipsis = ""
+    else:
+        max_bytes -= ellipsis_bytes
+    return _truncate_utf8(input_str, max_bytes) + ellipsis
+
+
+def word_count(input_str: str) -> int:
+    """
+    Count the whitespace-delimited words in a string.
+    
+    Word boundaries are the same as in capitalize_words: any run of
+    whitespace separates two words, and leading or trailing whitespace
+    does not add words.
+    
+    Args:
+        input_str: The string to count
+        
+    Returns:
+        The number of words
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> word_count("  hello \\t world  ")
+        2
+        >>> word_count("")
+        0
+    """
+    _validate_input(input_str)
+    
+    return len(_word_spans(input_str))
+
+
+@dataclass
+class WordStats:
+    """
+    Statistics about the words of a string, as returned by word_stats.
+    
+    Attributes:
+        words: The number of whitespace-delimited words
+        characters: The number of characters (code points)
+        bytes: The length of the UTF-8 encoding in bytes
+        unique_words: The number of distinct words, compared lowercased
+    """
+    
+    words: int
+    characters: int
+    bytes: int
+    unique_words: int
+
+
+def word_stats(input_str: str) -> WordStats:
+    """
+    Compute word, character, byte and unique-word counts for a string.
+    
+    Words are counted as in word_count; unique words are compared after
+    lowercasing, so "Hello" and "hello" count once.
+    
+    Args:
+        input_str: The string to analyse
+        
+    Returns:
+        A WordStats with the counts
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> word_stats("Hello hello café")
+        WordStats(words=3, characters=16, bytes=17, unique_words=2)
+    """
+    _validate_input(input_str)
+    
+    words = [input_str[start:end] for start, end in _word_spans(input_str)]
+    return WordStats(
+        words=len(words),
+        characters=len(input_str),
+        bytes=len(input_str.encode("utf-8")),
+        unique_words=len({word.lower() for word in words}),
+    )
+
+
+def reverse_words(input_str: str) -> str:
+    """
+    Reverse the order of the words in a string, keeping whitespace in place.
+    
+    Words are whitespace-delimited. The whitespace runs stay at their
+    original positions: leading and trailing whitespace stay at the ends
+    and the n-th gap between words is still the n-th gap. Characters within
+    each word keep their order; use reverse_string to reverse characters.
+    
+    Args:
+        input_str: The string whose words to reverse
+        
+    Returns:
+        The string with its words in reverse order
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> reverse_words("hello   world")
+        'world   hello'
+        >>> reverse_words(" one two\\tthree ")
+        ' three two\\tone '
+    """
+    _validate_input(input_str)
+    
+    spans = _word_spans(input_str)
+    words = [input_str[start:end] for start, end in reversed(spans)]
+    parts: List[str] = []
+    position = 0
+    for word, (start, end) in zip(words, spans):
+        parts.append(input_str[position:start])
+        parts.append(word)
+        position = end
+    parts.append(input_str[position:])
+    return "".join(parts)
+
+
+def reverse_runes(input_str: str) -> str:
+    """
+    Reverse a string code point by code point, with input validation.
+    
+    Python strings are sequences of code points, so multi-byte UTF-8
+    characters such as "é" are moved whole rather than split into bytes.
+    This is reverse_string with the same validation as reverse_words.
+    Combining marks are separate code points and end up before their
+    base letter; reverse the clusters from graphemes.iter_graphemes to
+    keep them attached.
+    
+    Args:
+        input_str: The string to reverse
+        
+    Returns:
+        The string with its code points in reverse order
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> reverse_runes("café")
+        'éfac'
+    """
+    _validate_input(input_str)
+    
+    return reverse_string(input_str)
+
+
+def swap_case(input_str: str) -> str:
+    """
+    Swap uppercase letters to lowercase and lowercase letters to uppercase.
+    
+    Each character is mapped on its own. Characters without a case, and
+    characters whose case mapping is not a single character (such as "ß",
+    whose uppercase is "SS"), are left unchanged, so the result always has
+    the same length as the input.
+    
+    Args:
+        input_str: The string to transform
+        
+    Returns:
+        The string with the case of each letter inverted
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> swap_case("Hello World")
+        'hELLO wORLD'
+        >>> swap_case("Привет Мир")
+        'пРИВЕТ мИР'
+    """
+    _validate_input(input_str)
+    
+    chars: List[str] = []
+    for char in input_str:
+        if char.isupper():
+            swapped = char.lower()
+        elif char.islower():
+            swapped = char.upper()
+        else:
+            swapped = char
+        chars.append(swapped if len(swapped) == 1 else char)
+    return "".join(chars)
+
+
+def _padding_needed(input_str: str, width: int, pad: str) -> int:
+    """
+    Validate padding arguments and return the number of pad characters needed.
+    
+    Only the type and MAX_STRING_LENGTH are checked, not control characters.
+    """
+    if not isinstance(input_str, str):
+        raise TypeError(
+            f"Input must be a string, got {type(input_str).__name__}"
+        )
+    if len(input_str) > MAX_STRING_LENGTH:
+        raise ValueError(
+            f"Input exceeds maximum length of {MAX_STRING_LENGTH} characters"
+        )
+    if not isinstance(pad, str) or len(pad) != 1:
+        raise ValueError(f"Pad must be a single character, got {pad!r}")
+    return max(width - len(input_str), 0)
+
+
+def pad_left(input_str: str, width: int, pad: str = " ") -> str:
+    """
+    Pad a string on the left to the given width in characters.
+    
+    Args:
+        input_str: The string to pad
+        width: The desired length in characters (code points)
+        pad: The single padding character
+        
+    Returns:
+        The padded string, or the input unchanged if it is already at
+        least width characters long
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input exceeds MAX_STRING_LENGTH or pad is not a
+            single character
+        
+    Examples:
+        >>> pad_left("café", 6, ".")
+        '..café'
+    """
+    return pad * _padding_needed(input_str, width, pad) + input_str
+
+
+def pad_right(input_str: str, width: int, pad: str = " ") -> str:
+    """
+    Pad a string on the right to the given width in characters.
+    
+    Args:
+        input_str: The string to pad
+        width: The desired length in characters (code points)
+        pad: The single padding character
+        
+    Returns:
+        The padded string, or the input unchanged if it is already at
+        least width characters long
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input exceeds MAX_STRING_LENGTH or pad is not a
+            single character
+        
+    Examples:
+        >>> pad_right("café", 6, ".")
+        'café..'
+    """
+    return input_str + pad * _padding_needed(input_str, width, pad)
+
+
+def center(input_str: str, width: int, pad: str = " ") -> str:
+    """
+    Pad a string on both sides to center it in the given width.
+    
+    When the padding cannot be split evenly, the extra pad character goes
+    on the right.
+    
+    Args:
+        input_str: The string to center
+        width: The desired length in characters (code points)
+        pad: The single padding character
+        
+    Returns:
+        The centered string, or the input unchanged if it is already at
+        least width characters long
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input exceeds MAX_STRING_LENGTH or pad is not a
+            single character
+        
+    Examples:
+        >>> center("café", 7, "*")
+        '*café**'
+    """
+    needed = _padding_needed(input_str, width, pad)
+    left = needed // 2
+    return pad * left + input_str + pad * (needed - left)
+
+
+def initials(input_str: str, max_letters: int = 0) -> str:
+    """
+    Build uppercase initials from the first letter of each word.
+    
+    Words are whitespace-delimited, as in capitalize_words, and leading
+    quotes and brackets are skipped. Words that start with any other
+    non-letter, such as a digit, contribute no initial.
+    
+    Args:
+        input_str: The text to abbreviate, typically a name
+        max_letters: The maximum number of initials; 0 or a negative value
+            returns all of them
+        
+    Returns:
+        The concatenated uppercase initials
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> initials("john ronald reuel tolkien", 3)
+        'JRR'
+        >>> initials("Ada Lovelace")
+        'AL'
+    """
+    _validate_input(input_str)
+    
+    letters: List[str] = []
+    for start, end in _word_spans(input_str):
+        index = start
+        while index < end and _is_leading_punctuation(input_str[index]):
+            index += 1
+        if index < end and input_str[index].isalpha():
+            letters.append(input_str[index].upper())
+            if len(letters) == max_letters:
+                break
+    return "".join(letters)
+
+
+def capitalize_words_with_delimiters_normalized(
+    input_str: str, delimiters: str, replacement: str
+) -> str:
+    """
+    Capitalize delimited words and collapse delimiter runs to a replacement.
+    
+    Words are separated by the characters in delimiters, as in
+    capitalize_words_with_delimiters. Each run of delimiters between two
+    words is replaced by a single replacement string; runs at the start or
+    end of the input are removed, so the output never starts or ends with
+    the replacement.
+    
+    Args:
+        input_str: The string whose words to capitalize
+        delimiters: The characters that separate words
+        replacement: The string written between words
+        
+    Returns:
+        The capitalized words joined by replacement
+        
+    Raises:
+        TypeError: If any argument is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> text = "--hello--world..foo."
+        >>> capitalize_words_with_delimiters_normalized(text, "-.", " ")
+        'Hello World Foo'
+    """
+    if not isinstance(replacement, str):
+        raise TypeError(
+            f"Replacement must be a string, got {type(replacement).__name__}"
+        )
+    capitalizer = Capitalizer(delimiters=delimiters)
+    capitalized = capitalizer.capitalize(input_str)
+    return replacement.join(capitalizer._words(capitalized))
+
+
+def _is_latin(char: str) -> bool:
+    """Return True for characters of the Latin script."""
+    return char.isascii() or unicodedata.name(char, "").startswith("LATIN ")
+
+
+def remove_accents(input_str: str) -> str:
+    """
+    Remove accents and other diacritics from Latin letters.
+    
+    The string is decomposed (NFD) and the combining marks that follow a
+    Latin base letter are removed, so "Crème Brûlée" becomes "Creme
+    Brulee". Marks on letters of other scripts, and text without accents
+    such as Cyrillic or CJK, are left untouched. Letters that have no
+    decomposition, such as "ø" or "ß", are also kept.
+    
+    Args:
+        input_str: The string to strip accents from
+        
+    Returns:
+        The string without diacritics on Latin letters, in NFC form
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> remove_accents("Crème Brûlée")
+        'Creme Brulee'
+        >>> remove_accents("Ёжик café")
+        'Ёжик cafe'
+    """
+    _validate_input(input_str)
+    
+    chars: List[str] = []
+    latin_base = False
+    for char in unicodedata.normalize("NFD", input_str):
+        if unicodedata.combining(char):
+            if not latin_base:
+                chars.append(char)
+            continue
+        latin_base = _is_latin(char)
+        chars.append(char)
+    return unicodedata.normalize("NFC", "".join(chars))
+
+
+# Typographic punctuation that has no compatibility decomposition to ASCII.
+_ASCII_PUNCTUATION_FOLDINGS = {
+    "\u2018": "'", "\u2019": "'", "\u201a": "'", "\u201b": "'",
+    "\u201c": '"', "\u201d": '"', "\u201e": '"', "\u201f": '"',
+    "\u00ab": '"', "\u00bb": '"', "\u2039": "'", "\u203a": "'",
+    "\u2010": "-", "\u2011": "-", "\u2012": "-", "\u2013": "-",
+    "\u2014": "-", "\u2015": "-", "\u2212": "-", "\u2044": "/",
+}
+
+
+def fold_to_ascii(
+    input_str: str, exceptions: Optional[Dict[str, str]] = None
+) -> str:
+    """
+    Replace accented letters and typographic punctuation with ASCII.
+    
+    Unlike remove_accents, which keeps letters such as "ß" and "ø" and
+    returns NFC text, this aims for plain ASCII: letters are decomposed
+    (NFKD) and their combining marks dropped, letters without a
+    decomposition are spelled out ("ß" becomes "ss", "æ" becomes "ae"),
+    compatibility characters such as ligatures and full-width letters are
+    replaced by their plain forms, and curly quotes and dashes become
+    their ASCII counterparts. Characters with no ASCII equivalent, such as
+    CJK or Cyrillic letters, are kept, without any combining marks; it is
+    a building block for slugs, search keys and file names, not a
+    transliterator.
+    
+    Args:
+        input_str: The string to fold
+        exceptions: Replacements for single characters, applied before
+            any other folding and taking precedence over it, for example
+            {"ü": "ue"} for German. Defaults to None.
+        
+    Returns:
+        The folded string
+        
+    Raises:
+        TypeError: If input is not a string or exceptions does not map
+            strings to strings
+        ValueError: If input fails validation (see MAX_STRING_LENGTH) or
+            an exception key is not a single character
+        
+    Examples:
+        >>> fold_to_ascii("Crème brûlée")
+        'Creme brulee'
+        >>> fold_to_ascii("Straße \\u201cøre\\u201d")
+        'Strasse "ore"'
+        >>> fold_to_ascii("Müller", {"ü": "ue"})
+        'Mueller'
+    """
+    _validate_input(input_str)
+    if exceptions:
+        for char, replacement in exceptions.items():
+            if not isinstance(char, str) or not isinstance(replacement, str):
+                raise TypeError(
+                    f"Exceptions must map strings to strings, got "
+                    f"{type(char).__name__} to {type(replacement).__name__}"
+                )
+            if len(char) != 1:
+                raise ValueError(
+                    f"Exception keys must be single characters, got {char!r}"
+                )
+        input_str = "".join(exceptions.get(char, char) for char in input_str)
+    
+    folded = _fold_accents(input_str)
+    return "".join(_ASCII_PUNCTUATION_FOLDINGS.get(char, char) for char in folded)
+
+
+def _wrap_line(line: str, width: int) -> List[str]:
+    """Greedily wrap a single line without newlines into lines of width."""
+    wrapped: List[str] = []
+    current = ""
+    gap = ""
+    for run in split_keep(line, str.isspace):
+        if run[0].isspace():
+            gap = run
+            continue
+        if not current:
+            current = (gap if not wrapped else "") + run
+        elif len(current) + len(gap) + len(run) <= width:
+            current += gap + run
+        else:
+            wrapped.append(current)
+            current = run
+        gap = ""
+    if len(current) + len(gap) <= width:
+        current += gap
+    wrapped.append(current)
+    return wrapped
+
+
+def wrap_text(input_str: str, width: int) -> str:
+    """
+    Wrap text so that no line is longer than width characters.
+    
+    Lines are broken only at whitespace: the whitespace run at each break
+    is replaced by a newline, and a word longer than width is placed on a
+    line of its own without being split. Existing line breaks are kept as
+    hard breaks, along with their original line endings. Whitespace inside
+    a line, including indentation, is otherwise preserved. Widths are
+    measured in characters (code points), not bytes.
+    
+    Args:
+        input_str: The text to wrap
+        width: The maximum line length in characters
+        
+    Returns:
+        The wrapped text
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation or width is less than 1
+        
+    Examples:
+        >>> print(wrap_text("the quick brown fox jumps", 10))
+        the quick
+        brown fox
+        jumps
+    """
+    _validate_input(input_str)
+    if width < 1:
+        raise ValueError(f"Width must be at least 1, got {width}")
+    
+    parts: List[str] = []
+    for line in input_str.splitlines(keepends=True):
+        content = line.rstrip("\r\n")
+        parts.append("\n".join(_wrap_line(content, width)))
+        parts.append(line[len(content) :])
+    return "".join(parts)
+
+
+def _uncapitalize_first_letter(word: str) -> str:
+    """
+    Lowercase the first letter of a word, skipping leading quotes and brackets.
+    
+    A word whose first character after any leading punctuation is not a
+    letter is returned unchanged.
+    """
+    index = 0
+    while index < len(word) and _is_leading_punctuation(word[index]):
+        index += 1
+    if index < len(word) and word[index].isalpha():
+        return word[:index] + word[index].lower() + word[index + 1 :]
+    return word
+
+
+def uncapitalize(input_str: str) -> str:
+    """
+    Lowercase the first letter of every whitespace-delimited word.
+    
+    The inverse of capitalize_words: only the first letter of each word is
+    changed, leading quotes and brackets are skipped, and words starting
+    with any other non-letter are left unchanged. Whitespace and the rest
+    of each word are preserved.
+    
+    Args:
+        input_str: The string whose words to uncapitalize
+        
+    Returns:
+        The string with each word's first letter in lowercase
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> uncapitalize("HELLO World")
+        'hELLO world'
+    """
+    _validate_input(input_str)
+    
+    return _WHITESPACE_WORD.sub(
+        lambda match: _uncapitalize_first_letter(match.group(0)), input_str
+    )
+
+
+def uncapitalize_first(input_str: str) -> str:
+    """
+    Lowercase only the first letter of the first word of a string.
+    
+    Leading whitespace, quotes and brackets are skipped; if the first word
+    starts with any other non-letter the string is returned unchanged.
+    
+    Args:
+        input_str: The string to uncapitalize
+        
+    Returns:
+        The string with its first letter in lowercase
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> uncapitalize_first("HELLO World")
+        'hELLO World'
+    """
+    _validate_input(input_str)
+    
+    return _WHITESPACE_WORD.sub(
+        lambda match: _uncapitalize_first_letter(match.group(0)), input_str, count=1
+    )
+
+
+_STRICT_CAPITALIZER = Capitalizer(lowercase_rest=True)
+
+
+def capitalize_words_strict(input_str: str) -> str:
+    """
+    Capitalize each word's first letter and lowercase the rest of the word.
+    
+    Unlike capitalize_words, which only touches the first letter, this
+    normalizes mixed case into true title case. Whitespace, digits and
+    punctuation are preserved; a word that starts with a non-letter is
+    lowercased without gaining a capital.
+    
+    Args:
+        input_str: The string to normalize
+        
+    Returns:
+        The string with each word in title case
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> capitalize_words_strict("HeLLo WoRLd")
+        'Hello World'
+        >>> capitalize_words_strict("'TWAS 3D")
+        "'Twas 3d"
+    """
+    return _STRICT_CAPITALIZER.capitalize(input_str)
+
+
+def word_frequencies(input_str: str) -> Dict[str, int]:
+    """
+    Count how often each lowercased word occurs.
+    
+    Words are whitespace-delimited, as in capitalize_words. Punctuation
+    attached to a word is kept; use word_frequencies_func to canonicalize
+    tokens differently.
+    
+    Args:
+        input_str: The text to analyse
+        
+    Returns:
+        A new dictionary mapping each lowercased word to its count; empty
+        if the input has no words
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> word_frequencies("The cat and the hat")
+        {'the': 2, 'cat': 1, 'and': 1, 'hat': 1}
+    """
+    return word_frequencies_func(input_str, str.lower)
+
+
+def word_frequencies_func(
+    input_str: str, normalize: Callable[[str], str]
+) -> Dict[str, int]:
+    """
+    Count word occurrences after canonicalizing each word with a function.
+    
+    Each whitespace-delimited word is passed to normalize and the results
+    are counted; words that normalize to an empty string are skipped.
+    
+    Args:
+        input_str: The text to analyse
+        normalize: Function mapping a raw word to its canonical form
+        
+    Returns:
+        A new dictionary mapping each canonical word to its count, in order
+        of first occurrence
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> word_frequencies_func("Hi, hi! HI", lambda w: w.strip(",!").lower())
+        {'hi': 3}
+    """
+    _validate_input(input_str)
+    
+    counts: Dict[str, int] = {}
+    for word in _WHITESPACE_WORD.findall(input_str):
+        key = normalize(word)
+        if key:
+            counts[key] = counts.get(key, 0) + 1
+    return counts
+
+
+# The end of a sentence: terminators followed by any closing quotes and
+# brackets, then whitespace or the end of the text. Chinese and Japanese
+# terminators need no whitespace after them.
+_SENTENCE_END = re.compile(
+    "[.!?\u2026]+[\"'\u2019\u201d\u00bb)\\]]*(?=\\s|$)"
+    "|[\u3002\uff01\uff1f]+[\u300d\u300f\uff09]*"
+)
+
+# Abbreviations that end in a period without ending a sentence, written
+# without the final period.
+DEFAULT_ABBREVIATIONS = frozenset(
+    (
+        "mr", "mrs", "ms", "dr", "prof", "sr", "jr", "st",
+        "e.g", "i.e", "cf", "vs", "approx", "fig", "inc", "ltd", "co",
+    )
+)
+
+
+def _ends_with_abbreviation(text: str, end: int, abbreviations: FrozenSet[str]) -> bool:
+    """Return True if the word ending at text[end - 1] is a known abbreviation."""
+    if text[end - 1] != ".":
+        return False
+    start = end
+    while start > 0 and not text[start - 1].isspace():
+        start -= 1
+    return _strip_punctuation(text[start:end]).casefold() in abbreviations
+
+
+def _sentence_spans(
+    text: str, abbreviations: FrozenSet[str]
+) -> List[Tuple[int, int]]:
+    """Return the start and end index of each sentence, without whitespace."""
+    ends = [
+        match.end()
+        for match in _SENTENCE_END.finditer(text)
+        if not _ends_with_abbreviation(text, match.end(), abbreviations)
+    ]
+    spans: List[Tuple[int, int]] = []
+    start = 0
+    for end in [*ends, len(text)]:
+        while start < end and text[start].isspace():
+            start += 1
+        stop = end
+        while stop > start and text[stop - 1].isspace():
+            stop -= 1
+        if start < stop:
+            spans.append((start, stop))
+        start = end
+    return spans
+
+
+def sentence_case(input_str: str) -> str:
+    """
+    Capitalize the first letter of each sentence and lowercase the rest.
+    
+    Sentences are found as in split_sentences: a sentence ends at '.',
+    '!' or '?' and any closing quotes followed by whitespace, except after
+    one of DEFAULT_ABBREVIATIONS such as "Dr." or "e.g.". A period inside
+    a number, as in "1.5", never ends a sentence. Whitespace and
+    terminators are preserved.
+    
+    Args:
+        input_str: The string to convert
+        
+    Returns:
+        The string in sentence case
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> sentence_case("hello world. goodbye WORLD!")
+        'Hello world. Goodbye world!'
+        >>> sentence_case("WHAT?  no way")
+        'What?  No way'
+        >>> sentence_case("ask dr. smith, e.g. today. it costs 1.5 euros.")
+        'Ask dr. smith, e.g. today. It costs 1.5 euros.'
+    """
+    return sentence_case_with_abbreviations(input_str, DEFAULT_ABBREVIATIONS)
+
+
+def sentence_case_with_abbreviations(
+    input_str: str, abbreviations: Iterable[str]
+) -> str:
+    """
+    Convert a string to sentence case using a custom list of abbreviations.
+    
+    A period after one of the abbreviations does not end a sentence.
+    Abbreviations are matched case-insensitively, with or without their
+    final period, ignoring punctuation around the word.
+    
+    Args:
+        input_str: The string to convert
+        abbreviations: Words such as "Dr." or "approx" that do not end a
+            sentence when followed by a period
+        
+    Returns:
+        The string in sentence case
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> sentence_case_with_abbreviations("see fig. 2. then stop.", ["Fig."])
+        'See fig. 2. Then stop.'
+        >>> sentence_case_with_abbreviations("see fig. two", [])
+        'See fig. Two'
+    """
+    _validate_input(input_str)
+    
+    known = frozenset(_strip_punctuation(word).casefold() for word in abbreviations)
+    lowered = input_str.lower()
+    parts: List[str] = []
+    position = 0
+    for start, end in _sentence_spans(lowered, known):
+        parts.append(lowered[position:start])
+        parts.append(_capitalize_first(lowered[start:end]))
+        position = end
+    parts.append(lowered[position:])
+    return "".join(parts)
+
+
+@dataclass
+class Sentence:
+    """
+    A sentence located in a string, as returned by split_sentences.
+    
+    Attributes:
+        text: The sentence, including its terminator and closing quotes
+        start: Index of the sentence's first character in the original
+            string
+        end: Index just past the sentence's last character, so that
+            text == original[start:end]
+    """
+    
+    text: str
+    start: int
+    end: int
+
+
+def split_sentences(
+    input_str: str, abbreviations: Iterable[str] = DEFAULT_ABBREVIATIONS
+) -> List[Sentence]:
+    """
+    Split a string into sentences, keeping their positions.
+    
+    A sentence ends at a run of '.', '!', '?' or '\u2026' followed by
+    whitespace or the end of the text. Closing quotes and brackets right
+    after the terminator belong to the sentence, so 'He said "Stop." Then
+    he left.' is two sentences. A period after one of the abbreviations
+    does not end a sentence, and a period inside a number, as in "1.5",
+    is not followed by whitespace and never does. The Chinese and Japanese
+    full stop, exclamation mark and question mark end a sentence without
+    whitespace. Text after the last terminator is a final sentence.
+    
+    Args:
+        input_str: The text to split
+        abbreviations: Words such as "Dr." or "approx" that do not end a
+            sentence when followed by a period, matched case-insensitively.
+            Defaults to DEFAULT_ABBREVIATIONS.
+        
+    Returns:
+        The sentences in order, without the whitespace between them
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> [s.text for s in split_sentences("Ask Dr. Smith. It costs 1.5 euros!")]
+        ['Ask Dr. Smith.', 'It costs 1.5 euros!']
+        >>> split_sentences('He said "Stop."  Then left')[1]
+        Sentence(text='Then left', start=17, end=26)
+    """
+    _validate_input(input_str)
+    
+    known = frozenset(_strip_punctuation(word).casefold() for word in abbreviations)
+    return [
+        Sentence(text=input_str[start:end], start=start, end=end)
+        for start, end in _sentence_spans(input_str, known)
+    ]
+
+
+@dataclass
+class Token:
+    """
+    A word located in a string, as returned by tokenize.
+    
+    tokenize_bytes returns the same tokens with start and end counted in
+    bytes of the UTF-8 encoding instead, so that
+    text == original.encode()[start:end].decode().
+    
+    Attributes:
+        text: The word itself
+        start: Index of the word's first character in the original string
+        end: Index just past the word's last character, so that
+            text == original[start:end]
+    """
+    
+    text: str
+    start: int
+    end: int
+
+
+def tokenize(input_str: str) -> List[Token]:
+    """
+    Split a string into the words that capitalize_words operates on.
+    
+    Words are maximal runs of non-whitespace characters. Whitespace is not
+    returned, but the offsets are exact, so the original string can be
+    rebuilt from the tokens and the gaps between them. Offsets are string
+    indices (code points); use tokenize_bytes for UTF-8 byte offsets.
+    
+    Args:
+        input_str: The string to tokenize
+        
+    Returns:
+        A list of Token objects in order of appearance
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> tokenize("hi  there")
+        [Token(text='hi', start=0, end=2), Token(text='there', start=4, end=9)]
+    """
+    _validate_input(input_str)
+    
+    return [
+        Token(input_str[start:end], start, end)
+        for start, end in _word_spans(input_str)
+    ]
+
+
+def tokenize_bytes(input_str: str) -> List[Token]:
+    """
+    Split a string into words, with offsets in bytes of its UTF-8 encoding.
+    
+    Words are the same as in tokenize. Byte offsets locate words in UTF-8
+    buffers, files and protocols that count bytes, such as editor and
+    language server ranges, where string indices would drift after the
+    first non-ASCII character.
+    
+    Args:
+        input_str: The string to tokenize
+        
+    Returns:
+        A list of Token objects in order of appearance, whose start and
+        end are byte offsets into input_str.encode("utf-8")
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> tokenize_bytes("café au")
+        [Token(text='café', start=0, end=5), Token(text='au', start=6, end=8)]
+    """
+    _validate_input(input_str)
+    
+    tokens: List[Token] = []
+    position = offset = 0
+    for start, end in _word_spans(input_str):
+        offset += len(input_str[position:start].encode("utf-8"))
+        word = input_str[start:end]
+        tokens.append(Token(word, offset, offset + len(word.encode("utf-8"))))
+        offset = tokens[-1].end
+        position = end
+    return tokens
+
+
+def capitalize_words_allowing(input_str: str, allowed_controls: Iterable[str]) -> str:
+    """
+    Capitalize words like capitalize_words, accepting extra control characters.
+    
+    By default, control characters other than tab, newline and carriage
+    return are rejected. This accepts the given control characters as
+    well, for data that legitimately contains e.g. form feeds or vertical
+    tabs. NUL and lone surrogates are always rejected. Allowed characters
+    that are whitespace, such as "\\f" and "\\v", separate words.
+    
+    Args:
+        input_str: The string whose words to capitalize
+        allowed_controls: Control characters to accept, e.g. "\\f\\v"
+        
+    Returns:
+        The string with each word's first letter in uppercase
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If allowed_controls contains NUL or a non-control
+            character, or if input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> capitalize_words_allowing("page one\\fpage two", "\\f")
+        'Page One\\x0cPage Two'
+    """
+    return Capitalizer(allowed_controls=allowed_controls).capitalize(input_str)
+
+
+def common_prefix(inputs: Sequence[str]) -> str:
+    """
+    Find the longest string that every input starts with.
+    
+    Args:
+        inputs: The strings to compare
+        
+    Returns:
+        The longest common prefix; an empty string if there are no inputs
+        or they share no prefix
+        
+    Raises:
+        TypeError: If any input is not a string
+        ValueError: If any input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> common_prefix(["interstellar", "internet", "interval"])
+        'inter'
+        >>> common_prefix(["apple", "banana"])
+        ''
+    """
+    for input_str in inputs:
+        _validate_input(input_str)
+    
+    if not inputs:
+        return ""
+    # The lexicographically smallest and largest strings differ earliest,
+    # so their common prefix is shared by every string in between.
+    first, last = min(inputs), max(inputs)
+    length = 0
+    for left, right in zip(first, last):
+        if left != right:
+            break
+        length += 1
+    return first[:length]
+
+
+def common_suffix(inputs: Sequence[str]) -> str:
+    """
+    Find the longest string that every input ends with.
+    
+    Args:
+        inputs: The strings to compare
+        
+    Returns:
+        The longest common suffix; an empty string if there are no inputs
+        or they share no suffix
+        
+    Raises:
+        TypeError: If any input is not a string
+        ValueError: If any input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> common_suffix(["running", "jumping", "swimming"])
+        'ing'
+    """
+    for input_str in inputs:
+        _validate_input(input_str)
+    
+    return common_prefix([input_str[::-1] for input_str in inputs])[::-1]
+
+
+def strip_control_characters(input_str: str) -> str:
+    """
+    Remove the control characters that the validating functions reject.
+    
+    Tab, newline and carriage return are kept. The result always passes
+    validation, so it can be passed straight to capitalize_words.
+    
+    Args:
+        input_str: The string to sanitize
+        
+    Returns:
+        The string without disallowed control characters
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input exceeds MAX_STRING_LENGTH or contains a lone
+            surrogate
+        
+    Examples:
+        >>> strip_control_characters("bell\\x07 and\\ttab")
+        'bell and\\ttab'
+    """
+    return strip_control_characters_count(input_str)[0]
+
+
+def strip_control_characters_count(input_str: str) -> Tuple[str, int]:
+    """
+    Remove disallowed control characters and report how many were removed.
+    
+    Args:
+        input_str: The string to sanitize
+        
+    Returns:
+        A tuple of the sanitized string (see strip_control_characters) and
+        the number of characters removed
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input exceeds MAX_STRING_LENGTH or contains a lone
+            surrogate
+        
+    Examples:
+        >>> strip_control_characters_count("\\x00a\\x1bb\\n")
+        ('ab\\n', 2)
+    """
+    stripped = _validate_input(input_str, control_policy=ControlPolicy.STRIP)
+    
+    return stripped, len(input_str) - len(stripped)
+
+
+# Surname particles kept lowercase by capitalize_names.
+DEFAULT_NAME_PARTICLES = frozenset(
+    (
+        "da", "das", "de", "degli", "del", "della", "der", "di", "dos", "du",
+        "la", "le", "ten", "ter", "van", "von", "zu",
+    )
+)
+
+
+@dataclass
+class NameCaseOptions:
+    """
+    Surname rules applied by capitalize_names.
+    
+    Each prefix rule capitalizes the letter after a prefix. The rules also
+    match ordinary words ("macaroni" becomes "MacAroni"), so disable the
+    ones that produce false positives for your data.
+    
+    Attributes:
+        mc: Capitalize after "Mc", as in "McDonald"
+        mac: Capitalize after "Mac" when at least two letters follow, as
+            in "MacLeod" (but not "Mack")
+        o_apostrophe: Capitalize after "O'" or "O’", as in "O'Brien"
+        particles: Words such as "van" and "der" that are lowercased rather
+            than capitalized, matched case-insensitively
+    """
+    
+    mc: bool = True
+    mac: bool = True
+    o_apostrophe: bool = True
+    particles: Iterable[str] = DEFAULT_NAME_PARTICLES
+
+
+def _capitalize_name(word: str, options: NameCaseOptions) -> str:
+    """Capitalize a word, then the letter after any enabled surname prefix."""
+    word = _capitalize_first(word)
+    start = 0
+    while start < len(word) and _is_leading_punctuation(word[start]):
+        start += 1
+    rules = (
+        (options.mc, ("mc",), 1),
+        (options.mac, ("mac",), 2),
+        (options.o_apostrophe, ("o'", "o’"), 1),
+    )
+    lowered = word[start:].lower()
+    for enabled, prefixes, letters_after in rules:
+        if not enabled:
+            continue
+        for prefix in prefixes:
+            split = start + len(prefix)
+            following = word[split : split + letters_after]
+            if (
+                lowered.startswith(prefix)
+                and len(following) == letters_after
+                and following.isalpha()
+            ):
+                return word[:split] + word[split].upper() + word[split + 1 :]
+    return word
+
+
+def capitalize_names(
+    input_str: str, options: Optional[NameCaseOptions] = None
+) -> str:
+    """
+    Capitalize words as personal names, including Mc, Mac and O' prefixes.
+    
+    Each word is capitalized as by capitalize_words, and then the letter
+    following a recognized surname prefix is capitalized too, so
+    "mcdonald" becomes "McDonald". The rest of each word is unchanged.
+    Surname particles such as "van" and "der" are lowercased instead,
+    unless they are the last word, so "VAN DER berg" becomes
+    "van der Berg".
+    
+    Args:
+        input_str: The names to capitalize
+        options: The rules to apply. Defaults to NameCaseOptions(), which
+            enables every prefix rule and uses DEFAULT_NAME_PARTICLES.
+        
+    Returns:
+        The string with each name capitalized
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> capitalize_names("mary o'brien and angus macleod")
+        "Mary O'Brien And Angus MacLeod"
+        >>> capitalize_names("macaroni", NameCaseOptions(mac=False))
+        'Macaroni'
+        >>> capitalize_names("ludwig van beethoven")
+        'Ludwig van Beethoven'
+    """
+    _validate_input(input_str)
+    
+    if options is None:
+        options = NameCaseOptions()
+    particles = frozenset(word.casefold() for word in options.particles)
+    spans = _word_spans(input_str)
+    parts: List[str] = []
+    position = 0
+    for index, (start, end) in enumerate(spans):
+        word = input_str[start:end]
+        parts.append(input_str[position:start])
+        if index < len(spans) - 1 and _strip_punctuation(word).casefold() in particles:
+            parts.append(word.lower())
+        else:
+            parts.append(_capitalize_name(word, options))
+        position = end
+    parts.append(input_str[position:])
+    return "".join(parts)
+
+
+def capitalize_words_skipping(input_str: str, skip_words: Iterable[str]) -> str:
+    """
+    Capitalize words like capitalize_words, leaving some words untouched.
+    
+    Words in skip_words are never capitalized unless they are the first
+    word, which suits name particles and product names: with "van" and
+    "de" skipped, "ludwig van beethoven" becomes "Ludwig van Beethoven".
+    Skip words are matched case-insensitively, ignoring punctuation
+    around the word, and are kept exactly as written.
+    
+    Args:
+        input_str: The string whose words to capitalize
+        skip_words: The words to leave unchanged
+        
+    Returns:
+        The string with every other word's first letter in uppercase
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> capitalize_words_skipping("ludwig van beethoven", ["van"])
+        'Ludwig van Beethoven'
+        >>> capitalize_words_skipping("of mice and men", ["of", "and"])
+        'Of Mice and Men'
+    """
+    return Capitalizer(skip_words=skip_words).capitalize(input_str)
+
+
+def capitalize_words_with_acronyms(input_str: str, acronyms: Iterable[str]) -> str:
+    """
+    Capitalize words like capitalize_words, forcing the spelling of some words.
+    
+    Words matching an entry in acronyms, case-insensitively and ignoring
+    punctuation around the word, are replaced by that entry, so acronyms
+    and brand names come out right whatever the input casing.
+    
+    Args:
+        input_str: The string whose words to capitalize
+        acronyms: Acronyms and brand names in their correct spelling, such
+            as "NASA", "iOS" or "eBay"
+        
+    Returns:
+        The capitalized string
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> brands = ["NASA", "iOS", "eBay"]
+        >>> capitalize_words_with_acronyms("nasa buys ios devices on ebay.", brands)
+        'NASA Buys iOS Devices On eBay.'
+    """
+    return Capitalizer(acronyms=acronyms).capitalize(input_str)
+
+
+def to_upper_full(input_str: str, locale: Optional[str] = None) -> str:
+    """
+    Uppercase a string using full Unicode case mappings.
+    
+    Unlike swap_case, which keeps a 1:1 character mapping, one character
+    may expand to several: "ß" becomes "SS" and "ﬁ" becomes "FI", so the
+    result can be longer than the input.
+    
+    Args:
+        input_str: The string to uppercase
+        locale: A language tag such as "tr" or "lt" selecting special case
+            mappings, as in capitalize_words_locale. Defaults to None.
+        
+    Returns:
+        The uppercased string
+        
+    Raises:
+        TypeError: If input or locale is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> to_upper_full("straße ﬁne")
+        'STRASSE FINE'
+        >>> to_upper_full("istanbul", "tr")
+        'İSTANBUL'
+    """
+    language = _locale_language(locale)
+    _validate_input(input_str)
+    
+    return _upper(input_str, language)
+
+
+def to_lower_full(input_str: str, locale: Optional[str] = None) -> str:
+    """
+    Lowercase a string using full Unicode case mappings.
+    
+    Context-dependent mappings are applied: a capital sigma at the end of
+    a word becomes the final form "ς", and "İ" becomes "i" followed by a
+    combining dot above unless the locale is Turkish or Azeri.
+    
+    Args:
+        input_str: The string to lowercase
+        locale: A language tag such as "tr" or "lt" selecting special case
+            mappings, as in capitalize_words_locale. Defaults to None.
+        
+    Returns:
+        The lowercased string
+        
+    Raises:
+        TypeError: If input or locale is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> to_lower_full("ΟΔΟΣ")
+        'οδος'
+        >>> to_lower_full("DİYARBAKIR", "tr")
+        'diyarbakır'
+    """
+    language = _locale_language(locale)
+    _validate_input(input_str)
+    
+    return _lower(input_str, language)
+
+
+def uc_first(input_str: str) -> str:
+    """
+    Titlecase the first user-perceived character of a string.
+    
+    The first extended grapheme cluster is changed as a whole, so a letter
+    followed by combining marks keeps its marks, and a flag or other emoji
+    sequence is never split. Nothing is skipped: if the string starts with
+    whitespace, punctuation or a digit it is returned unchanged. The
+    titlecase form is used, so "ǆ" becomes "ǅ" and "ﬁ" becomes "Fi".
+    
+    Args:
+        input_str: The string to change
+        
+    Returns:
+        The string with its first character titlecased
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> uc_first("hello World")
+        'Hello World'
+        >>> uc_first("e\\u0301cole") == "E\\u0301cole"
+        True
+    """
+    _validate_input(input_str)
+    
+    end = _grapheme_end(input_str, 0)
+    return _title(input_str[:end]) + input_str[end:]
+
+
+def lc_first(input_str: str) -> str:
+    """
+    Lowercase the first user-perceived character of a string.
+    
+    The counterpart of uc_first: the first extended grapheme cluster is
+    lowercased as a whole and the rest of the string is unchanged.
+    
+    Args:
+        input_str: The string to change
+        
+    Returns:
+        The string with its first character lowercased
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> lc_first("HELLO World")
+        'hELLO World'
+    """
+    _validate_input(input_str)
+    
+    end = _grapheme_end(input_str, 0)
+    return input_str[:end].lower() + input_str[end:]
+
+
+# First words of Unicode character names that identify a script. Letters
+# and marks named otherwise ("MATHEMATICAL BOLD CAPITAL A", "MODIFIER
+# LETTER ...") belong to no single script.
+_SCRIPT_NAME_PREFIXES = frozenset(
+    (
+        "ARABIC", "ARMENIAN", "BENGALI", "BOPOMOFO", "CHEROKEE", "COPTIC",
+        "CYRILLIC", "DEVANAGARI", "ETHIOPIC", "GEORGIAN", "GREEK", "GUJARATI",
+        "GURMUKHI", "HANGUL", "HEBREW", "HIRAGANA", "KANNADA", "KATAKANA",
+        "KHMER", "LAO", "LATIN", "MALAYALAM", "MONGOLIAN", "MYANMAR", "ORIYA",
+        "SINHALA", "SYRIAC", "TAMIL", "TELUGU", "THAANA", "THAI", "TIBETAN",
+        "YI",
+    )
+)
+
+# Scripts that are routinely written together and do not count as mixing
+# (the Japanese, Korean and Chinese-with-Bopomofo writing systems).
+_SCRIPT_COMBINATIONS = (
+    frozenset(("Han", "Hiragana", "Katakana")),
+    frozenset(("Han", "Hangul")),
+    frozenset(("Han", "Bopomofo")),
+)
+
+
+def _char_script(char: str) -> str:
+    """
+    Return the script of a character, such as "Latin" or "Han".
+    
+    Characters other than letters and marks, such as digits, punctuation
+    and symbols, are "Common". Marks with no script of their own, such as
+    combining accents, are "Inherited" and take the script of the
+    character they follow.
+    """
+    category = unicodedata.category(char)
+    if category[0] not in "LM":
+        return "Common"
+    words = unicodedata.name(char, "").split()
+    if words and words[0] in ("FULLWIDTH", "HALFWIDTH"):
+        words = words[1:]
+    if words and words[0] == "CJK":
+        return "Han"
+    if words and words[0] in _SCRIPT_NAME_PREFIXES:
+        return words[0].title()
+    return "Inherited" if category[0] == "M" else "Common"
+
+
+@dataclass
+class ScriptReport:
+    """
+    The scripts found in a string, as returned by detect_scripts.
+    
+    Attributes:
+        scripts: The percentage of non-whitespace characters in each
+            script, largest first; digits, punctuation and symbols are
+            counted as "Common"
+        mixed_script: Whether letters from more than one script appear,
+            other than combinations that are written together, such as
+            Han with Hiragana and Katakana in Japanese
+    """
+    
+    scripts: Dict[str, float]
+    mixed_script: bool
+
+
+def detect_scripts(input_str: str) -> ScriptReport:
+    """
+    Report which Unicode scripts a string is written in.
+    
+    Scripts are identified from character names, covering the major
+    modern scripts: Latin, Greek, Cyrillic, Armenian, Hebrew, Arabic, the
+    Indic scripts, Thai, Georgian, Han, Hiragana, Katakana, Hangul and
+    others. Combining marks count towards the script of the letter they
+    follow. Letters of more than one script in the same string, such as
+    a Cyrillic "а" in an otherwise Latin "pаypal", set mixed_script, which
+    is a common sign of spoofing.
+    
+    Args:
+        input_str: The string to analyze
+        
+    Returns:
+        A ScriptReport with the share of each script and the mixed-script
+        flag; an empty or all-whitespace string has no scripts
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> detect_scripts("Hello, мир!").scripts
+        {'Latin': 50.0, 'Cyrillic': 30.0, 'Common': 20.0}
+        >>> detect_scripts("東京タワー").mixed_script
+        False
+    """
+    _validate_input(input_str)
+    
+    counts: Dict[str, int] = {}
+    previous = "Common"
+    for char in input_str:
+        if char.isspace():
+            previous = "Common"
+            continue
+        script = _char_script(char)
+        if script == "Inherited":
+            script = previous
+        counts[script] = counts.get(script, 0) + 1
+        previous = script
+    
+    total = sum(counts.values())
+    ranked = sorted(counts.items(), key=lambda item: (-item[1], item[0]))
+    scripts = {script: count * 100 / total for script, count in ranked}
+    letters = set(scripts) - {"Common"}
+    mixed = len(letters) > 1 and not any(
+        letters <= combination for combination in _SCRIPT_COMBINATIONS
+    )
+    return ScriptReport(scripts, mixed)
+
+
+# A subset of the Unicode confusables data (UTS #39): characters that look
+# like others, mapped to the prototype they are drawn like. It covers the
+# Cyrillic and Greek lookalikes of Latin letters, digit and letter
+# confusions, and typographic punctuation. As in the full data, the
+# prototype of "I", "1" and "|" is "l", the prototype of "0" is "O", and
+# "m" is drawn like "rn".
+_CONFUSABLES = {
+    # Cyrillic
+    "\u0430": "a", "\u0435": "e", "\u043e": "o", "\u0440": "p", "\u0441": "c",
+    "\u0443": "y", "\u0445": "x", "\u0455": "s", "\u0456": "i", "\u0458": "j",
+    "\u0501": "d", "\u04bb": "h", "\u051b": "q", "\u051d": "w", "\u04cf": "l",
+    "\u04af": "y", "\u0410": "A", "\u0412": "B", "\u0415": "E", "\u041a": "K",
+    "\u041c": "M", "\u041d": "H", "\u041e": "O", "\u0420": "P", "\u0421": "C",
+    "\u0422": "T", "\u0425": "X", "\u0423": "Y", "\u0405": "S", "\u0406": "l",
+    "\u0408": "J", "\u051a": "Q", "\u051c": "W",
+    # Greek
+    "\u03b1": "a", "\u03bf": "o", "\u03bd": "v", "\u03c1": "p", "\u03b9": "i",
+    "\u0391": "A", "\u0392": "B", "\u0395": "E", "\u0396": "Z", "\u0397": "H",
+    "\u0399": "l", "\u039a": "K", "\u039c": "M", "\u039d": "N", "\u039f": "O",
+    "\u03a1": "P", "\u03a4": "T", "\u03a5": "Y", "\u03a7": "X",
+    # Latin, digits and punctuation
+    "I": "l", "1": "l", "|": "l", "\u01c0": "l", "0": "O", "m": "rn",
+    "\u0131": "i", "\u0251": "a", "\u0261": "g", "\u2010": "-", "\u2011": "-",
+    "\u2012": "-", "\u2013": "-", "\u2212": "-", "\u2018": "'", "\u2019": "'",
+    "\u02bc": "'", "\u201c": '"', "\u201d": '"',
+}
+
+# Compatibility decompositions that only change the style of a character,
+# such as fullwidth or mathematical bold letters, which are confusable with
+# the plain character.
+_STYLE_DECOMPOSITIONS = frozenset(("<font>", "<wide>", "<narrow>"))
+
+
+def _confusable_prototype(char: str) -> str:
+    """Return the prototype a character is confusable with, or the character."""
+    if char in _CONFUSABLES:
+        return _CONFUSABLES[char]
+    tag, _, code_points = unicodedata.decomposition(char).partition(" ")
+    if tag in _STYLE_DECOMPOSITIONS:
+        return "".join(chr(int(point, 16)) for point in code_points.split())
+    return char
+
+
+def skeleton(input_str: str) -> str:
+    """
+    Compute the confusable skeleton of a string, as defined by UTS #39.
+    
+    Two strings that look alike, such as "paypal" and "pаypal" with a
+    Cyrillic "а", have the same skeleton. The string is decomposed (NFD),
+    default-ignorable characters such as zero-width joiners, variation
+    selectors and tag characters are removed, each remaining character is
+    replaced by the prototype it is visually confusable with, and the
+    result is decomposed again. The skeleton is meant for
+    comparison only, not for display, and is case-sensitive: "PayPal" and
+    "paypal" have different skeletons.
+    
+    The mappings are a subset of the Unicode confusables data: Cyrillic
+    and Greek letters that look like Latin ones, fullwidth and
+    mathematical letter styles, the digit and letter confusions "0"/"O"
+    and "1"/"l"/"I", "rn" for "m", and typographic dashes and quotes.
+    
+    Args:
+        input_str: The string to reduce
+        
+    Returns:
+        The skeleton of the string
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> skeleton("p\\u0430yp\\u0430l")
+        'paypal'
+        >>> skeleton("G00GLE")
+        'GOOGLE'
+    """
+    _validate_input(input_str)
+    
+    decomposed = unicodedata.normalize("NFD", input_str)
+    visible = _DEFAULT_IGNORABLE.sub("", decomposed)
+    prototypes = "".join(_confusable_prototype(char) for char in visible)
+    return unicodedata.normalize("NFD", prototypes)
+
+
+def are_confusable(first: str, second: str) -> bool:
+    """
+    Check whether two strings could be mistaken for each other.
+    
+    The strings are confusable when their skeletons are equal (see
+    skeleton); identical strings are trivially confusable. Use this to
+    vet new usernames or domain labels against existing ones.
+    
+    Args:
+        first: The first string to compare
+        second: The second string to compare
+        
+    Returns:
+        True if the strings have the same skeleton
+        
+    Raises:
+        TypeError: If either input is not a string
+        ValueError: If either input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> are_confusable("paypal", "p\\u0430yp\\u0430l")
+        True
+        >>> are_confusable("modern", "rnodern")
+        True
+        >>> are_confusable("paypal", "PayPal")
+        False
+    """
+    return skeleton(first) == skeleton(second)
+
+
+# The Bidi_Control characters, which change the display order of the text
+# around them without being visible themselves.
+_BIDI_CONTROL_PATTERN = re.compile("[\u061c\u200e\u200f\u202a-\u202e\u2066-\u2069]")
+
+
+class BidiAction(Enum):
+    """What sanitize_bidi does with the bidi control characters it finds."""
+    
+    STRIP = "strip"
+    ESCAPE = "escape"
+
+
+@dataclass
+class BidiFinding:
+    """
+    A bidi control character found by sanitize_bidi.
+    
+    Attributes:
+        index: The position of the character in the original string
+        char: The character itself
+        name: Its Unicode name, such as "RIGHT-TO-LEFT OVERRIDE"
+    """
+    
+    index: int
+    char: str
+    name: str
+
+
+@dataclass
+class BidiReport:
+    """
+    The result of sanitize_bidi.
+    
+    Attributes:
+        text: The sanitized string
+        findings: The bidi control characters found, in order
+    """
+    
+    text: str
+    findings: List[BidiFinding]
+
+
+def sanitize_bidi(input_str: str, action: BidiAction = BidiAction.STRIP) -> BidiReport:
+    """
+    Remove or escape bidirectional control characters, reporting each one.
+    
+    Bidi controls such as U+202E RIGHT-TO-LEFT OVERRIDE pass validation
+    because they are format characters, not control characters, but they
+    can make text display differently from how it is stored: a file named
+    "invoice\\u202efdp.exe" displays as "invoiceexe.pdf". All Bidi_Control
+    characters are handled: the embeddings and overrides U+202A to U+202E,
+    the isolates U+2066 to U+2069, and the marks U+200E, U+200F and
+    U+061C.
+    
+    Args:
+        input_str: The string to sanitize
+        action: BidiAction.STRIP to remove the characters, or
+            BidiAction.ESCAPE to replace each with a visible marker such
+            as "<U+202E>". Defaults to BidiAction.STRIP.
+        
+    Returns:
+        A BidiReport with the sanitized text and, for each character
+        found, its position in the original string and its name
+        
+    Raises:
+        TypeError: If input is not a string or action is not a BidiAction
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> report = sanitize_bidi("invoice\\u202efdp.exe")
+        >>> report.text
+        'invoicefdp.exe'
+        >>> [(found.index, found.name) for found in report.findings]
+        [(7, 'RIGHT-TO-LEFT OVERRIDE')]
+        >>> sanitize_bidi("a\\u2066b", BidiAction.ESCAPE).text
+        'a<U+2066>b'
+    """
+    _validate_input(input_str)
+    if not isinstance(action, BidiAction):
+        raise TypeError(f"Action must be a BidiAction, got {type(action).__name__}")
+    
+    findings = [
+        BidiFinding(match.start(), match.group(), unicodedata.name(match.group()))
+        for match in _BIDI_CONTROL_PATTERN.finditer(input_str)
+    ]
+    if action is BidiAction.ESCAPE:
+        text = _BIDI_CONTROL_PATTERN.sub(_code_point, input_str)
+    else:
+        text = _BIDI_CONTROL_PATTERN.sub("", input_str)
+    return BidiReport(text, findings)
+
+
+def strip_invisible(input_str: str) -> str:
+    """
+    Remove invisible characters that pass validation.
+    
+    Zero-width and other invisible characters are format characters, not
+    control characters, so the validating functions accept them; they can
+    hide inside identifiers and make equal-looking strings compare
+    unequal. Removed are U+200B ZERO WIDTH SPACE, U+FEFF ZERO WIDTH
+    NO-BREAK SPACE (the byte-order mark), U+00AD SOFT HYPHEN, U+2060 WORD
+    JOINER, the invisible operators U+2061 to U+2064, U+034F COMBINING
+    GRAPHEME JOINER, U+180E MONGOLIAN VOWEL SEPARATOR, the Hangul filler
+    characters and the bidi controls (see sanitize_bidi). The joiners
+    U+200C and U+200D and the variation selectors are kept, because emoji
+    sequences and scripts such as Persian and Devanagari depend on them.
+    
+    Args:
+        input_str: The string to clean
+        
+    Returns:
+        The string without invisible characters
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> strip_invisible("pass\\u200bword\\ufeff")
+        'password'
+    """
+    _validate_input(input_str)
+    
+    return _INVISIBLE_CHARACTERS.sub("", input_str)
+
+
+def apply_control_policy(input_str: str, policy: ControlPolicy) -> str:
+    """
+    Deal with control characters the validating functions would reject.
+    
+    The validating functions reject control characters other than tab,
+    newline and carriage return, which makes them fail on logs and
+    terminal captures. STRIP removes the control characters, as
+    strip_control_characters does, and REPLACE replaces each with U+FFFD
+    REPLACEMENT CHARACTER, so the result is accepted by any of them.
+    ALLOW only checks the input as a Capitalizer with that policy does,
+    keeping the control characters, and REJECT checks it as validation
+    does. NUL is never kept, and lone surrogates are always rejected.
+    
+    The same policies can be passed as control_policy to Capitalizer,
+    capitalize_words, capitalize_words_with_delimiters and strip_ansi.
+    The other validating functions always reject control characters, so
+    apply the policy with this function first.
+    
+    Args:
+        input_str: The string to clean
+        policy: What to do with the control characters
+        
+    Returns:
+        The string with the policy applied
+        
+    Raises:
+        TypeError: If input is not a string or policy is not a
+            ControlPolicy
+        ValueError: If input is longer than MAX_STRING_LENGTH or contains
+            a lone surrogate, or contains a disallowed control character
+            under REJECT or NUL under ALLOW
+        
+    Examples:
+        >>> apply_control_policy("\\x1b[31mred\\x1b[0m", ControlPolicy.STRIP)
+        '[31mred[0m'
+        >>> apply_control_policy("bell\\x07", ControlPolicy.REPLACE)
+        'bell\ufffd'
+    """
+    _check_control_policy(policy)
+    
+    return _validate_input(input_str, control_policy=policy)
+
+
+def reveal_invisible(input_str: str) -> str:
+    """
+    Replace invisible characters with visible markers such as "<U+200B>".
+    
+    The characters affected are those removed by strip_invisible. Use it
+    to show users or logs exactly what a string contains.
+    
+    Args:
+        input_str: The string to reveal
+        
+    Returns:
+        The string with each invisible character replaced by its code point
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        
+    Examples:
+        >>> reveal_invisible("pass\\u200bword")
+        'pass<U+200B>word'
+    """
+    _validate_input(input_str)
+    
+    return _INVISIBLE_CHARACTERS.sub(_code_point, input_str)
+
+
+# Characters sanitize_for_log has to look at: everything except printable
+# ASCII other than the backslash.
+_LOG_CANDIDATE_PATTERN = re.compile(r"[^\x20-\x5b\x5d-\x7e]")
+
+_LOG_ESCAPES = {"\\": "\\\\", "\n": "\\n", "\r": "\\r", "\t": "\\t"}
+
+
+def _log_escape(match: "re.Match[str]") -> str:
+    """Escape the matched character unless it is printable and visible."""
+    char = match.group(0)
+    if char in _LOG_ESCAPES:
+        return _LOG_ESCAPES[char]
+    if char.isprintable() and not _DEFAULT_IGNORABLE.match(char):
+        return char
+    if ord(char) < 0x100:
+        return f"\\x{ord(char):02x}"
+    if ord(char) < 0x10000:
+        return f"\\u{ord(char):04x}"
+    return f"\\U{ord(char):08x}"
+
+
+def sanitize_for_log(input_str: str) -> str:
+    """
+    Escape a string so it can be written to a log as part of one entry.
+    
+    Untrusted text containing a line break can forge what looks like a
+    separate log entry, and escape sequences can rewrite or hide what a
+    terminal shows. Newlines, carriage returns and tabs become \\n, \\r
+    and \\t, and every other character that str.isprintable rejects or
+    that is default-ignorable becomes a \\x, \\u or \\U escape: control
+    characters such as the ESC that starts ANSI escape sequences (\\x1b),
+    format characters such as bidi controls and joiners (\\u202e),
+    separators other than the space (\\u2028, \\u00a0), private-use and
+    unassigned code points, lone surrogates, and invisible characters such
+    as variation selectors. Backslashes are doubled, so an escape in the
+    output always stands for the original character and cannot be forged
+    either. The result contains only printable, visible characters and
+    never spans more than one line.
+    
+    Unlike most functions in this module, every character is accepted,
+    since a log line must be written whatever the input contains.
+    
+    Args:
+        input_str: The untrusted text to log
+        
+    Returns:
+        The escaped text
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input exceeds MAX_STRING_LENGTH
+        
+    Examples:
+        >>> print(sanitize_for_log("admin\\n2024-01-01 INFO login ok"))
+        admin\\n2024-01-01 INFO login ok
+        >>> print(sanitize_for_log("\\x1b[31mred\\x1b[0m C:\\\\temp"))
+        \\x1b[31mred\\x1b[0m C:\\\\temp
+    """
+    if not isinstance(input_str, str):
+        raise TypeError(f"Input must be a string, got {type(input_str).__name__}")
+    if len(input_str) > MAX_STRING_LENGTH:
+        raise ValueError(
+            f"Input exceeds maximum length of {MAX_STRING_LENGTH} characters"
+        )
+    
+    return _LOG_CANDIDATE_PATTERN.sub(_log_escape, input_str)
+
+
+# The longest control string body strip_ansi removes, so that an
+# unterminated one cannot hide the rest of the input.
+_MAX_CONTROL_STRING_LENGTH = 4096
+
+# ANSI escape sequences (ECMA-48), in 7-bit and 8-bit form: control strings
+# such as OSC hyperlinks and window titles, which run to a string terminator,
+# the next control character or _MAX_CONTROL_STRING_LENGTH characters; CSI
+# sequences such as colors and cursor movement; and the remaining
+# two-character and character set escapes.
+_ANSI_SEQUENCE_PATTERN = re.compile(
+    "(?:\x1b[P\\]X^_]|[\x90\x98\x9d\x9e\x9f])"
+    f"[^\x00-\x1f\x7f-\x9f]{{0,{_MAX_CONTROL_STRING_LENGTH}}}"
+    "(?:\x07|\x1b\\\\|\x9c)?"
+    "|(?:\x1b\\[|\x9b)[\x30-\x3f]*[\x20-\x2f]*[\x40-\x7e]"
+    "|\x1b[\x20-\x2f]*[\x30-\x7e]"
+)
+
+
+def strip_ansi(
+    input_str: str,
+    control_policy: ControlPolicy = ControlPolicy.ALLOW,
+    reject_invisible: bool = False,
+) -> str:
+    """
+    Remove ANSI escape sequences, such as colors, from terminal output.
+    
+    Removed are CSI sequences such as "\\x1b[31m" (colors and styles)
+    and "\\x1b[2K" (cursor movement and erasing), OSC sequences such as
+    hyperlinks and window titles, up to the BEL or ESC \\ that ends them,
+    and other escape sequences, in their 7-bit and 8-bit forms. An OSC or
+    other control string without a terminator ends at the next control
+    character, such as a newline, or after 4096 characters, so it cannot
+    swallow the rest of the input. The text
+    between sequences is kept, so the result can be capitalized, wrapped
+    or measured like any other text. Control characters that are not part
+    of a sequence are kept.
+    
+    Unlike most functions in this module, input may contain control
+    characters other than NUL, since escape sequences start with one.
+    control_policy is applied to the control characters left after the
+    sequences are removed, such as a bell or a backspace.
+    
+    Args:
+        input_str: The terminal output to clean
+        control_policy: What to do with the control characters left over,
+            as in apply_control_policy. Defaults to ControlPolicy.ALLOW,
+            which keeps them.
+        reject_invisible: Whether to reject input containing invisible
+            characters such as U+200B (see strip_invisible). Defaults to
+            False.
+        
+    Returns:
+        The text without escape sequences
+        
+    Raises:
+        TypeError: If input is not a string or control_policy is not a
+            ControlPolicy
+        ValueError: If input is too long (see MAX_STRING_LENGTH) or
+            contains NUL or a lone surrogate, control characters are
+            left over under ControlPolicy.REJECT, or it contains an
+            invisible character when reject_invisible is set
+        
+    Examples:
+        >>> strip_ansi("\\x1b[1;31merror:\\x1b[0m file not found")
+        'error: file not found'
+        >>> strip_ansi("\\x1b]8;;https://example.com\\x07link\\x1b]8;;\\x07")
+        'link'
+    """
+    _check_control_policy(control_policy)
+    input_str = _validate_input(
+        input_str,
+        reject_invisible=reject_invisible,
+        control_policy=ControlPolicy.ALLOW,
+    )
+    
+    text = _ANSI_SEQUENCE_PATTERN.sub("", input_str)
+    return _apply_control_policy(text, control_policy)
+
+
+def visible_length(input_str: str) -> int:
+    """
+    Compute the number of terminal columns a string occupies when printed.
+    
+    Escape sequences are ignored, as removed by strip_ansi, and the
+    remaining text is measured as by display_width, so colored output can
+    be padded and aligned correctly.
+    
+    Args:
+        input_str: The terminal output to measure
+        
+    Returns:
+        The number of columns the text occupies
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input is too long (see MAX_STRING_LENGTH) or
+            contains NUL or a lone surrogate
+        
+    Examples:
+        >>> visible_length("\\x1b[32mOK\\x1b[0m 日本")
+        7
+    """
+    return _display_width(strip_ansi(input_str))
+
+
+def limit_combining_marks(input_str: str, max_marks: int) -> str:
+    """
+    Limit how many combining marks may stack on one character.
+    
+    "Zalgo" text piles dozens of combining marks onto each letter so that
+    it spills over the lines around it. Marks beyond max_marks in a row
+    are dropped; the first max_marks are kept, so ordinary accented text
+    is unchanged. Decomposed Vietnamese uses up to two marks per letter
+    and pointed Hebrew up to three or four, so a limit of 4 leaves all
+    real text alone.
+    
+    Args:
+        input_str: The string to clean
+        max_marks: The number of consecutive combining marks to keep
+        
+    Returns:
+        The string with excess combining marks removed
+        
+    Raises:
+        TypeError: If input is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH) or
+            max_marks is negative
+        
+    Examples:
+        >>> zalgo = "Z\\u0334\\u0322\\u031b\\u0315a\\u0336\\u0321"
+        >>> ascii(limit_combining_marks(zalgo, 2))
+        "'Z\\\\u0334\\\\u0322a\\\\u0336\\\\u0321'"
+        >>> limit_combining_marks(zalgo, 0)
+        'Za'
+    """
+    _validate_input(input_str)
+    if max_marks < 0:
+        raise ValueError(f"Maximum marks cannot be negative, got {max_marks}")
+    
+    chars: List[str] = []
+    marks = 0
+    for char in input_str:
+        if unicodedata.category(char) in ("Mn", "Me"):
+            marks += 1
+            if marks > max_marks:
+                continue
+        else:
+            marks = 0
+        chars.append(char)
+    return "".join(chars)
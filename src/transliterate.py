@@ -0,0 +1,519 @@
+"""Transliteration of Cyrillic, Greek and Arabic text into Latin letters and slugs."""
+
+import codecs
+import re
+import unicodedata
+from dataclasses import dataclass, field
+from typing import IO, Any, Dict, Iterable, List, Mapping, Optional, Tuple
+
+from src.string_utils import (
+    STREAM_CHUNK_SIZE,
+    _check_characters,
+    _validate_input,
+    slugify_with_separator,
+)
+
+
+class Transliterator:
+    """
+    A table-driven converter from one script to Latin letters.
+    
+    Tables map lowercase letters, or short lowercase letter sequences such
+    as digraphs, to their Latin spelling; uppercase input is matched
+    through its lowercase form and the output is capitalized to follow it.
+    Where sequences overlap, the longest match wins. Characters not in the
+    table are matched by their base letter with accents removed, and are
+    otherwise copied unchanged, so Latin text, digits and punctuation pass
+    through.
+    
+    Some schemes spell a letter differently at the start of a word, such as
+    Russian "е", which BGN/PCGN writes "ye" initially and "e" elsewhere;
+    these spellings go in initial_table. A word is a run of letters and of
+    other characters in the table, such as the Ukrainian apostrophe.
+    
+    Attributes:
+        name (str): A short description of the scheme.
+        table (Dict[str, str]): The lowercase sequences and their spelling.
+        initial_table (Dict[str, str]): Spellings that replace those of
+            table at the start of a word.
+        initial_after (FrozenSet[str]): Lowercase letters after which a
+            letter is also spelled as if it started a word.
+        
+    Example:
+        >>> custom = Transliterator({"ж": "j", "а": "a", "н": "n"})
+        >>> custom.transliterate("Жан")
+        'Jan'
+    """
+    
+    def __init__(
+        self,
+        table: Mapping[str, str],
+        *,
+        initial_table: Optional[Mapping[str, str]] = None,
+        initial_after: Iterable[str] = (),
+        name: str = "",
+    ) -> None:
+        """
+        Initialize the transliterator.
+        
+        Args:
+            table: Letters or letter sequences and their Latin spelling.
+                Keys are matched case-insensitively.
+            initial_table: Spellings used instead of those in table at the
+                start of a word. Defaults to none.
+            initial_after: Letters after which initial_table also applies,
+                such as vowels. Defaults to none.
+            name: A short description of the scheme. Defaults to "".
+            
+        Raises:
+            TypeError: If a table does not map strings to strings
+            ValueError: If a table has an empty key
+        """
+        self.name = name
+        self.table = _lowercase_table(table)
+        self.initial_table = _lowercase_table(initial_table or {})
+        self.initial_after = frozenset(char.lower() for char in initial_after)
+        self._max_key = max(map(len, [*self.table, *self.initial_table]), default=1)
+    
+    def extend(self, table: Mapping[str, str], name: str = "") -> "Transliterator":
+        """
+        Build a transliterator with extra or overriding table entries.
+        
+        Args:
+            table: Entries added to, and taking precedence over, this
+                transliterator's table. They also replace any initial
+                spelling of the same sequence.
+            name: The name of the new scheme. Defaults to this one's.
+            
+        Returns:
+            A new Transliterator; this one is unchanged
+            
+        Raises:
+            TypeError: If table does not map strings to strings
+            ValueError: If table has an empty key
+        """
+        extra = _lowercase_table(table)
+        return Transliterator(
+            {**self.table, **extra},
+            initial_table={
+                key: value
+                for key, value in self.initial_table.items()
+                if key not in extra
+            },
+            initial_after=self.initial_after,
+            name=name or self.name,
+        )
+    
+    def _is_initial(self, previous: str) -> bool:
+        """Return True if a letter following previous starts a word."""
+        lowered = previous.lower()
+        if previous.isalpha() or lowered in self.table:
+            return lowered in self.initial_after
+        return True
+    
+    def _match(self, text: str, index: int, initial: bool) -> Tuple[str, int]:
+        """Return the spelling of the longest match at index and its length."""
+        for length in range(min(self._max_key, len(text) - index), 0, -1):
+            key = text[index : index + length].lower()
+            if initial and key in self.initial_table:
+                return self.initial_table[key], length
+            if key in self.table:
+                return self.table[key], length
+        char = text[index]
+        base = unicodedata.normalize("NFD", char)[0].lower()
+        if base != char.lower() and base in self.table:
+            return self.table[base], 1
+        return char, 1
+    
+    def _transliterate(
+        self, text: str, previous: str = "", final: bool = True
+    ) -> Tuple[str, int]:
+        """
+        Transliterate NFC text, returning the result and the length consumed.
+        
+        Unless final is set, the end of text is held back, since a longer
+        match or the case of the following letter may depend on text that
+        has not arrived yet. previous is the character before text.
+        """
+        limit = len(text)
+        if not final:
+            limit = max(limit - self._max_key - 1, 0)
+            while limit > 0 and unicodedata.combining(text[limit]):
+                limit -= 1
+        parts: List[str] = []
+        index = 0
+        while index < limit:
+            before = text[index - 1] if index else previous
+            spelling, length = self._match(text, index, self._is_initial(before))
+            segment = text[index : index + length]
+            if spelling != segment and segment[:1].isupper():
+                after = text[index + length : index + length + 1]
+                if segment.isupper() and (
+                    len(segment) > 1
+                    or after.isupper()
+                    or (before.isupper() and not after.islower())
+                ):
+                    spelling = spelling.upper()
+                else:
+                    spelling = spelling[:1].upper() + spelling[1:]
+            parts.append(spelling)
+            index += length
+        return "".join(parts), index
+    
+    def transliterate(self, input_str: str) -> str:
+        """
+        Transliterate a string into Latin letters.
+        
+        The input is normalized to NFC first, so decomposed letters such
+        as "и" followed by a combining breve are matched as "й".
+        
+        Args:
+            input_str: The text to transliterate
+            
+        Returns:
+            The transliterated text
+            
+        Raises:
+            TypeError: If input is not a string
+            ValueError: If input fails validation (see MAX_STRING_LENGTH)
+        """
+        _validate_input(input_str)
+        
+        return self._transliterate(unicodedata.normalize("NFC", input_str))[0]
+    
+    def transliterate_stream(
+        self, reader: IO[Any], writer: IO[Any], chunk_size: int = STREAM_CHUNK_SIZE
+    ) -> None:
+        """
+        Transliterate text from a stream, writing the result incrementally.
+        
+        Memory use stays bounded regardless of the total size, and
+        MAX_STRING_LENGTH does not apply. A few characters are held back
+        at each chunk boundary, so the output is exactly that of
+        transliterate on the whole input. Binary streams are decoded and
+        re-encoded as UTF-8 incrementally, as in capitalize_words_stream.
+        
+        Args:
+            reader: A text or binary stream with a read(size) method
+            writer: A stream of the same kind with a write() method
+            chunk_size: The number of characters or bytes to read at a time
+            
+        Raises:
+            ValueError: If the input contains a lone surrogate or a
+                disallowed control character, if a binary stream is not
+                valid UTF-8 (UnicodeDecodeError), or if chunk_size is less
+                than 1
+        """
+        if chunk_size < 1:
+            raise ValueError(f"Chunk size must be at least 1, got {chunk_size}")
+        
+        decoder = codecs.getincrementaldecoder("utf-8")()
+        binary = False
+        pending = ""
+        previous = ""
+        offset = 0
+        while True:
+            chunk = reader.read(chunk_size)
+            if isinstance(chunk, bytes):
+                binary = True
+                text = decoder.decode(chunk, final=not chunk)
+            else:
+                text = chunk
+            _check_characters(text, offset)
+            offset += len(text)
+            pending = unicodedata.normalize("NFC", pending + text)
+            result, consumed = self._transliterate(pending, previous, not chunk)
+            if consumed:
+                previous = pending[consumed - 1]
+                pending = pending[consumed:]
+            if result:
+                writer.write(result.encode("utf-8") if binary else result)
+            if not chunk:
+                break
+
+
+def _lowercase_table(table: Mapping[str, str]) -> Dict[str, str]:
+    """Validate a transliteration table and lowercase its keys."""
+    lowered: Dict[str, str] = {}
+    for key, value in table.items():
+        if not isinstance(key, str) or not isinstance(value, str):
+            raise TypeError(
+                f"Tables must map strings to strings, got "
+                f"{type(key).__name__} to {type(value).__name__}"
+            )
+        if not key:
+            raise ValueError("Table keys cannot be empty")
+        lowered[key.lower()] = value
+    return lowered
+
+
+_RUSSIAN_VOWELS = "аеёиоуыэюя"
+
+# BGN/PCGN 1947 romanization of Russian.
+BGN_PCGN_RUSSIAN = Transliterator(
+    {
+        "а": "a", "б": "b", "в": "v", "г": "g", "д": "d", "е": "e",
+        "ё": "ë", "ж": "zh", "з": "z", "и": "i", "й": "y", "к": "k",
+        "л": "l", "м": "m", "н": "n", "о": "o", "п": "p", "р": "r",
+        "с": "s", "т": "t", "у": "u", "ф": "f", "х": "kh", "ц": "ts",
+        "ч": "ch", "ш": "sh", "щ": "shch", "ъ": "ʺ", "ы": "y",
+        "ь": "ʹ", "э": "e", "ю": "yu", "я": "ya",
+    },
+    initial_table={"е": "ye", "ё": "yë"},
+    initial_after=_RUSSIAN_VOWELS + "йъь",
+    name="BGN/PCGN (Russian)",
+)
+
+# The Ukrainian national system of 2010, as adopted by the UNGEGN.
+UKRAINIAN_NATIONAL = Transliterator(
+    {
+        "а": "a", "б": "b", "в": "v", "г": "h", "ґ": "g", "д": "d",
+        "е": "e", "є": "ie", "ж": "zh", "з": "z", "зг": "zgh", "и": "y",
+        "і": "i", "ї": "i", "й": "i", "к": "k", "л": "l", "м": "m",
+        "н": "n", "о": "o", "п": "p", "р": "r", "с": "s", "т": "t",
+        "у": "u", "ф": "f", "х": "kh", "ц": "ts", "ч": "ch", "ш": "sh",
+        "щ": "shch", "ь": "", "ю": "iu", "я": "ia", "'": "", "ʼ": "",
+        "’": "",
+    },
+    initial_table={"є": "ye", "ї": "yi", "й": "y", "ю": "yu", "я": "ya"},
+    name="Ukrainian national (2010)",
+)
+
+# ISO 9:1995, a reversible one-to-one mapping for all Cyrillic alphabets.
+ISO_9 = Transliterator(
+    {
+        "а": "a", "б": "b", "в": "v", "г": "g", "ґ": "g\u0300", "д": "d",
+        "ѓ": "ǵ", "ђ": "đ", "е": "e", "ё": "ë", "є": "ê",
+        "ж": "ž", "з": "z", "ѕ": "ẑ", "и": "i", "і": "ì",
+        "ї": "ï", "й": "j", "ј": "j\u030c", "к": "k", "ќ": "ḱ",
+        "л": "l", "љ": "l\u0302", "м": "m", "н": "n", "њ": "n\u0302",
+        "о": "o", "п": "p", "р": "r", "с": "s", "т": "t", "ћ": "ć",
+        "у": "u", "ў": "ǔ", "ф": "f", "х": "h", "ц": "c",
+        "ч": "č", "џ": "d\u0302", "ш": "š", "щ": "ŝ",
+        "ъ": "ʺ", "ы": "y", "ь": "ʹ", "э": "è",
+        "ю": "û", "я": "â",
+    },
+    name="ISO 9:1995 (Cyrillic)",
+)
+
+# ELOT 743, the Greek standard also published as ISO 843 (transcription).
+ELOT_743 = Transliterator(
+    {
+        "α": "a", "β": "v", "γ": "g", "δ": "d", "ε": "e", "ζ": "z",
+        "η": "i", "θ": "th", "ι": "i", "κ": "k", "λ": "l", "μ": "m",
+        "ν": "n", "ξ": "x", "ο": "o", "π": "p", "ρ": "r", "σ": "s",
+        "ς": "s", "τ": "t", "υ": "y", "φ": "f", "χ": "ch", "ψ": "ps",
+        "ω": "o", "ου": "ou", "ού": "ou", "αυ": "av", "αύ": "av",
+        "ευ": "ev", "εύ": "ev", "ηυ": "iv", "ηύ": "iv", "γγ": "ng",
+        "γξ": "nx", "γχ": "nch", "μπ": "mp", "ντ": "nt",
+    },
+    initial_table={"μπ": "b", "ντ": "d"},
+    name="ELOT 743 (Greek)",
+)
+
+# A simplified romanization of Arabic based on ALA-LC, without the dots
+# and macrons, for identifiers rather than scholarship. Short vowels are
+# only written when the text carries vowel marks.
+ARABIC_SIMPLIFIED = Transliterator(
+    {
+        "ء": "'", "آ": "a", "أ": "a", "ؤ": "'", "إ": "i", "ئ": "'",
+        "ا": "a", "ب": "b", "ة": "h", "ت": "t", "ث": "th", "ج": "j",
+        "ح": "h", "خ": "kh", "د": "d", "ذ": "dh", "ر": "r", "ز": "z",
+        "س": "s", "ش": "sh", "ص": "s", "ض": "d", "ط": "t", "ظ": "z",
+        "ع": "'", "غ": "gh", "ف": "f", "ق": "q", "ك": "k", "ل": "l",
+        "م": "m", "ن": "n", "ه": "h", "و": "w", "ى": "a", "ي": "y",
+        "ـ": "", "\u064b": "an", "\u064c": "un", "\u064d": "in",
+        "\u064e": "a", "\u064f": "u", "\u0650": "i", "\u0651": "",
+        "\u0652": "", "،": ",", "؛": ";", "؟": "?",
+        "٠": "0", "١": "1", "٢": "2", "٣": "3",
+        "٤": "4", "٥": "5", "٦": "6", "٧": "7",
+        "٨": "8", "٩": "9",
+    },
+    name="Arabic (simplified ALA-LC)",
+)
+
+# Built-in schemes by name: the language code for the usual scheme of a
+# language, and "iso9" for ISO 9.
+SCHEMES: Dict[str, Transliterator] = {
+    "ru": BGN_PCGN_RUSSIAN,
+    "uk": UKRAINIAN_NATIONAL,
+    "iso9": ISO_9,
+    "el": ELOT_743,
+    "ar": ARABIC_SIMPLIFIED,
+}
+
+
+def transliterate(input_str: str, scheme: str) -> str:
+    """
+    Transliterate text into Latin letters using a built-in scheme.
+    
+    Args:
+        input_str: The text to transliterate
+        scheme: A name from SCHEMES: "ru" (BGN/PCGN), "uk" (Ukrainian
+            national), "iso9" (ISO 9, any Cyrillic), "el" (ELOT 743) or
+            "ar" (simplified ALA-LC)
+        
+    Returns:
+        The transliterated text; characters the scheme does not cover,
+        including Latin letters, are kept
+        
+    Raises:
+        TypeError: If input or scheme is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH) or
+            scheme is not a known name
+        
+    Examples:
+        >>> transliterate("Щукин Ёлкин", "ru")
+        'Shchukin Yëlkin'
+        >>> transliterate("Київ", "uk")
+        'Kyiv'
+        >>> transliterate("Αθήνα", "el")
+        'Athina'
+    """
+    _validate_input(input_str)
+    
+    return _lookup_scheme(scheme).transliterate(input_str)
+
+
+def _lookup_scheme(scheme: str) -> Transliterator:
+    """Return the built-in scheme named scheme."""
+    if not isinstance(scheme, str):
+        raise TypeError(f"Scheme must be a string, got {type(scheme).__name__}")
+    if scheme not in SCHEMES:
+        raise ValueError(
+            f"Unknown transliteration scheme {scheme!r}, "
+            f"expected one of {', '.join(sorted(SCHEMES))}"
+        )
+    return SCHEMES[scheme]
+
+
+# The schemes slugify_transliterated applies in turn when none is chosen:
+# Russian first for its readable spellings, then ISO 9 for the letters of
+# other Cyrillic alphabets, then Greek.
+_DEFAULT_SLUG_SCHEMES = (BGN_PCGN_RUSSIAN, ISO_9, ELOT_743)
+
+
+@dataclass
+class SlugOptions:
+    """
+    Settings applied by slugify_transliterated.
+    
+    Attributes:
+        separator: The string placed between words
+        max_length: The maximum length of the slug, or None for no limit.
+            Longer slugs are cut at the last whole word that fits.
+        replacements: Strings replaced before anything else, such as
+            {"&": " and "}; keys are matched case-sensitively, longest
+            first
+        scheme: The name of a built-in scheme from SCHEMES, or None to
+            transliterate Cyrillic and Greek with the usual Russian and
+            Greek schemes
+    """
+    
+    separator: str = "-"
+    max_length: Optional[int] = None
+    replacements: Mapping[str, str] = field(default_factory=dict)
+    scheme: Optional[str] = None
+
+
+def _check_slug_options(options: SlugOptions) -> None:
+    """Raise unless options are usable."""
+    if not isinstance(options.separator, str):
+        raise TypeError(
+            f"Separator must be a string, got {type(options.separator).__name__}"
+        )
+    if not options.separator:
+        raise ValueError("Separator cannot be empty")
+    if options.max_length is not None and options.max_length < 1:
+        raise ValueError(
+            f"Maximum length must be at least 1, got {options.max_length}"
+        )
+    for key, value in options.replacements.items():
+        if not isinstance(key, str) or not isinstance(value, str):
+            raise TypeError(
+                f"Replacements must map strings to strings, got "
+                f"{type(key).__name__} to {type(value).__name__}"
+            )
+        if not key:
+            raise ValueError("Replacement keys cannot be empty")
+    if options.scheme is not None:
+        _lookup_scheme(options.scheme)
+
+
+def _trim_slug(slug: str, separator: str, max_length: int) -> str:
+    """Cut slug to max_length at a word boundary, or mid-word if it must."""
+    if len(slug) <= max_length:
+        return slug
+    cut = slug.rfind(separator, 0, max_length + len(separator))
+    if cut > 0:
+        return slug[:cut]
+    return slug[:max_length]
+
+
+def slugify_transliterated(
+    input_str: str, options: Optional[SlugOptions] = None
+) -> str:
+    """
+    Convert text in any of several scripts into a URL slug.
+    
+    Unlike slugify, which drops letters it cannot fold to ASCII, Cyrillic
+    and Greek text is transliterated first, so a Russian or Greek title
+    still gets a readable slug. The steps are:
+    
+    1. options.replacements are applied, so "&" can become "and" rather
+       than a separator
+    2. Cyrillic and Greek are transliterated, with options.scheme or by
+       default BGN/PCGN for Russian and the other Cyrillic alphabets and
+       ELOT 743 for Greek
+    3. As in slugify_with_separator, accents are folded, letters are
+       lowercased, each run of whitespace and punctuation becomes one
+       separator, and other characters are removed
+    4. If the slug is longer than options.max_length, it is cut after the
+       last whole word that fits; a first word that does not fit on its
+       own is cut mid-word
+    
+    Args:
+        input_str: The text to convert, typically a title
+        options: The settings to apply. Defaults to SlugOptions().
+        
+    Returns:
+        The URL-safe slug, which may be empty if nothing in the input
+        could be spelled in ASCII
+        
+    Raises:
+        TypeError: If input is not a string, or the separator or a
+            replacement is not a string
+        ValueError: If input fails validation (see MAX_STRING_LENGTH), the
+            separator is empty, max_length is less than 1, a replacement
+            key is empty or scheme is not a known name
+        
+    Examples:
+        >>> slugify_transliterated("Щи & борщ: рецепты")
+        'shchi-borshch-retsepty'
+        >>> slugify_transliterated("Καλημέρα κόσμε")
+        'kalimera-kosme'
+        >>> options = SlugOptions(replacements={"&": " and "}, max_length=20)
+        >>> slugify_transliterated("Fish & Chips: A Love Story", options)
+        'fish-and-chips-a'
+    """
+    _validate_input(input_str)
+    if options is None:
+        options = SlugOptions()
+    _check_slug_options(options)
+    
+    text = input_str
+    if options.replacements:
+        keys = sorted(options.replacements, key=len, reverse=True)
+        pattern = re.compile("|".join(map(re.escape, keys)))
+        text = pattern.sub(lambda match: options.replacements[match.group()], text)
+    if options.scheme is not None:
+        schemes: Tuple[Transliterator, ...] = (_lookup_scheme(options.scheme),)
+    else:
+        schemes = _DEFAULT_SLUG_SCHEMES
+    for transliterator in schemes:
+        text = transliterator.transliterate(text)
+    
+    slug = slugify_with_separator(text, options.separator)
+    if options.max_length is not None:
+        slug = _trim_slug(slug, options.separator, options.max_length)
+    return slug
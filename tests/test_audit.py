@@ -0,0 +1,379 @@
+"""
+Unit tests for audit module.
+
+Tests the findings audit_string reports for control characters,
+surrogates, bidi controls, invisible characters, mixed-script words and
+confusable letters, their offsets and severities, the display name rules
+checked by validate_display_name, and error conditions.
+"""
+
+import pytest
+from src.audit import (
+    AuditFinding,
+    AuditReport,
+    DisplayNamePolicy,
+    DisplayNameViolation,
+    IssueKind,
+    Severity,
+    ViolationKind,
+    audit_string,
+    validate_display_name,
+)
+from src.string_utils import MAX_STRING_LENGTH
+
+
+def summary(report):
+    """Return the kind, severity and offsets of each finding."""
+    return [
+        (finding.kind, finding.severity, finding.start, finding.end)
+        for finding in report.findings
+    ]
+
+
+class TestCleanInput:
+    """Test suite for input without findings."""
+
+    def test_plain_text(self):
+        """Test that ordinary text is clean."""
+        report = audit_string("Hello, world! Tab\tand\r\nnewlines are fine.")
+        assert report == AuditReport([], None)
+        assert report.clean
+
+    def test_other_scripts(self):
+        """Test that text in other scripts is not suspicious by itself."""
+        texts = ("Привет мир", "Ελληνικά", "東京タワー", "서울 시", "café naïve")
+        for text in texts:
+            assert audit_string(text).clean, text
+
+    def test_empty(self):
+        """Test that an empty string is clean."""
+        assert audit_string("").clean
+
+
+class TestCharacterFindings:
+    """Test suite for findings about single characters."""
+
+    def test_control_characters(self):
+        """Test that controls are reported, NUL and ESC as high severity."""
+        report = audit_string("a\x00b\x1b[31mc\x07\x85")
+        assert summary(report) == [
+            (IssueKind.CONTROL, Severity.HIGH, 1, 2),
+            (IssueKind.CONTROL, Severity.HIGH, 3, 4),
+            (IssueKind.CONTROL, Severity.MEDIUM, 9, 10),
+            (IssueKind.CONTROL, Severity.MEDIUM, 10, 11),
+        ]
+        assert report.findings[0].description == "U+0000"
+
+    def test_lone_surrogate(self):
+        """Test that a lone surrogate is reported."""
+        assert summary(audit_string("a\udc00")) == [
+            (IssueKind.SURROGATE, Severity.HIGH, 1, 2)
+        ]
+
+    def test_bidi_override(self):
+        """Test that an override is high severity and a mark is low."""
+        report = audit_string("invoice\u202efdp.exe\u200e")
+        assert summary(report) == [
+            (IssueKind.BIDI, Severity.HIGH, 7, 8),
+            (IssueKind.BIDI, Severity.LOW, 15, 16),
+        ]
+        assert report.findings[0] == AuditFinding(
+            IssueKind.BIDI,
+            Severity.HIGH,
+            7,
+            8,
+            "\u202e",
+            "U+202E RIGHT-TO-LEFT OVERRIDE",
+        )
+        assert report.severity is Severity.HIGH
+
+    def test_bidi_isolates(self):
+        """Test that isolates, used in Trojan Source attacks, are high."""
+        report = audit_string("x = 1 \u2067// comment\u2069")
+        assert [finding.severity for finding in report.findings] == [
+            Severity.HIGH,
+            Severity.HIGH,
+        ]
+
+    def test_invisible_characters(self):
+        """Test that zero-width and invisible characters are reported."""
+        report = audit_string("ad\u200bmin\ufeff\u00ad")
+        assert summary(report) == [
+            (IssueKind.INVISIBLE, Severity.MEDIUM, 2, 3),
+            (IssueKind.INVISIBLE, Severity.MEDIUM, 6, 7),
+            (IssueKind.INVISIBLE, Severity.MEDIUM, 7, 8),
+        ]
+        assert report.findings[0].description == "U+200B ZERO WIDTH SPACE"
+
+    def test_emoji_sequences_not_reported(self):
+        """Test that joiners, selectors and tags within emoji are not reported."""
+        england = "\U0001f3f4" + "".join(chr(0xE0000 + ord(c)) for c in "gbeng")
+        text = f"\U0001f468\u200d\U0001f469 \u2764\ufe0f \u2764\ufe0e 1\ufe0f\u20e3"
+        text += f" {england}\U000e007f"
+        assert audit_string(text).clean
+
+    def test_joiners_outside_emoji(self):
+        """Test that joiners outside emoji are reported with low severity."""
+        assert summary(audit_string("می\u200cخواهم")) == [
+            (IssueKind.INVISIBLE, Severity.LOW, 2, 3),
+        ]
+        assert summary(audit_string("admin\u200d")) == [
+            (IssueKind.INVISIBLE, Severity.LOW, 5, 6),
+        ]
+
+    def test_variation_selectors_and_tags(self):
+        """Test that selectors and tag characters outside emoji are reported."""
+        for text, index in [
+            ("admin\ufe0f", 5),
+            ("adm\ufe00in", 3),
+            ("admin\U000e0061", 5),
+            ("admin\U0001bca0", 5),
+        ]:
+            assert summary(audit_string(text)) == [
+                (IssueKind.INVISIBLE, Severity.MEDIUM, index, index + 1),
+            ], ascii(text)
+        finding = audit_string("admin\U000e0061").findings[0]
+        assert finding.description == "U+E0061 TAG LATIN SMALL LETTER A"
+
+
+class TestWordFindings:
+    """Test suite for mixed-script and confusable findings."""
+
+    def test_mixed_script_word(self):
+        """Test that a word mixing Latin and Cyrillic is reported whole."""
+        report = audit_string("login to p\u0430yp\u0430l now")
+        assert summary(report) == [
+            (IssueKind.MIXED_SCRIPT, Severity.HIGH, 9, 15),
+            (IssueKind.CONFUSABLE, Severity.MEDIUM, 10, 11),
+            (IssueKind.CONFUSABLE, Severity.MEDIUM, 13, 14),
+        ]
+        assert report.findings[0].text == "p\u0430yp\u0430l"
+        assert report.findings[0].description == "Letters from Cyrillic, Latin"
+        assert report.findings[1].description == (
+            "U+0430 CYRILLIC SMALL LETTER A looks like 'a'"
+        )
+
+    def test_words_in_different_scripts(self):
+        """Test that separate words in different scripts are not mixed."""
+        assert audit_string("Hello мир").clean
+
+    def test_japanese_combination(self):
+        """Test that scripts written together are not reported as mixed."""
+        assert audit_string("東京タワーです").clean
+
+    def test_whole_script_confusable(self):
+        """Test that a word made only of lookalikes is reported."""
+        report = audit_string("\u0441\u043e\u0441\u043e")
+        assert [finding.kind for finding in report.findings] == [
+            IssueKind.CONFUSABLE
+        ] * 4
+        assert report.severity is Severity.MEDIUM
+
+    def test_fullwidth_letters(self):
+        """Test that fullwidth Latin letters are reported as confusable."""
+        report = audit_string("\uff50\uff41\uff59")
+        assert [finding.start for finding in report.findings] == [0, 1, 2]
+        assert all(finding.kind is IssueKind.CONFUSABLE for finding in report.findings)
+
+    def test_accented_lookalike(self):
+        """Test that an accented lookalike in a Latin word is reported."""
+        report = audit_string("\u0441af\u00e9")
+        assert [finding.kind for finding in report.findings] == [
+            IssueKind.MIXED_SCRIPT,
+            IssueKind.CONFUSABLE,
+        ]
+
+
+class TestReport:
+    """Test suite for the report as a whole."""
+
+    def test_findings_in_order(self):
+        """Test that findings of all kinds are ordered by position."""
+        report = audit_string("\u200b p\u0430y \x1b")
+        assert [finding.kind for finding in report.findings] == [
+            IssueKind.INVISIBLE,
+            IssueKind.MIXED_SCRIPT,
+            IssueKind.CONFUSABLE,
+            IssueKind.CONTROL,
+        ]
+
+    def test_offsets_index_input(self):
+        """Test that each finding's text is the input at its offsets."""
+        text = "x\u202e p\u0430y\u200b \x00"
+        for finding in audit_string(text).findings:
+            assert text[finding.start : finding.end] == finding.text
+
+    def test_highest_severity(self):
+        """Test that the report carries the highest severity found."""
+        assert audit_string("a\u200eb").severity is Severity.LOW
+        assert audit_string("a\u200bb\u200e").severity is Severity.MEDIUM
+        assert audit_string("a\u200b\x00").severity is Severity.HIGH
+
+
+class TestErrors:
+    """Test suite for error conditions."""
+
+    def test_non_string(self):
+        """Test that non-string input raises TypeError."""
+        with pytest.raises(TypeError, match="Input must be a string"):
+            audit_string(b"bytes")
+
+    def test_too_long(self):
+        """Test that input over the maximum length raises ValueError."""
+        with pytest.raises(ValueError, match="maximum length"):
+            audit_string("a" * (MAX_STRING_LENGTH + 1))
+
+
+def violations(name, **policy):
+    """Return the kind and offsets of each violation of a name."""
+    return [
+        (violation.kind, violation.start, violation.end)
+        for violation in validate_display_name(name, DisplayNamePolicy(**policy))
+    ]
+
+
+class TestValidateDisplayName:
+    """Test suite for validate_display_name function."""
+
+    def test_valid_names(self):
+        """Test that ordinary names in any single script are accepted."""
+        names = ("Ada Lovelace", "Zo\u00eb", "Иван Петров", "東京タワー", "user_42")
+        for name in names:
+            assert validate_display_name(name) == [], name
+
+    def test_decomposed_vietnamese(self):
+        """Test that two stacked marks are allowed by default."""
+        assert validate_display_name("Tie\u0302\u0301ng Vie\u0323\u0302t") == []
+
+    def test_empty_name(self):
+        """Test that an empty or blank name is a single violation."""
+        assert violations("") == [(ViolationKind.EMPTY, 0, 0)]
+        assert violations(" \t ") == [(ViolationKind.EMPTY, 0, 3)]
+
+    def test_controls_and_invisible(self):
+        """Test that controls and invisible characters are never allowed."""
+        name = "a\tb\u2028c\u200bd\ud800"
+        assert violations(name, allow_bidi_controls=True) == [
+            (ViolationKind.CONTROL, 1, 2),
+            (ViolationKind.CONTROL, 3, 4),
+            (ViolationKind.INVISIBLE, 5, 6),
+            (ViolationKind.CONTROL, 7, 8),
+        ]
+
+    def test_bidi_controls(self):
+        """Test that bidi controls are rejected unless the policy allows them."""
+        name = "evil\u202egnp.exe"
+        assert violations(name) == [(ViolationKind.BIDI_CONTROL, 4, 5)]
+        assert violations(name, allow_bidi_controls=True) == []
+        message = validate_display_name(name)[0].message
+        assert message == (
+            "Bidirectional control U+202E RIGHT-TO-LEFT OVERRIDE is not allowed"
+        )
+
+    def test_disallowed_script(self):
+        """Test that each run of letters in another script is reported."""
+        latin = frozenset({"Latin"})
+        name = "Ivan \u041f\u0435\u0442\u0440\u043e\u0432"
+        assert violations(name, allowed_scripts=latin) == [
+            (ViolationKind.DISALLOWED_SCRIPT, 5, 11)
+        ]
+        result = validate_display_name(
+            "\u03b1\u03b2 12", DisplayNamePolicy(allowed_scripts=latin)
+        )
+        assert result == [
+            DisplayNameViolation(
+                ViolationKind.DISALLOWED_SCRIPT,
+                0,
+                2,
+                "\u03b1\u03b2",
+                "Letters in the Greek script are not allowed",
+            )
+        ]
+
+    def test_marks_take_script_of_base(self):
+        """Test that combining marks count towards their base letter."""
+        assert violations("Jose\u0301", allowed_scripts=frozenset({"Latin"})) == []
+
+    def test_mixed_script(self):
+        """Test that a word mixing scripts is rejected unless allowed."""
+        assert violations("p\u0430ypal") == [(ViolationKind.MIXED_SCRIPT, 0, 6)]
+        assert violations("p\u0430ypal", allow_mixed_scripts=True) == []
+        assert violations("Hello \u043c\u0438\u0440") == []
+
+    def test_combining_marks(self):
+        """Test that a run of too many marks is reported as a whole."""
+        zalgo = "Z\u0334\u0322\u031b\u0315a"
+        assert violations(zalgo) == [(ViolationKind.COMBINING_MARKS, 1, 5)]
+        assert violations(zalgo, max_combining_marks=4) == []
+        assert violations("e\u0301", max_combining_marks=0) == [
+            (ViolationKind.COMBINING_MARKS, 1, 2)
+        ]
+
+    def test_reserved_names(self):
+        """Test that names confusable with a reserved name are rejected."""
+        reserved = frozenset({"admin", "paypal"})
+        for name in ("admin", "ADMIN", "AdmIn", "PayPa1", "p\u0430yp\u0430l"):
+            kinds = [kind for kind, _, _ in violations(name, reserved_names=reserved)]
+            assert ViolationKind.RESERVED in kinds, name
+        assert violations("administrator", reserved_names=reserved) == []
+
+    def test_reserved_name_with_invisible_characters(self):
+        """Test that invisible characters cannot get around a reserved name."""
+        result = violations(" ad\u200bmin ", reserved_names=frozenset({"admin"}))
+        assert result == [
+            (ViolationKind.RESERVED, 0, 8),
+            (ViolationKind.INVISIBLE, 3, 4),
+        ]
+
+    def test_reserved_name_with_default_ignorables(self):
+        """Test that selectors, tags and joiners cannot get around a reserved name."""
+        for name, index in [
+            ("admin\ufe0f", 5),
+            ("adm\ufe00in", 3),
+            ("admin\U000e0061", 5),
+            ("admin\u200d", 5),
+        ]:
+            assert violations(name, reserved_names=frozenset({"admin"})) == [
+                (ViolationKind.RESERVED, 0, len(name)),
+                (ViolationKind.INVISIBLE, index, index + 1),
+            ], ascii(name)
+
+    def test_emoji_sequences_allowed(self):
+        """Test that joiners and selectors within emoji are allowed."""
+        assert violations("Ada \u2764\ufe0f \U0001f469\u200d\U0001f4bb") == []
+
+    def test_reserved_message(self):
+        """Test that the message names the reserved name collided with."""
+        policy = DisplayNamePolicy(reserved_names=frozenset({"support"}))
+        (violation,) = validate_display_name("SUPP0RT", policy)
+        assert violation.message == (
+            "Name is too similar to the reserved name 'support'"
+        )
+
+    def test_all_violations_reported(self):
+        """Test that every violation is reported, in order of position."""
+        name = "\u202ep\u0430yp\u0430l\u200b"
+        result = violations(name, reserved_names=frozenset({"paypal"}))
+        kinds = [kind for kind, _, _ in result]
+        assert kinds == [
+            ViolationKind.MIXED_SCRIPT,
+            ViolationKind.RESERVED,
+            ViolationKind.BIDI_CONTROL,
+            ViolationKind.INVISIBLE,
+        ]
+
+    def test_invalid_policy(self):
+        """Test that an unusable policy raises."""
+        with pytest.raises(ValueError, match="cannot be negative"):
+            validate_display_name("name", DisplayNamePolicy(max_combining_marks=-1))
+        with pytest.raises(TypeError, match="Allowed scripts"):
+            validate_display_name("name", DisplayNamePolicy(allowed_scripts="Latin"))
+        with pytest.raises(TypeError, match="Reserved names"):
+            validate_display_name("name", DisplayNamePolicy(reserved_names="admin"))
+
+    def test_invalid_input(self):
+        """Test that non-string and overlong input raise."""
+        with pytest.raises(TypeError, match="Input must be a string"):
+            validate_display_name(None)
+        with pytest.raises(ValueError, match="maximum length"):
+            validate_display_name("a" * (MAX_STRING_LENGTH + 1))
@@ -0,0 +1,241 @@
+"""
+Unit tests for base_encoding module.
+
+Tests Base58, Base62 and Crockford Base32 encoding and decoding of bytes
+and integers, leading zero bytes, Crockford's forgiving decoding and check
+symbols, and error conditions.
+"""
+
+import base64
+import os
+
+import pytest
+from src.base_encoding import (
+    BASE58_ALPHABET,
+    BASE62_ALPHABET,
+    CROCKFORD_ALPHABET,
+    MAX_ENCODED_LENGTH,
+    decode_base58,
+    decode_base58_int,
+    decode_base62,
+    decode_base62_int,
+    decode_crockford32,
+    decode_crockford32_int,
+    encode_base58,
+    encode_base62,
+    encode_crockford32,
+)
+
+
+class TestBase58:
+    """Test suite for encode_base58, decode_base58 and decode_base58_int."""
+
+    def test_known_values(self):
+        """Test encodings shared with Bitcoin's Base58."""
+        assert encode_base58(b"hello world") == "StV1DL6CwTryKyV"
+        assert encode_base58(bytes.fromhex("0000287fb4cd")) == "11233QC4"
+        assert decode_base58("StV1DL6CwTryKyV") == b"hello world"
+
+    def test_leading_zero_bytes(self):
+        """Test that each leading zero byte is written as "1"."""
+        assert encode_base58(b"") == ""
+        assert encode_base58(b"\x00") == "1"
+        assert encode_base58(b"\x00\x00\x01") == "112"
+        assert decode_base58("112") == b"\x00\x00\x01"
+        assert decode_base58("") == b""
+
+    def test_integers(self):
+        """Test encoding and decoding integers."""
+        assert encode_base58(0) == "1"
+        assert encode_base58(57) == "z"
+        assert encode_base58(58) == "21"
+        assert encode_base58(2**64 - 1) == "jpXCZedGfVQ"
+        assert decode_base58_int("jpXCZedGfVQ") == 2**64 - 1
+
+    def test_alphabet_excludes_ambiguous(self):
+        """Test that "0", "O", "I" and "l" are not used."""
+        assert not set("0OIl") & set(BASE58_ALPHABET)
+        with pytest.raises(ValueError, match="Invalid Base58 character '0' at index 2"):
+            decode_base58("ab0c")
+
+    def test_round_trip(self):
+        """Test that random bytes survive a round trip."""
+        for size in range(0, 40):
+            data = b"\x00" * (size % 3) + os.urandom(size)
+            assert decode_base58(encode_base58(data)) == data
+
+
+class TestBase62:
+    """Test suite for encode_base62, decode_base62 and decode_base62_int."""
+
+    def test_known_values(self):
+        """Test encoding bytes and integers."""
+        assert encode_base62(b"hello world") == "AAwf93rvy4aWQVw"
+        assert encode_base62(61) == "z"
+        assert encode_base62(62) == "10"
+        assert encode_base62(2**64 - 1) == "LygHa16AHYF"
+
+    def test_alphabet(self):
+        """Test that only digits and ASCII letters are used."""
+        assert len(BASE62_ALPHABET) == 62
+        assert BASE62_ALPHABET.isalnum() and BASE62_ALPHABET.isascii()
+
+    def test_leading_zero_bytes(self):
+        """Test that each leading zero byte is written as "0"."""
+        assert encode_base62(b"\x00\x00\xff") == "0047"
+        assert decode_base62("0047") == b"\x00\x00\xff"
+
+    def test_round_trip(self):
+        """Test that random bytes and integers survive a round trip."""
+        for size in range(0, 40):
+            data = b"\x00" * (size % 3) + os.urandom(size)
+            assert decode_base62(encode_base62(data)) == data
+        for value in (0, 1, 2**32, 2**64 - 1, 2**100 + 7):
+            assert decode_base62_int(encode_base62(value)) == value
+
+    def test_invalid_character(self):
+        """Test that characters outside the alphabet raise ValueError."""
+        with pytest.raises(ValueError, match="Invalid Base62 character '-' at index 1"):
+            decode_base62_int("a-b")
+
+
+class TestCrockford32:
+    """Test suite for Crockford Base32 encoding and decoding."""
+
+    def test_bytes_match_rfc_4648_bit_layout(self):
+        """Test that bytes are packed in 5-bit groups like RFC 4648 Base32."""
+        table = str.maketrans("ABCDEFGHIJKLMNOPQRSTUVWXYZ234567", CROCKFORD_ALPHABET)
+        for size in range(0, 20):
+            data = os.urandom(size)
+            expected = base64.b32encode(data).decode().rstrip("=").translate(table)
+            assert encode_crockford32(data) == expected
+            assert decode_crockford32(expected) == data
+
+    def test_known_values(self):
+        """Test encoding bytes and integers."""
+        assert encode_crockford32(b"hello") == "D1JPRV3F"
+        assert encode_crockford32(b"") == ""
+        assert encode_crockford32(0) == "0"
+        assert encode_crockford32(32) == "10"
+        assert encode_crockford32(1234) == "16J"
+
+    def test_forgiving_decoding(self):
+        """Test that case, hyphens and lookalike letters are accepted."""
+        assert decode_crockford32("d1jp-rv3f") == b"hello"
+        assert decode_crockford32_int("1O") == 32
+        assert decode_crockford32_int("iL") == 33
+        assert decode_crockford32_int("Io") == decode_crockford32_int("10")
+
+    def test_u_is_invalid(self):
+        """Test that "U" is not a data symbol."""
+        assert "U" not in CROCKFORD_ALPHABET
+        with pytest.raises(ValueError, match="Invalid Crockford Base32 character 'U'"):
+            decode_crockford32_int("1U")
+
+    def test_checksum(self):
+        """Test that the check symbol is the value modulo 37."""
+        assert encode_crockford32(1234, checksum=True) == "16JD"
+        assert encode_crockford32(32, checksum=True) == "10*"
+        assert encode_crockford32(36, checksum=True) == "14U"
+        assert encode_crockford32(0, checksum=True) == "00"
+        assert decode_crockford32_int("16jd", checksum=True) == 1234
+        assert decode_crockford32_int("14u", checksum=True) == 36
+        data = b"order-42"
+        encoded = encode_crockford32(data, checksum=True)
+        assert encoded[:-1] == encode_crockford32(data)
+        assert decode_crockford32(encoded, checksum=True) == data
+
+    def test_checksum_detects_errors(self):
+        """Test that a wrong or swapped symbol fails the checksum."""
+        with pytest.raises(ValueError, match="expected 'D', got 'E'"):
+            decode_crockford32_int("16JE", checksum=True)
+        with pytest.raises(ValueError, match="checksum mismatch"):
+            decode_crockford32_int("1J6D", checksum=True)
+        with pytest.raises(ValueError, match="checksum mismatch"):
+            decode_crockford32_int("17JD", checksum=True)
+
+    def test_checksum_errors(self):
+        """Test that missing or invalid check symbols raise ValueError."""
+        with pytest.raises(ValueError, match="cannot be empty"):
+            decode_crockford32("", checksum=True)
+        with pytest.raises(ValueError, match="Invalid Crockford Base32 check symbol"):
+            decode_crockford32_int("16J#", checksum=True)
+
+    def test_invalid_lengths(self):
+        """Test that lengths no bytes encode to raise ValueError."""
+        for text in ("0", "000", "000000"):
+            with pytest.raises(ValueError, match="no bytes encode to it"):
+                decode_crockford32(text)
+
+    def test_nonzero_padding(self):
+        """Test that text with nonzero padding bits raises ValueError."""
+        assert decode_crockford32("CR") == b"f"
+        with pytest.raises(ValueError, match="nonzero padding bits"):
+            decode_crockford32("CS")
+
+
+class TestErrors:
+    """Test suite for error conditions shared by the codecs."""
+
+    def test_invalid_data(self):
+        """Test that data other than bytes or an int raises TypeError."""
+        for encode in (encode_base58, encode_base62, encode_crockford32):
+            with pytest.raises(TypeError, match="Data must be bytes or an int"):
+                encode("text")
+            with pytest.raises(TypeError, match="Data must be bytes or an int"):
+                encode(True)
+
+    def test_bytes_like(self):
+        """Test that bytearray and memoryview are accepted."""
+        assert encode_base58(bytearray(b"hello world")) == "StV1DL6CwTryKyV"
+        assert encode_base62(memoryview(b"hello world")) == "AAwf93rvy4aWQVw"
+
+    def test_negative_number(self):
+        """Test that negative numbers raise ValueError."""
+        for encode in (encode_base58, encode_base62, encode_crockford32):
+            with pytest.raises(ValueError, match="negative number, got -1"):
+                encode(-1)
+
+    def test_invalid_text(self):
+        """Test that decoding non-string input raises TypeError."""
+        for decode in (decode_base58, decode_base62_int, decode_crockford32):
+            with pytest.raises(TypeError, match="Input must be a string"):
+                decode(b"abc")
+
+    def test_empty_number(self):
+        """Test that an empty string cannot be decoded as a number."""
+        for decode in (decode_base58_int, decode_base62_int, decode_crockford32_int):
+            with pytest.raises(ValueError, match="Cannot decode an empty"):
+                decode("")
+
+    def test_maximum_encoded_length(self):
+        """Test that data encoding to more than MAX_ENCODED_LENGTH is refused."""
+        codecs = [
+            (encode_base58, decode_base58, 749),
+            (encode_base62, decode_base62, 762),
+            (encode_crockford32, decode_crockford32, 640),
+        ]
+        for encode, decode, size in codecs:
+            data = b"\xff" * size
+            text = encode(data)
+            assert len(text) <= MAX_ENCODED_LENGTH
+            assert decode(text) == data
+            with pytest.raises(ValueError, match="too large to encode"):
+                encode(data + b"\xff")
+            with pytest.raises(ValueError, match="too large to encode"):
+                encode(1 << (size * 8 + 8))
+
+    def test_long_text_refused(self):
+        """Test that decoding text longer than MAX_ENCODED_LENGTH is refused."""
+        text = "2" * (MAX_ENCODED_LENGTH + 1)
+        for decode in (
+            decode_base58,
+            decode_base58_int,
+            decode_base62,
+            decode_base62_int,
+            decode_crockford32,
+            decode_crockford32_int,
+        ):
+            with pytest.raises(ValueError, match="exceeds maximum length of 1024"):
+                decode(text)
+        assert decode_crockford32_int("-".join("2" * MAX_ENCODED_LENGTH)) > 0
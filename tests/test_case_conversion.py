@@ -0,0 +1,361 @@
+"""
+Unit tests for case_conversion module.
+
+Tests conversion of human-readable strings into identifier case styles,
+including separator handling, numbers, unicode and error conditions.
+"""
+
+import pytest
+from src.case_conversion import (
+    CaseConverter,
+    CaseStyle,
+    detect_case,
+    to_camel_case,
+    to_dot_case,
+    to_kebab_case,
+    to_pascal_case,
+    to_path_case,
+    to_screaming_snake_case,
+    to_snake_case,
+    to_train_case,
+)
+
+
+class TestToCamelCase:
+    """Test suite for to_camel_case function."""
+
+    def test_separator_styles(self):
+        """Test that spaces, hyphens and underscores are all boundaries."""
+        assert to_camel_case("hello world") == "helloWorld"
+        assert to_camel_case("hello-world") == "helloWorld"
+        assert to_camel_case("hello_world") == "helloWorld"
+
+    def test_runs_of_separators(self):
+        """Test that leading, trailing and repeated separators are dropped."""
+        assert to_camel_case("  __hello -- big_world  ") == "helloBigWorld"
+
+    def test_first_letter_lowercased(self):
+        """Test that the first letter is lowercased."""
+        assert to_camel_case("Hello World") == "helloWorld"
+
+    def test_camel_and_pascal_input(self):
+        """Test splitting existing camelCase and PascalCase identifiers."""
+        assert to_camel_case("userName") == "userName"
+        assert to_camel_case("UserName") == "userName"
+        assert to_camel_case("user_NAME") == "userName"
+
+    def test_acronyms(self):
+        """Test that acronyms are split off and normalized like other words."""
+        assert to_camel_case("HTTPServer") == "httpServer"
+        assert to_camel_case("XMLHttpRequest") == "xmlHttpRequest"
+        assert to_camel_case("parseHTTPResponse") == "parseHttpResponse"
+
+    def test_numbers_attach_to_previous_word(self):
+        """Test that numbers do not force a word boundary."""
+        assert to_camel_case("item 2 count") == "item2Count"
+
+    def test_unicode(self):
+        """Test converting words with non-ASCII letters."""
+        assert to_camel_case("élan über") == "élanÜber"
+
+    def test_empty_and_separator_only(self):
+        """Test inputs that contain no words."""
+        assert to_camel_case("") == ""
+        assert to_camel_case(" - _ ") == ""
+
+    def test_rejects_control_characters(self):
+        """Test that disallowed control characters raise ValueError."""
+        with pytest.raises(ValueError, match="control character"):
+            to_camel_case("hello\x00world")
+
+    def test_type_error(self):
+        """Test that TypeError is raised for non-string input."""
+        with pytest.raises(TypeError, match="Input must be a string"):
+            to_camel_case(None)
+
+
+class TestToPascalCase:
+    """Test suite for to_pascal_case function."""
+
+    def test_separator_styles(self):
+        """Test that spaces, hyphens and underscores are all boundaries."""
+        assert to_pascal_case("hello world") == "HelloWorld"
+        assert to_pascal_case("hello-world") == "HelloWorld"
+        assert to_pascal_case("hello_world") == "HelloWorld"
+
+    def test_numbers(self):
+        """Test that numbers attach to the preceding word."""
+        assert to_pascal_case("item 2 count") == "Item2Count"
+
+    def test_camel_case_input(self):
+        """Test splitting an existing camelCase identifier."""
+        assert to_pascal_case("userName") == "UserName"
+        assert to_pascal_case("userID") == "UserId"
+
+    def test_acronyms(self):
+        """Test that acronyms are split off and normalized like other words."""
+        assert to_pascal_case("HTTPServer") == "HttpServer"
+        assert to_pascal_case("XMLHttpRequest") == "XmlHttpRequest"
+
+    def test_empty(self):
+        """Test converting an empty string."""
+        assert to_pascal_case("") == ""
+
+
+class TestToSnakeCase:
+    """Test suite for to_snake_case function."""
+
+    def test_camel_and_pascal_input(self):
+        """Test splitting existing camelCase and PascalCase identifiers."""
+        assert to_snake_case("helloWorld") == "hello_world"
+        assert to_snake_case("HelloWorld") == "hello_world"
+
+    def test_acronyms(self):
+        """Test that consecutive capitals are treated as one word."""
+        assert to_snake_case("parseHTTPResponse") == "parse_http_response"
+        assert to_snake_case("HTTPServer") == "http_server"
+        assert to_snake_case("userID") == "user_id"
+
+    def test_digits(self):
+        """Test that digits stay with the preceding word."""
+        assert to_snake_case("item2Count") == "item2_count"
+        assert to_snake_case("version 2") == "version_2"
+
+    def test_separator_runs_collapse(self):
+        """Test that whitespace and punctuation collapse to one separator."""
+        assert to_snake_case("  Hello,   World! ") == "hello_world"
+        assert to_snake_case("hello--world__again") == "hello_world_again"
+
+    def test_unicode(self):
+        """Test splitting identifiers with non-ASCII letters."""
+        assert to_snake_case("straßeÜber") == "straße_über"
+
+    def test_empty(self):
+        """Test inputs without words."""
+        assert to_snake_case("") == ""
+        assert to_snake_case("--!!--") == ""
+
+
+class TestToKebabCase:
+    """Test suite for to_kebab_case function."""
+
+    def test_camel_and_pascal_input(self):
+        """Test splitting existing camelCase and PascalCase identifiers."""
+        assert to_kebab_case("helloWorld") == "hello-world"
+        assert to_kebab_case("HelloWorld") == "hello-world"
+
+    def test_acronyms(self):
+        """Test that consecutive capitals are treated as one word."""
+        assert to_kebab_case("parseHTTPResponse") == "parse-http-response"
+
+    def test_trims_separators(self):
+        """Test that no leading or trailing hyphen is produced."""
+        assert to_kebab_case("__hello world__") == "hello-world"
+
+    def test_rejects_control_characters(self):
+        """Test that disallowed control characters raise ValueError."""
+        with pytest.raises(ValueError, match="control character"):
+            to_kebab_case("hello\x1bworld")
+
+
+class TestToScreamingSnakeCase:
+    """Test suite for to_screaming_snake_case function."""
+
+    def test_from_camel_case(self):
+        """Test converting camelCase input."""
+        assert to_screaming_snake_case("maxRetryCount") == "MAX_RETRY_COUNT"
+
+    def test_from_text(self):
+        """Test converting human-readable text with punctuation."""
+        assert to_screaming_snake_case("  api base-url ") == "API_BASE_URL"
+
+    def test_acronyms_and_digits(self):
+        """Test that acronyms stay together and digits split before capitals."""
+        assert to_screaming_snake_case("parseHTTPResponse") == "PARSE_HTTP_RESPONSE"
+        assert to_screaming_snake_case("version2Update") == "VERSION2_UPDATE"
+
+    def test_empty_string(self):
+        """Test that empty input gives an empty identifier."""
+        assert to_screaming_snake_case("") == ""
+
+    def test_non_string_input(self):
+        """Test that non-string input raises TypeError."""
+        with pytest.raises(TypeError, match="Input must be a string"):
+            to_screaming_snake_case(42)
+
+
+class TestToDotTrainPathCase:
+    """Test suite for to_dot_case, to_train_case and to_path_case functions."""
+
+    def test_dot_case(self):
+        """Test converting to dot.case."""
+        assert to_dot_case("userProfileId") == "user.profile.id"
+        assert to_dot_case("User Profile ID") == "user.profile.id"
+
+    def test_train_case(self):
+        """Test converting to Train-Case."""
+        assert to_train_case("user_profile_id") == "User-Profile-Id"
+        assert to_train_case("contentType") == "Content-Type"
+
+    def test_path_case(self):
+        """Test converting to path/case."""
+        assert to_path_case("user-profile-id") == "user/profile/id"
+
+    def test_shared_splitter(self):
+        """Test that acronyms and digits split as in to_snake_case."""
+        text = "parseHTTPResponse2Body"
+        words = to_snake_case(text).split("_")
+        assert to_dot_case(text) == ".".join(words)
+        assert to_path_case(text) == "/".join(words)
+        assert to_train_case(text) == "Parse-Http-Response2-Body"
+
+    def test_round_trip(self):
+        """Test that dot.case and path/case convert back to other styles."""
+        for convert in (to_dot_case, to_path_case, to_train_case):
+            converted = convert("userProfileId")
+            assert to_camel_case(converted) == "userProfileId"
+            assert to_pascal_case(converted) == "UserProfileId"
+            assert to_snake_case(converted) == "user_profile_id"
+            assert to_dot_case(converted) == "user.profile.id"
+            assert to_path_case(converted) == "user/profile/id"
+
+    def test_separators_trimmed(self):
+        """Test that leading and trailing separators are dropped."""
+        assert to_dot_case("  __hello world__ ") == "hello.world"
+        assert to_path_case("/a/b/") == "a/b"
+
+    def test_empty_and_invalid_input(self):
+        """Test empty input and validation errors."""
+        assert to_train_case("") == ""
+        with pytest.raises(TypeError, match="Input must be a string"):
+            to_dot_case(None)
+        with pytest.raises(ValueError, match="control character"):
+            to_path_case("a\x07b")
+
+
+class TestCaseConverter:
+    """Test suite for CaseConverter class."""
+
+    def test_acronym_round_trip(self):
+        """Test that "userID" round-trips through snake_case."""
+        converter = CaseConverter(acronyms=["ID"])
+        snake = converter.to_snake_case("userID")
+        assert snake == "user_id"
+        assert converter.to_camel_case(snake) == "userID"
+
+    def test_pascal_case_with_acronyms(self):
+        """Test that acronyms keep their spelling in PascalCase."""
+        converter = CaseConverter(acronyms=["HTTP", "URL"])
+        assert converter.to_pascal_case("http_server_url") == "HTTPServerURL"
+
+    def test_first_word_acronym_in_camel_case(self):
+        """Test that a leading acronym is lowercased in camelCase."""
+        converter = CaseConverter(acronyms=["ID"])
+        assert converter.to_camel_case("ID_token") == "idToken"
+
+    def test_words_normalized(self):
+        """Test that non-acronym words are normalized to an initial capital."""
+        converter = CaseConverter()
+        assert converter.to_camel_case("HELLO big_WORLD") == "helloBigWorld"
+        assert converter.to_pascal_case("parseHTTPResponse") == "ParseHttpResponse"
+
+    def test_mixed_case_acronym(self):
+        """Test that acronyms are matched case-insensitively."""
+        converter = CaseConverter(acronyms=["iOS"])
+        assert converter.to_pascal_case("ios app") == "iOSApp"
+
+    def test_snake_kebab_and_screaming(self):
+        """Test the separator-joined styles."""
+        converter = CaseConverter(acronyms=["ID"])
+        assert converter.to_kebab_case("userID") == "user-id"
+        assert converter.to_screaming_snake_case("userID") == "USER_ID"
+
+    def test_empty_and_invalid_input(self):
+        """Test empty input and validation errors."""
+        converter = CaseConverter()
+        assert converter.to_camel_case("") == ""
+        with pytest.raises(ValueError, match="control character"):
+            converter.to_pascal_case("a\x00b")
+
+    def test_acronyms_with_internal_capitals(self):
+        """Test that registered words such as "iOS" are not split at capitals."""
+        converter = CaseConverter(acronyms=["iOS", "eBay"])
+        assert converter.to_snake_case("iOSAppForEBaySeller") == (
+            "ios_app_for_ebay_seller"
+        )
+        assert converter.to_pascal_case("ios_app_for_ebay") == "iOSAppForeBay"
+        assert converter.to_kebab_case("myIOSApp") == "my-ios-app"
+
+    def test_screaming_snake_case_with_acronyms(self):
+        """Test that acronym merging applies to every output style."""
+        converter = CaseConverter(acronyms=["eBay"])
+        assert converter.to_screaming_snake_case("eBayListing") == "EBAY_LISTING"
+
+
+class TestDetectCase:
+    """Test suite for detect_case function."""
+
+    @pytest.mark.parametrize(
+        "text, style",
+        [
+            ("user_profile_id", CaseStyle.SNAKE),
+            ("USER_PROFILE_ID", CaseStyle.SCREAMING_SNAKE),
+            ("user-profile-id", CaseStyle.KEBAB),
+            ("User-Profile-Id", CaseStyle.TRAIN),
+            ("user.profile.id", CaseStyle.DOT),
+            ("user/profile/id", CaseStyle.PATH),
+            ("userProfileId", CaseStyle.CAMEL),
+            ("UserProfileId", CaseStyle.PASCAL),
+            ("User Profile Id", CaseStyle.TITLE),
+            ("user profile id", CaseStyle.LOWER),
+            ("USER PROFILE ID", CaseStyle.UPPER),
+        ],
+    )
+    def test_unambiguous_styles(self, text, style):
+        """Test that multi-word identifiers are detected with full confidence."""
+        detection = detect_case(text)
+        assert detection.style == style
+        assert detection.confidence == 1.0
+        assert detection.candidates == (style,)
+
+    def test_acronyms_in_camel_and_pascal_case(self):
+        """Test that all-uppercase words are accepted in camel and Pascal case."""
+        assert detect_case("userID").style == CaseStyle.CAMEL
+        assert detect_case("HTTPServer").style == CaseStyle.PASCAL
+
+    def test_single_word_is_ambiguous(self):
+        """Test that a single word lists every style it is valid in."""
+        detection = detect_case("User")
+        assert detection.style == CaseStyle.TITLE
+        assert detection.candidates == (
+            CaseStyle.TITLE,
+            CaseStyle.TRAIN,
+            CaseStyle.PASCAL,
+        )
+        assert detection.confidence == pytest.approx(1 / 3)
+
+    def test_mixed(self):
+        """Test that input fitting no style is reported as mixed."""
+        for text in ("Hello world", "user_Profile", "user-profile_id", "a__b"):
+            detection = detect_case(text)
+            assert detection.style == CaseStyle.MIXED
+            assert detection.confidence == 1.0
+            assert detection.candidates == ()
+
+    def test_no_words(self):
+        """Test that input without words is mixed with zero confidence."""
+        detection = detect_case("  --  ")
+        assert detection.style == CaseStyle.MIXED
+        assert detection.confidence == 0.0
+
+    def test_round_trip_with_converters(self):
+        """Test that converter output is detected as the matching style."""
+        text = "parse HTTP response"
+        assert detect_case(to_snake_case(text)).style == CaseStyle.SNAKE
+        assert detect_case(to_kebab_case(text)).style == CaseStyle.KEBAB
+        assert detect_case(to_train_case(text)).style == CaseStyle.TRAIN
+
+    def test_non_string_input(self):
+        """Test that non-string input raises TypeError."""
+        with pytest.raises(TypeError, match="Input must be a string"):
+            detect_case(3.5)
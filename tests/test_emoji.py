@@ -0,0 +1,141 @@
+"""
+Unit tests for emoji module.
+
+Tests emoji detection, counting and removal with emoji sequences treated
+as single units, and conversion to and from :shortcode: form.
+"""
+
+import pytest
+from src.emoji import (
+    DEFAULT_SHORTCODES,
+    count_emoji,
+    emoji_to_shortcodes,
+    has_emoji,
+    shortcodes_to_emoji,
+    strip_emoji,
+)
+
+FAMILY = "\U0001f468\u200d\U0001f469\u200d\U0001f467"
+THUMBS_UP_MEDIUM = "\U0001f44d\U0001f3fd"
+FLAG_NZ = "\U0001f1f3\U0001f1ff"
+KEYCAP_ONE = "1\ufe0f\u20e3"
+
+
+class TestHasEmoji:
+    """Test suite for has_emoji function."""
+
+    def test_detects_emoji(self):
+        """Test that single emoji and sequences are detected."""
+        assert has_emoji("Ship it \U0001f680")
+        assert has_emoji(FAMILY)
+        assert has_emoji(FLAG_NZ)
+        assert has_emoji(KEYCAP_ONE)
+
+    def test_text_presentation_pictographs(self):
+        """Test that pictographs drawn as text are not emoji."""
+        assert not has_emoji("© 2024 ™")
+        assert not has_emoji("☺")
+        assert not has_emoji("❤\ufe0e")
+        assert has_emoji("❤\ufe0f")
+
+    def test_plain_text(self):
+        """Test that text without emoji is not matched."""
+        assert not has_emoji("Hello, 世界! 123")
+        assert not has_emoji("")
+
+    def test_invalid_input(self):
+        """Test that non-string input raises TypeError."""
+        with pytest.raises(TypeError, match="Input must be a string"):
+            has_emoji(None)
+
+
+class TestCountEmoji:
+    """Test suite for count_emoji function."""
+
+    def test_sequences_count_once(self):
+        """Test that ZWJ sequences, skin tones, flags and keycaps count once."""
+        assert count_emoji(FAMILY) == 1
+        assert count_emoji(THUMBS_UP_MEDIUM) == 1
+        assert count_emoji(FLAG_NZ) == 1
+        assert count_emoji(KEYCAP_ONE) == 1
+
+    def test_mixed_text(self):
+        """Test counting emoji among text."""
+        text = f"Great job {THUMBS_UP_MEDIUM}{THUMBS_UP_MEDIUM} from {FAMILY}!"
+        assert count_emoji(text) == 3
+
+    def test_no_emoji(self):
+        """Test that text without emoji has a count of zero."""
+        assert count_emoji("plain text") == 0
+
+
+class TestStripEmoji:
+    """Test suite for strip_emoji function."""
+
+    def test_removes_whole_sequences(self):
+        """Test that no joiner, modifier or selector is left behind."""
+        assert strip_emoji(f"a{FAMILY}b{THUMBS_UP_MEDIUM}c❤\ufe0f") == "abc"
+
+    def test_keeps_text_and_whitespace(self):
+        """Test that surrounding text and whitespace are kept."""
+        assert strip_emoji("Done ✅!") == "Done !"
+        assert strip_emoji("café ©") == "café ©"
+
+
+class TestShortcodes:
+    """Test suite for emoji_to_shortcodes and shortcodes_to_emoji."""
+
+    def test_emoji_to_shortcodes(self):
+        """Test replacing named emoji with shortcodes."""
+        assert emoji_to_shortcodes("Ship it \U0001f680\U0001f389") == (
+            "Ship it :rocket::tada:"
+        )
+        assert emoji_to_shortcodes(FAMILY) == ":family:"
+
+    def test_first_name_is_canonical(self):
+        """Test that the first of several names is used."""
+        assert emoji_to_shortcodes("\U0001f44d") == ":thumbsup:"
+
+    def test_presentation_selector_optional(self):
+        """Test that emoji match with or without U+FE0F."""
+        assert emoji_to_shortcodes("❤\ufe0f") == ":heart:"
+        assert emoji_to_shortcodes("⚠") == "⚠"
+        assert emoji_to_shortcodes("⚠\ufe0f") == ":warning:"
+
+    def test_skin_tones(self):
+        """Test that skin tones are written after the base emoji's name."""
+        assert emoji_to_shortcodes(THUMBS_UP_MEDIUM) == ":thumbsup::skin-tone-4:"
+        assert shortcodes_to_emoji(":thumbsup::skin-tone-4:") == THUMBS_UP_MEDIUM
+
+    def test_unnamed_emoji_kept(self):
+        """Test that emoji without a shortcode are kept."""
+        assert emoji_to_shortcodes("\U0001f9a9") == "\U0001f9a9"
+
+    def test_shortcodes_to_emoji(self):
+        """Test replacing known shortcodes, including aliases."""
+        assert shortcodes_to_emoji(":+1: :fire:") == "\U0001f44d \U0001f525"
+        assert shortcodes_to_emoji(":heart:") == "❤\ufe0f"
+
+    def test_unknown_and_lookalike_shortcodes(self):
+        """Test that unknown names and times are left alone."""
+        text = "at 12:30:45 :not_an_emoji:"
+        assert shortcodes_to_emoji(text) == text
+
+    def test_shortcodes_joined_to_words_kept(self):
+        """Test that known names between words or colons are left alone."""
+        for text in ("aspect 1:100:1 and perms rw:x:r", "x:fire: :fire:x", "::fire::"):
+            assert shortcodes_to_emoji(text) == text
+        assert shortcodes_to_emoji("(:fire:)") == "(\U0001f525)"
+
+    def test_round_trip(self):
+        """Test that every default emoji survives a round trip."""
+        for emoji in set(DEFAULT_SHORTCODES.values()):
+            assert shortcodes_to_emoji(emoji_to_shortcodes(emoji)) == emoji
+
+    def test_custom_table(self):
+        """Test converting with a custom shortcode table."""
+        custom = {"ship": "\U0001f680"}
+        assert emoji_to_shortcodes("\U0001f680\U0001f389", custom) == (
+            ":ship:\U0001f389"
+        )
+        assert shortcodes_to_emoji(":ship: :rocket:", custom) == "\U0001f680 :rocket:"
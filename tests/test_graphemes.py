@@ -0,0 +1,199 @@
+"""
+Unit tests for graphemes module.
+
+Tests segmentation into extended grapheme clusters, counting, slicing and
+chunking by cluster, and that word capitalization keeps clusters intact.
+"""
+
+import pytest
+from src.graphemes import (
+    chunk_code_points,
+    chunk_graphemes,
+    grapheme_count,
+    grapheme_slice,
+    iter_graphemes,
+)
+from src.string_utils import Capitalizer, capitalize_words
+
+
+class TestIterGraphemes:
+    """Test suite for iter_graphemes function."""
+
+    def test_ascii(self):
+        """Test that plain ASCII yields one cluster per character."""
+        assert list(iter_graphemes("abc")) == ["a", "b", "c"]
+
+    def test_combining_marks(self):
+        """Test that combining marks stay with their base letter."""
+        text = "e\u0301a\u0308\u0304"
+        assert list(iter_graphemes(text)) == ["e\u0301", "a\u0308\u0304"]
+
+    def test_emoji_sequences(self):
+        """Test that ZWJ sequences, skin tones and flags are single clusters."""
+        family = "\U0001f468\u200d\U0001f469\u200d\U0001f467"
+        thumbs = "\U0001f44d\U0001f3fd"
+        flags = "\U0001f1f3\U0001f1ff\U0001f1e6\U0001f1fa"
+        assert list(iter_graphemes(family + thumbs)) == [family, thumbs]
+        assert list(iter_graphemes(flags)) == [
+            "\U0001f1f3\U0001f1ff",
+            "\U0001f1e6\U0001f1fa",
+        ]
+
+    def test_crlf_and_hangul(self):
+        """Test that CR LF and conjoining jamo form single clusters."""
+        assert list(iter_graphemes("a\r\nb")) == ["a", "\r\n", "b"]
+        assert list(iter_graphemes("\u1100\u1161\u11a8")) == ["\u1100\u1161\u11a8"]
+
+    def test_roundtrip_and_empty(self):
+        """Test that clusters join back to the input and empty yields nothing."""
+        text = "Cafe\u0301 \U0001f44b\U0001f3fb!"
+        assert "".join(iter_graphemes(text)) == text
+        assert list(iter_graphemes("")) == []
+
+    def test_invalid_input(self):
+        """Test that invalid input raises immediately, not on iteration."""
+        with pytest.raises(TypeError, match="Input must be a string"):
+            iter_graphemes(None)
+        with pytest.raises(ValueError):
+            iter_graphemes("a\x00b")
+
+
+class TestGraphemeCount:
+    """Test suite for grapheme_count function."""
+
+    def test_counts_clusters(self):
+        """Test that clusters, not code points, are counted."""
+        assert grapheme_count("hello") == 5
+        assert grapheme_count("e\u0301") == 1
+        family = "\U0001f468\u200d\U0001f469\u200d\U0001f467"
+        assert grapheme_count(family + " ok") == 4
+
+    def test_empty(self):
+        """Test that an empty string has no clusters."""
+        assert grapheme_count("") == 0
+
+    def test_invalid_input(self):
+        """Test that non-string input raises TypeError."""
+        with pytest.raises(TypeError, match="Input must be a string"):
+            grapheme_count(42)
+
+
+class TestGraphemeSlice:
+    """Test suite for grapheme_slice function."""
+
+    def test_slices_by_cluster(self):
+        """Test that positions count clusters."""
+        text = "e\u0301a\u0308xyz"
+        assert grapheme_slice(text, 0, 2) == "e\u0301a\u0308"
+        assert grapheme_slice(text, 1, 3) == "a\u0308x"
+
+    def test_defaults_and_negative_positions(self):
+        """Test Python slice semantics for omitted and negative positions."""
+        text = "ab\U0001f44d\U0001f3fdc"
+        assert grapheme_slice(text) == text
+        assert grapheme_slice(text, 2) == "\U0001f44d\U0001f3fdc"
+        assert grapheme_slice(text, -2, -1) == "\U0001f44d\U0001f3fd"
+
+    def test_out_of_range(self):
+        """Test that out-of-range positions are clamped like str slicing."""
+        assert grapheme_slice("abc", 5) == ""
+        assert grapheme_slice("abc", -10, 10) == "abc"
+
+    def test_invalid_position(self):
+        """Test that non-integer positions raise TypeError."""
+        with pytest.raises(TypeError, match="Position must be an integer"):
+            grapheme_slice("abc", "1")
+
+
+class TestCapitalizeWordsClusters:
+    """Test suite for grapheme-aware capitalization in capitalize_words."""
+
+    def test_combining_marks_kept(self):
+        """Test that a decomposed first letter keeps its marks."""
+        assert capitalize_words("e\u0301cole a\u0308pfel") == "E\u0301cole A\u0308pfel"
+
+    def test_emoji_words_unchanged(self):
+        """Test that words made of emoji sequences pass through intact."""
+        family = "\U0001f468\u200d\U0001f469\u200d\U0001f467"
+        assert capitalize_words(f"{family} family") == f"{family} Family"
+
+    def test_locale_mapping_uses_cluster(self):
+        """Test that locale mappings see the marks in the first cluster."""
+        capitalizer = Capitalizer(locale="lt")
+        assert capitalizer.capitalize("i\u0307stanbul") == "Istanbul"
+
+
+class TestChunkCodePoints:
+    """Test suite for chunk_code_points function."""
+
+    def test_even_and_uneven_split(self):
+        """Test that only the last piece may be short."""
+        assert chunk_code_points("abcdef", 2) == ["ab", "cd", "ef"]
+        assert chunk_code_points("abcdefg", 3) == ["abc", "def", "g"]
+
+    def test_size_larger_than_input(self):
+        """Test that a short string is a single piece."""
+        assert chunk_code_points("abc", 10) == ["abc"]
+
+    def test_empty_string(self):
+        """Test that an empty string gives no pieces."""
+        assert chunk_code_points("", 3) == []
+
+    def test_counts_code_points(self):
+        """Test that non-ASCII characters count as one unit each."""
+        assert chunk_code_points("\u00e9\u4e2d\U0001f600x", 2) == [
+            "\u00e9\u4e2d",
+            "\U0001f600x",
+        ]
+
+    def test_invalid_size(self):
+        """Test that a bad size is rejected."""
+        with pytest.raises(TypeError, match="Size must be an integer"):
+            chunk_code_points("abc", 1.5)
+        with pytest.raises(ValueError, match="Size must be at least 1"):
+            chunk_code_points("abc", 0)
+
+    def test_invalid_input(self):
+        """Test that non-string input raises TypeError."""
+        with pytest.raises(TypeError):
+            chunk_code_points(None, 2)
+
+
+class TestChunkGraphemes:
+    """Test suite for chunk_graphemes function."""
+
+    def test_ascii(self):
+        """Test that plain ASCII is split like chunk_code_points."""
+        assert chunk_graphemes("abcdefg", 3) == ["abc", "def", "g"]
+
+    def test_keeps_combining_marks(self):
+        """Test that a combining mark stays with its base letter."""
+        assert chunk_graphemes("ae\u0301io", 2) == ["ae\u0301", "io"]
+
+    def test_keeps_emoji_sequences(self):
+        """Test that ZWJ sequences and flags are never split."""
+        family = "\U0001f468\u200d\U0001f469\u200d\U0001f467"
+        flag = "\U0001f1f3\U0001f1ff"
+        assert chunk_graphemes(family + flag + "a", 1) == [family, flag, "a"]
+        assert chunk_graphemes(family + flag + "a", 2) == [family + flag, "a"]
+
+    def test_crlf_is_one_unit(self):
+        """Test that CR LF counts as a single cluster."""
+        assert chunk_graphemes("a\r\nb", 2) == ["a\r\n", "b"]
+
+    def test_round_trip(self):
+        """Test that joining the pieces reproduces the input."""
+        text = "cafe\u0301 \U0001f44d\U0001f3fd ok"
+        for size in range(1, 8):
+            assert "".join(chunk_graphemes(text, size)) == text
+
+    def test_empty_string(self):
+        """Test that an empty string gives no pieces."""
+        assert chunk_graphemes("", 2) == []
+
+    def test_invalid_size(self):
+        """Test that a bad size is rejected."""
+        with pytest.raises(TypeError, match="Size must be an integer"):
+            chunk_graphemes("abc", "2")
+        with pytest.raises(ValueError, match="Size must be at least 1"):
+            chunk_graphemes("abc", -1)
@@ -0,0 +1,146 @@
+"""
+Unit tests for idna module.
+
+Tests Punycode encoding and decoding, IDNA2008 ToASCII and ToUnicode
+conversion, label mapping and the validation rules.
+"""
+
+import pytest
+from src.idna import from_punycode, idna_to_ascii, idna_to_unicode, to_punycode
+
+
+class TestPunycode:
+    """Test suite for to_punycode and from_punycode functions."""
+
+    def test_round_trip(self):
+        """Test the RFC 3492 encoding and its inverse."""
+        assert to_punycode("bücher") == "bcher-kva"
+        assert from_punycode("bcher-kva") == "bücher"
+        assert from_punycode(to_punycode("例え")) == "例え"
+
+    def test_ascii_input(self):
+        """Test that ASCII strings get a trailing delimiter."""
+        assert to_punycode("abc") == "abc-"
+        assert from_punycode("abc-") == "abc"
+
+    def test_invalid_punycode(self):
+        """Test that malformed and non-ASCII Punycode raise ValueError."""
+        with pytest.raises(ValueError, match="Invalid Punycode"):
+            from_punycode("99999999")
+        with pytest.raises(ValueError, match="must be ASCII"):
+            from_punycode("bücher")
+
+    def test_invalid_input(self):
+        """Test that non-string input raises TypeError."""
+        with pytest.raises(TypeError, match="Input must be a string"):
+            to_punycode(None)
+
+
+class TestIdnaToAscii:
+    """Test suite for idna_to_ascii function."""
+
+    def test_encodes_unicode_labels(self):
+        """Test that non-ASCII labels become xn-- A-labels."""
+        assert idna_to_ascii("bücher.example") == "xn--bcher-kva.example"
+        assert idna_to_ascii("例え.テスト") == "xn--r8jz45g.xn--zckzah"
+        assert idna_to_ascii("مثال.إختبار") == "xn--mgbh0fb.xn--kgbechtv"
+
+    def test_mapping(self):
+        """Test lowercasing, width folding and label separators."""
+        assert idna_to_ascii("Bücher.EXAMPLE") == "xn--bcher-kva.example"
+        assert idna_to_ascii("ＥＸＡＭＰＬＥ．com") == "example.com"
+        assert idna_to_ascii("例え。テスト") == "xn--r8jz45g.xn--zckzah"
+
+    def test_idna2008_differs_from_idna2003(self):
+        """Test that sharp s is kept rather than mapped to ss."""
+        assert idna_to_ascii("faß.de") == "xn--fa-hia.de"
+
+    def test_root_label_kept(self):
+        """Test that a trailing root full stop is kept."""
+        assert idna_to_ascii("bücher.example.") == "xn--bcher-kva.example."
+
+    def test_contextual_characters(self):
+        """Test the CONTEXTJ and CONTEXTO rules."""
+        assert idna_to_ascii("l·l.cat") == "xn--ll-0ea.cat"
+        assert idna_to_ascii("क\u094d\u200dष.in").startswith("xn--")
+        with pytest.raises(ValueError, match="<U\\+00B7> at index 1"):
+            idna_to_ascii("a·b.cat")
+        with pytest.raises(ValueError, match="<U\\+200D> at index 1"):
+            idna_to_ascii("a\u200db.com")
+
+    def test_zero_width_non_joiner_between_joining_letters(self):
+        """Test the RFC 5892 joining context for U+200C in Persian."""
+        ascii_name = idna_to_ascii("نامه\u200cای.ir")
+        assert ascii_name.startswith("xn--")
+        assert idna_to_unicode(ascii_name) == "نامه\u200cای.ir"
+        # A mark between the letter and U+200C is transparent.
+        assert idna_to_ascii("نامه\u064e\u200cای.ir").startswith("xn--")
+        for label in ("a\u200cb", "\u200cای", "نامه\u200c", "و\u200cای"):
+            with pytest.raises(ValueError, match="<U\\+200C>"):
+                idna_to_ascii(label + ".ir")
+
+    def test_disallowed_characters(self):
+        """Test that symbols, punctuation and compatibility forms are rejected."""
+        for domain in ("☃.net", "a_b.com", "Ǆ.com", "a b.com"):
+            with pytest.raises(ValueError, match="disallowed character"):
+                idna_to_ascii(domain)
+
+    def test_hyphen_rules(self):
+        """Test leading, trailing and third-and-fourth position hyphens."""
+        with pytest.raises(ValueError, match="starts or ends with a hyphen"):
+            idna_to_ascii("-ab.com")
+        with pytest.raises(ValueError, match="third and fourth"):
+            idna_to_ascii("ab--c.com")
+
+    def test_leading_combining_mark(self):
+        """Test that a label cannot start with a combining mark."""
+        with pytest.raises(ValueError, match="combining mark"):
+            idna_to_ascii("\u0301a.com")
+
+    def test_invalid_a_labels(self):
+        """Test that malformed or non-canonical A-labels are rejected."""
+        for domain in ("xn--zz.com", "xn--abc-.com", "xn--a.com"):
+            with pytest.raises(ValueError, match="not a valid A-label"):
+                idna_to_ascii(domain)
+
+    def test_bidi_rule(self):
+        """Test that right-to-left labels may not mix directions."""
+        assert idna_to_ascii("שלום1.co") == "xn--1-9hcuf1d.co"
+        with pytest.raises(ValueError, match="mixes text directions"):
+            idna_to_ascii("שa.co")
+        with pytest.raises(ValueError, match="mixes text directions"):
+            idna_to_ascii("1a.שלום")
+
+    def test_lengths(self):
+        """Test the 63-character label and 253-character name limits."""
+        idna_to_ascii("a" * 63 + ".com")
+        with pytest.raises(ValueError, match="longer than 63"):
+            idna_to_ascii("a" * 64 + ".com")
+        with pytest.raises(ValueError, match="longer than 253"):
+            idna_to_ascii(".".join(["a" * 63] * 4))
+
+    def test_empty_labels(self):
+        """Test that empty names and labels are rejected."""
+        with pytest.raises(ValueError, match="cannot be empty"):
+            idna_to_ascii("")
+        with pytest.raises(ValueError, match="empty label"):
+            idna_to_ascii("a..b")
+
+
+class TestIdnaToUnicode:
+    """Test suite for idna_to_unicode function."""
+
+    def test_decodes_a_labels(self):
+        """Test that A-labels are decoded and other labels lowercased."""
+        assert idna_to_unicode("xn--bcher-kva.EXAMPLE") == "bücher.example"
+        assert idna_to_unicode("xn--r8jz45g.xn--zckzah") == "例え.テスト"
+
+    def test_round_trip(self):
+        """Test that ToUnicode inverts ToASCII."""
+        domain = "faß.例え.example."
+        assert idna_to_unicode(idna_to_ascii(domain)) == domain
+
+    def test_validates(self):
+        """Test that decoded labels are validated."""
+        with pytest.raises(ValueError, match="not a valid A-label"):
+            idna_to_unicode("xn--abc.com")
@@ -0,0 +1,169 @@
+"""
+Unit tests for markup module.
+
+Tests tag removal, entity decoding, whitespace and block handling,
+hidden elements, link targets, and error conditions.
+"""
+
+import pytest
+from src.markup import strip_html
+from src.string_utils import capitalize_words
+
+
+class TestStripHtmlTags:
+    """Test suite for tag and comment removal."""
+
+    def test_inline_tags(self):
+        """Test that inline tags are removed and their text kept."""
+        assert strip_html("a <b>bold</b> and <i>italic</i> word") == (
+            "a bold and italic word"
+        )
+
+    def test_comments(self):
+        """Test that comments are removed."""
+        assert strip_html("hi <!-- note --> there") == "hi there"
+
+    def test_hidden_elements(self):
+        """Test that script and style content is dropped."""
+        html = "<style>p { color: red }</style>x<script>alert('<b>')</script>y"
+        assert strip_html(html) == "xy"
+
+    def test_attributes_not_kept(self):
+        """Test that attribute values do not appear in the text."""
+        assert strip_html('<img alt="cat" src="cat.png">text') == "text"
+
+    def test_plain_text(self):
+        """Test that text without markup is returned unchanged."""
+        assert strip_html("just text") == "just text"
+
+    def test_empty(self):
+        """Test that an empty string gives an empty string."""
+        assert strip_html("") == ""
+
+
+class TestStripHtmlEntities:
+    """Test suite for character reference decoding."""
+
+    def test_named(self):
+        """Test that named references are decoded."""
+        assert strip_html("5 &lt; 6 &amp;&amp; 7 &gt; 6") == "5 < 6 && 7 > 6"
+
+    def test_numeric(self):
+        """Test that decimal and hexadecimal references are decoded."""
+        assert strip_html("Caf&#233; &#x2014; ok") == "Café — ok"
+
+    def test_unknown_kept(self):
+        """Test that an unknown reference is kept as written."""
+        assert strip_html("a &bogus; b") == "a &bogus; b"
+
+    def test_decoded_tags_not_removed(self):
+        """Test that escaped markup is text, not a tag."""
+        assert strip_html("&lt;b&gt;hi&lt;/b&gt;") == "<b>hi</b>"
+
+    def test_decoded_controls_removed(self):
+        """Test that control characters from references are removed."""
+        assert strip_html("<pre>a&#12;b&#27;c&#0;</pre>") == "abc\ufffd"
+
+
+class TestStripHtmlLayout:
+    """Test suite for whitespace and block elements."""
+
+    def test_whitespace_collapsed(self):
+        """Test that runs of whitespace become one space."""
+        assert strip_html("<div>\n  hello\n   world  </div>") == "hello world"
+
+    def test_paragraphs(self):
+        """Test that paragraphs are separated by a blank line."""
+        assert strip_html("<p>one</p><p>two</p>") == "one\n\ntwo"
+
+    def test_line_elements(self):
+        """Test that list items and divs start new lines."""
+        html = "<ul><li>one</li><li>two</li></ul><div>a</div><div>b</div>"
+        assert strip_html(html) == "one\ntwo\n\na\nb"
+
+    def test_br(self):
+        """Test that each br starts a new line."""
+        assert strip_html("a<br>b<br/><br>c") == "a\nb\n\nc"
+
+    def test_pre_preserved(self):
+        """Test that whitespace inside pre is kept."""
+        assert strip_html("<p>x</p><pre>a\n    b</pre>") == "x\n\na\n    b"
+
+    def test_pre_leading_whitespace_kept(self):
+        """Test that indentation at the start of a leading pre is kept."""
+        assert strip_html("<pre>  a\n  b</pre>") == "  a\n  b"
+        assert strip_html("<pre>\n  a</pre>") == "  a"
+        assert strip_html("<p>&nbsp;hi</p>") == "hi"
+
+    def test_table_cells_separated(self):
+        """Test that cells in a row are separated by a tab."""
+        assert strip_html("<td>Name</td><td>Age</td>") == "Name\tAge"
+        table = (
+            "<table><tr><th>Name </th><th> Age</th></tr>"
+            "<tr><td>Ada</td><td>36</td></tr></table>"
+        )
+        assert strip_html(table) == "Name\tAge\nAda\t36"
+
+    def test_adjacent_inline_text(self):
+        """Test that inline tags do not add spaces."""
+        assert strip_html("<b>Hel</b>lo") == "Hello"
+
+
+class TestStripHtmlLinks:
+    """Test suite for keep_links."""
+
+    def test_links_dropped_by_default(self):
+        """Test that link targets are not kept by default."""
+        assert strip_html('<a href="https://example.com">docs</a>') == "docs"
+
+    def test_keep_links(self):
+        """Test that the target follows the link text in brackets."""
+        html = 'Read <a href="https://example.com/a?b=1&amp;c=2">the docs</a>.'
+        assert strip_html(html, keep_links=True) == (
+            "Read the docs [https://example.com/a?b=1&c=2]."
+        )
+
+    def test_target_equal_to_text(self):
+        """Test that a target equal to the text is not repeated."""
+        html = '<a href="https://example.com">https://example.com</a>'
+        assert strip_html(html, keep_links=True) == "https://example.com"
+
+    def test_link_without_text(self):
+        """Test that a link without text keeps just its target."""
+        html = '<a href="/home"><img src="logo.png"></a>'
+        assert strip_html(html, keep_links=True) == "[/home]"
+
+    def test_unsafe_schemes_dropped(self):
+        """Test that script and data targets are left out."""
+        html = (
+            '<a href="javascript:alert(1)">a</a> '
+            '<a href=" JavaScript:x">b</a> <a href="data:text/html,x">c</a>'
+        )
+        assert strip_html(html, keep_links=True) == "a b c"
+
+    def test_anchor_without_href(self):
+        """Test that an anchor without a target is plain text."""
+        assert strip_html('<a name="top">Top</a>', keep_links=True) == "Top"
+
+
+class TestStripHtmlCapitalize:
+    """Test suite for passing the result to capitalize_words."""
+
+    def test_capitalize_result(self):
+        """Test that the text can be capitalized."""
+        text = strip_html("<h1>caf&eacute; &amp; bar</h1><p>open&nbsp;late</p>")
+        assert capitalize_words(text) == "Café & Bar\n\nOpen\u00a0Late"
+
+
+class TestStripHtmlErrors:
+    """Test suite for error conditions."""
+
+    def test_non_string(self):
+        """Test that non-string input raises TypeError."""
+        with pytest.raises(TypeError, match="Input must be a string"):
+            strip_html(b"<p>x</p>")
+
+    def test_control_characters(self):
+        """Test that raw control characters raise ValueError."""
+        with pytest.raises(ValueError):
+            strip_html("<p>\x00</p>")
@@ -0,0 +1,149 @@
+"""
+Unit tests for ngrams module.
+
+Tests character and word n-grams, their iterator variants, grapheme
+safety, shingling, Jaccard similarity and error conditions.
+"""
+
+import types
+
+import pytest
+from src.ngrams import (
+    iter_ngrams,
+    iter_word_ngrams,
+    jaccard_similarity,
+    ngrams,
+    shingles,
+    word_ngrams,
+)
+
+
+class TestNgrams:
+    """Test suite for ngrams and iter_ngrams functions."""
+
+    def test_character_ngrams(self):
+        """Test sliding windows over characters."""
+        assert ngrams("night", 2) == ["ni", "ig", "gh", "ht"]
+        assert ngrams("night", 5) == ["night"]
+        assert ngrams("aaa", 2) == ["aa", "aa"]
+
+    def test_shorter_than_n(self):
+        """Test that strings shorter than n have no n-grams."""
+        assert ngrams("ab", 3) == []
+        assert ngrams("", 1) == []
+
+    def test_grapheme_safe(self):
+        """Test that combining marks and emoji sequences are not split."""
+        assert ngrams("e\u0301te\u0301", 2) == ["e\u0301t", "te\u0301"]
+        family = "\U0001f468\u200d\U0001f469\u200d\U0001f467"
+        assert ngrams(family + "ab", 2) == [family + "a", "ab"]
+
+    def test_iterator_is_lazy(self):
+        """Test that iter_ngrams returns a generator."""
+        result = iter_ngrams("abcd", 3)
+        assert isinstance(result, types.GeneratorType)
+        assert next(result) == "abc"
+        assert list(result) == ["bcd"]
+
+    def test_validation_is_eager(self):
+        """Test that bad arguments raise before iteration starts."""
+        with pytest.raises(TypeError, match="Input must be a string"):
+            iter_ngrams(None, 2)
+        with pytest.raises(ValueError, match="at least 1"):
+            iter_ngrams("abc", 0)
+        with pytest.raises(TypeError, match="N must be an integer"):
+            iter_ngrams("abc", 2.0)
+
+
+class TestWordNgrams:
+    """Test suite for word_ngrams and iter_word_ngrams functions."""
+
+    def test_word_ngrams(self):
+        """Test sliding windows over whitespace-delimited words."""
+        assert word_ngrams("the quick brown fox", 2) == [
+            "the quick", "quick brown", "brown fox"
+        ]
+        assert word_ngrams("one two", 1) == ["one", "two"]
+
+    def test_whitespace_normalized(self):
+        """Test that words are joined by a single space."""
+        assert word_ngrams("  a\t\tb\nc  ", 2) == ["a b", "b c"]
+
+    def test_fewer_words_than_n(self):
+        """Test that strings with fewer than n words have no n-grams."""
+        assert word_ngrams("only two", 3) == []
+        assert word_ngrams("", 2) == []
+
+    def test_iterator_is_lazy(self):
+        """Test that iter_word_ngrams yields one n-gram at a time."""
+        result = iter_word_ngrams("a b c", 2)
+        assert next(result) == "a b"
+        assert next(result) == "b c"
+
+    def test_invalid_n(self):
+        """Test that n below 1 raises ValueError."""
+        with pytest.raises(ValueError, match="at least 1"):
+            word_ngrams("a b", -1)
+
+
+class TestShingles:
+    """Test suite for shingles function."""
+
+    def test_shingle_count(self):
+        """Test that a document of w words has w - k + 1 shingles."""
+        assert len(shingles("a b c d e", 3)) == 3
+        assert shingles("a b", 3) == frozenset()
+
+    def test_case_and_spacing_ignored(self):
+        """Test that case and whitespace do not change the shingles."""
+        assert shingles("The Quick brown fox", 2) == shingles(
+            "the quick\nbrown  FOX", 2
+        )
+
+    def test_repeated_shingles_counted_once(self):
+        """Test that shingles form a set."""
+        assert len(shingles("to be to be", 2)) == 2
+
+    def test_stable_hash(self):
+        """Test that hashes are fixed 64-bit values, not salted per process."""
+        assert shingles("hello world", 2) == frozenset({0x878633AA32A3B150})
+
+    def test_invalid_k(self):
+        """Test that k below 1 raises ValueError."""
+        with pytest.raises(ValueError, match="at least 1"):
+            shingles("a b", 0)
+
+    def test_invalid_input(self):
+        """Test that non-string input raises TypeError."""
+        with pytest.raises(TypeError, match="Input must be a string"):
+            shingles(None, 3)
+        with pytest.raises(TypeError, match="Input must be a string"):
+            shingles(b"a b c", 3)
+
+
+class TestJaccardSimilarity:
+    """Test suite for jaccard_similarity function."""
+
+    def test_similarity_values(self):
+        """Test identical, disjoint and overlapping sets."""
+        assert jaccard_similarity({1, 2}, {1, 2}) == 1.0
+        assert jaccard_similarity({1, 2}, {3, 4}) == 0.0
+        assert jaccard_similarity({1, 2, 3}, {2, 3, 4}) == 0.5
+
+    def test_empty_sets(self):
+        """Test that two empty sets are identical."""
+        assert jaccard_similarity(set(), frozenset()) == 1.0
+        assert jaccard_similarity(set(), {1}) == 0.0
+
+    def test_near_duplicates(self):
+        """Test that a small edit keeps documents similar."""
+        original = shingles("the quick brown fox jumps over the lazy dog today", 3)
+        edited = shingles("the quick brown fox jumps over the lazy cat today", 3)
+        unrelated = shingles("completely different text about something else", 3)
+        assert jaccard_similarity(original, edited) > 0.5
+        assert jaccard_similarity(original, unrelated) == 0.0
+
+    def test_invalid_arguments(self):
+        """Test that non-set arguments raise TypeError."""
+        with pytest.raises(TypeError, match="must be sets"):
+            jaccard_similarity([1, 2], {1})
@@ -0,0 +1,179 @@
+"""
+Unit tests for profanity module.
+
+Tests the ProfanityFilter class with whole-word and substring matching,
+leetspeak normalization, masking characters, match reporting, and error
+conditions.
+"""
+
+import pytest
+from src.filter import MatchMode, ProfanityFilter, ProfanityMatch
+from src.filter.profanity import LEET_SUBSTITUTIONS
+
+
+class TestWholeWord:
+    """Test suite for whole-word matching."""
+
+    def test_finds_word(self):
+        """Test that a listed word is found with its position."""
+        assert ProfanityFilter(["darn"]).find("Oh darn.") == [
+            ProfanityMatch("darn", "darn", 3, 7)
+        ]
+
+    def test_case_insensitive(self):
+        """Test that words match regardless of case."""
+        assert ProfanityFilter(["Darn"]).mask("DARN darn dArN") == "**** **** ****"
+
+    def test_not_inside_words(self):
+        """Test that words inside longer words are not matched."""
+        profanity = ProfanityFilter(["ass"])
+        assert profanity.find("a classic bass assessment") == []
+        assert profanity.contains("you ass!")
+
+    def test_underscore_separates(self):
+        """Test that an underscore does not join words."""
+        assert ProfanityFilter(["heck"]).mask("what_the_heck") == "what_the_****"
+
+    def test_combining_marks(self):
+        """Test that a combining mark continues the word it follows."""
+        profanity = ProfanityFilter(["hell"])
+        assert profanity.find("hell\u0301o") == []
+        assert profanity.mask("hell\u0301!") == "****!"
+
+    def test_longest_word_wins(self):
+        """Test that the longer of two words at one position is reported."""
+        profanity = ProfanityFilter(["darn", "darnit"])
+        assert profanity.words == ["darnit", "darn"]
+        assert [match.word for match in profanity.find("darnit, darn")] == [
+            "darnit",
+            "darn",
+        ]
+
+    def test_phrase(self):
+        """Test that words with spaces and punctuation match as written."""
+        profanity = ProfanityFilter(["what the heck", "a.b"])
+        assert profanity.mask("So what the heck? axb a.b") == (
+            "So *************? axb ***"
+        )
+
+    def test_non_ascii_words(self):
+        """Test that words outside ASCII match case-insensitively."""
+        assert ProfanityFilter(["Straße"]).mask("STRAßE") == "******"
+
+    def test_empty_word_list(self):
+        """Test that a filter without words finds nothing."""
+        profanity = ProfanityFilter([])
+        assert profanity.find("anything") == []
+        assert profanity.mask("anything") == "anything"
+        assert not profanity.contains("anything")
+
+
+class TestSubstring:
+    """Test suite for substring matching."""
+
+    def test_inside_words(self):
+        """Test that words are matched inside longer words."""
+        profanity = ProfanityFilter(["ass"], mode=MatchMode.SUBSTRING)
+        assert profanity.mask("a classic") == "a cl***ic"
+
+    def test_compounds(self):
+        """Test that each occurrence in a compound is found."""
+        profanity = ProfanityFilter(["heck", "darn"], mode=MatchMode.SUBSTRING)
+        assert [match.start for match in profanity.find("heckdarnheck")] == [
+            0,
+            4,
+            8,
+        ]
+
+
+class TestLeetNormalization:
+    """Test suite for leetspeak normalization."""
+
+    def test_substitutes(self):
+        """Test that digits and symbols match the letters they replace."""
+        profanity = ProfanityFilter(["hell", "ass", "bigot"])
+        assert profanity.find("H3LL he1l @$$ 8!907") == [
+            ProfanityMatch("hell", "H3LL", 0, 4),
+            ProfanityMatch("hell", "he1l", 5, 9),
+            ProfanityMatch("ass", "@$$", 10, 13),
+            ProfanityMatch("bigot", "8!907", 14, 19),
+        ]
+
+    def test_trailing_symbol_not_part_of_word(self):
+        """Test that punctuation after a word does not prevent a match."""
+        assert ProfanityFilter(["heck"]).mask("h3ck!") == "****!"
+
+    def test_disabled(self):
+        """Test that substitutes are literal when normalization is off."""
+        profanity = ProfanityFilter(["hell"], normalize_leet=False)
+        assert profanity.find("h3ll") == []
+        assert profanity.mask("hell") == "****"
+
+    def test_request_examples(self):
+        """Test the substitutions named for a and i."""
+        assert "@" in LEET_SUBSTITUTIONS["a"]
+        assert "1" in LEET_SUBSTITUTIONS["i"]
+
+
+class TestMasking:
+    """Test suite for mask characters."""
+
+    def test_custom_character(self):
+        """Test that another mask character can be chosen."""
+        assert ProfanityFilter(["darn"], mask_chars="#").mask("darn") == "####"
+
+    def test_characters_cycle(self):
+        """Test that several mask characters are used in turn."""
+        profanity = ProfanityFilter(["darnit"], mask_chars="@#$%")
+        assert profanity.mask("darnit!") == "@#$%@#!"
+
+    def test_cluster_masked_once(self):
+        """Test that a letter with a combining mark is one masked character."""
+        assert ProfanityFilter(["heck"]).mask("hec\u0301k") == "hec\u0301k"
+        assert ProfanityFilter(["heck"]).mask("heck\u0301") == "****"
+
+    def test_text_outside_matches_unchanged(self):
+        """Test that unmatched text is kept exactly."""
+        text = "Nothing to see here, café \U0001F600"
+        assert ProfanityFilter(["darn"]).mask(text) == text
+
+
+class TestErrors:
+    """Test suite for error conditions."""
+
+    def test_words_string(self):
+        """Test that a single string instead of a list raises TypeError."""
+        with pytest.raises(TypeError, match="iterable of strings"):
+            ProfanityFilter("darn")
+
+    def test_word_not_string(self):
+        """Test that a word that is not a string raises TypeError."""
+        with pytest.raises(TypeError, match="Words must be strings"):
+            ProfanityFilter(["darn", 1])
+
+    def test_empty_word(self):
+        """Test that an empty word raises ValueError."""
+        with pytest.raises(ValueError, match="Words cannot be empty"):
+            ProfanityFilter(["darn", ""])
+
+    def test_invalid_mode(self):
+        """Test that a mode that is not a MatchMode raises TypeError."""
+        with pytest.raises(TypeError, match="Mode must be a MatchMode"):
+            ProfanityFilter(["darn"], mode="substring")
+
+    def test_invalid_mask_chars(self):
+        """Test that invalid mask characters raise an error."""
+        with pytest.raises(TypeError, match="Mask characters must be a string"):
+            ProfanityFilter(["darn"], mask_chars=None)
+        with pytest.raises(ValueError, match="Mask characters cannot be empty"):
+            ProfanityFilter(["darn"], mask_chars="")
+
+    def test_invalid_input(self):
+        """Test that input is validated like capitalize_words."""
+        profanity = ProfanityFilter(["darn"])
+        with pytest.raises(TypeError, match="Input must be a string"):
+            profanity.find(None)
+        with pytest.raises(ValueError, match="control character"):
+            profanity.mask("darn\x1b")
+        with pytest.raises(TypeError, match="Input must be a string"):
+            profanity.contains(b"darn")
@@ -0,0 +1,120 @@
+"""
+Unit tests for scan module.
+
+Tests incremental segmentation of text and binary streams into grapheme
+clusters, words and sentences, segments split across chunks, and error
+conditions.
+"""
+
+import io
+
+import pytest
+from src.graphemes import iter_graphemes
+from src.scan import scan_graphemes, scan_sentences, scan_words
+from src.string_utils import split_sentences
+
+
+class TestScanGraphemes:
+    """Test suite for scan_graphemes function."""
+
+    def test_matches_iter_graphemes(self):
+        """Test that every chunk size gives the clusters of the whole text."""
+        family = "\U0001f468\u200d\U0001f469\u200d\U0001f467"
+        text = f"cafe\u0301 {family} \U0001f1f3\U0001f1ff\r\n"
+        for chunk_size in range(1, 8):
+            clusters = list(scan_graphemes(io.StringIO(text), chunk_size))
+            assert clusters == list(iter_graphemes(text))
+
+    def test_binary_stream(self):
+        """Test that characters split across reads are decoded whole."""
+        stream = io.BytesIO("e\u0301é".encode("utf-8"))
+        assert list(scan_graphemes(stream, 1)) == ["e\u0301", "é"]
+
+    def test_empty_stream(self):
+        """Test that an empty stream yields nothing."""
+        assert list(scan_graphemes(io.StringIO(""))) == []
+
+    def test_reads_lazily(self):
+        """Test that the stream is only read as the iterator advances."""
+        stream = io.StringIO("abcdef")
+        clusters = scan_graphemes(stream, 2)
+        assert stream.tell() == 0
+        assert next(clusters) == "a"
+        assert stream.tell() == 2
+
+
+class TestScanWords:
+    """Test suite for scan_words function."""
+
+    def test_words_split_across_chunks(self):
+        """Test that words spanning chunk boundaries are found whole."""
+        text = "Don't stop: 3.14 and 1,000 snake_case words."
+        expected = ["Don't", "stop", "3.14", "and", "1,000", "snake_case", "words"]
+        for chunk_size in (1, 2, 3, 5, 64):
+            assert list(scan_words(io.StringIO(text), chunk_size)) == expected
+
+    def test_trailing_punctuation(self):
+        """Test that punctuation held back at the end is not a word."""
+        assert list(scan_words(io.StringIO("end... "), 2)) == ["end"]
+
+    def test_ideographs(self):
+        """Test that ideographs are words of one character each."""
+        assert list(scan_words(io.StringIO("中文"), 1)) == ["中", "文"]
+
+    def test_long_word(self):
+        """Test a word much longer than the chunk size."""
+        word = "x" * 10_000
+        assert list(scan_words(io.StringIO(f"{word} y"), 7)) == [word, "y"]
+
+    def test_binary_stream(self):
+        """Test that binary streams are decoded as UTF-8."""
+        stream = io.BytesIO("naïve café".encode("utf-8"))
+        assert list(scan_words(stream, 1)) == ["naïve", "café"]
+
+
+class TestScanSentences:
+    """Test suite for scan_sentences function."""
+
+    def test_matches_split_sentences(self):
+        """Test that every chunk size gives the sentences of the whole text."""
+        text = 'He said "Stop." Then Dr. Lee left! Was it 1.5 km?… Yes。OK'
+        expected = [sentence.text for sentence in split_sentences(text)]
+        for chunk_size in range(1, 10):
+            assert list(scan_sentences(io.StringIO(text), chunk_size)) == expected
+
+    def test_abbreviation_context(self):
+        """Test that an abbreviation is read with the text before it."""
+        text = "4。Dr. 4"
+        expected = [sentence.text for sentence in split_sentences(text)]
+        assert list(scan_sentences(io.StringIO(text), 1)) == expected
+
+    def test_custom_abbreviations(self):
+        """Test that custom abbreviations do not end sentences."""
+        stream = io.StringIO("See approx. 5 units. Done.")
+        sentences = scan_sentences(stream, 3, ["approx"])
+        assert list(sentences) == ["See approx. 5 units.", "Done."]
+
+    def test_whitespace_only(self):
+        """Test that a stream of whitespace has no sentences."""
+        assert list(scan_sentences(io.StringIO(" \n\t "), 2)) == []
+
+
+class TestScanErrors:
+    """Test suite for scan error conditions."""
+
+    def test_invalid_chunk_size(self):
+        """Test that chunk_size is checked when the function is called."""
+        for scan in (scan_graphemes, scan_words, scan_sentences):
+            with pytest.raises(ValueError, match="Chunk size must be at least 1"):
+                scan(io.StringIO("text"), 0)
+
+    def test_control_character(self):
+        """Test that a disallowed control character is reported with its index."""
+        words = scan_words(io.StringIO("ok fine\x00"), 3)
+        with pytest.raises(ValueError, match="at index 7"):
+            list(words)
+
+    def test_invalid_utf8(self):
+        """Test that invalid UTF-8 in a binary stream is rejected."""
+        with pytest.raises(UnicodeDecodeError):
+            list(scan_graphemes(io.BytesIO(b"ab\xff"), 2))
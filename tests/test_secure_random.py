@@ -0,0 +1,105 @@
+"""
+Unit tests for secure_random module.
+
+Tests random strings from the predefined and custom alphabets, the
+uniformity of their characters, base64url tokens and error conditions.
+"""
+
+import base64
+from collections import Counter
+
+import pytest
+from src.secure_random import Alphabet, random_string, random_token
+
+
+class TestRandomString:
+    """Test suite for random_string function."""
+
+    def test_length(self):
+        """Test that the requested number of characters is generated."""
+        for length in (0, 1, 22, 100):
+            assert len(random_string(length)) == length
+
+    def test_predefined_alphabets(self):
+        """Test that each predefined alphabet is respected."""
+        for alphabet in Alphabet:
+            assert set(random_string(500, alphabet)) <= set(alphabet.value)
+
+    def test_alphabet_contents(self):
+        """Test the characters of the predefined alphabets."""
+        assert len(Alphabet.ALPHANUMERIC.value) == 62
+        assert Alphabet.HEX.value == "0123456789abcdef"
+        assert set(Alphabet.URL_SAFE.value) - set(Alphabet.ALPHANUMERIC.value) == {
+            "-",
+            "_",
+        }
+        assert not set("01IOlo") & set(Alphabet.NO_LOOKALIKES.value)
+
+    def test_default_alphabet(self):
+        """Test that the default alphabet is alphanumeric."""
+        result = random_string(200)
+        assert result.isascii() and result.isalnum()
+
+    def test_custom_alphabet(self):
+        """Test drawing from a custom string of characters."""
+        assert set(random_string(200, "ab")) == {"a", "b"}
+        assert set(random_string(50, "éñ")) <= {"é", "ñ"}
+
+    def test_results_differ(self):
+        """Test that repeated calls do not repeat results."""
+        assert len({random_string(22) for _ in range(100)}) == 100
+
+    def test_uniform_distribution(self):
+        """Test that no character of an odd-sized alphabet is favoured."""
+        alphabet = "abcdefghijk"
+        samples = 110_000
+        counts = Counter(random_string(samples, alphabet))
+        expected = samples / len(alphabet)
+        chi_square = sum((counts[c] - expected) ** 2 / expected for c in alphabet)
+        # The 99.99th percentile of chi-square with 10 degrees of freedom.
+        assert chi_square < 35.56
+
+    def test_invalid_length(self):
+        """Test that a negative length raises ValueError."""
+        with pytest.raises(ValueError, match="Length cannot be negative, got -1"):
+            random_string(-1)
+
+    def test_invalid_alphabet(self):
+        """Test that alphabets that are too small or repeat raise."""
+        with pytest.raises(ValueError, match="at least 2 characters"):
+            random_string(5, "a")
+        with pytest.raises(ValueError, match="cannot repeat characters"):
+            random_string(5, "aab")
+        with pytest.raises(TypeError, match="Alphabet must be an Alphabet or a string"):
+            random_string(5, ["a", "b"])
+
+
+class TestRandomToken:
+    """Test suite for random_token function."""
+
+    def test_default_length(self):
+        """Test that the default token encodes 32 bytes."""
+        token = random_token()
+        assert len(token) == 43
+        assert len(base64.urlsafe_b64decode(token + "=")) == 32
+
+    def test_url_safe_characters(self):
+        """Test that tokens only use base64url characters without padding."""
+        token = random_token(300)
+        assert set(token) <= set(Alphabet.URL_SAFE.value)
+
+    def test_sizes(self):
+        """Test tokens of various sizes."""
+        for num_bytes in (1, 16, 24, 64):
+            token = random_token(num_bytes)
+            padding = "=" * (-len(token) % 4)
+            assert len(base64.urlsafe_b64decode(token + padding)) == num_bytes
+
+    def test_tokens_differ(self):
+        """Test that repeated calls do not repeat tokens."""
+        assert len({random_token(16) for _ in range(100)}) == 100
+
+    def test_invalid_size(self):
+        """Test that fewer than 1 byte raises ValueError."""
+        with pytest.raises(ValueError, match="at least 1, got 0"):
+            random_token(0)
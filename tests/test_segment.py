@@ -0,0 +1,75 @@
+"""
+Unit tests for segment module.
+
+Tests dictionary longest-match segmentation of Chinese, Japanese and Thai,
+mixed-script text, punctuation, spans and error conditions.
+"""
+
+import pytest
+from src.segment import Segmenter
+
+
+CHINESE = Segmenter(["北京", "北京大学", "大学", "大学生", "欢迎", "你", "手机"])
+
+
+class TestSegmenter:
+    """Test suite for Segmenter class."""
+
+    def test_longest_match(self):
+        """Test that the longest dictionary word wins."""
+        assert CHINESE.segment("北京大学生") == ["北京大学", "生"]
+        assert CHINESE.segment("北京欢迎你") == ["北京", "欢迎", "你"]
+
+    def test_unknown_han_characters(self):
+        """Test that Han characters missing from the dictionary stand alone."""
+        assert CHINESE.segment("我爱北京") == ["我", "爱", "北京"]
+
+    def test_japanese(self):
+        """Test mixed kanji and kana, with unmatched katakana kept together."""
+        japanese = Segmenter(["私", "は", "を", "食べる"])
+        assert japanese.segment("私はラーメンを食べる") == [
+            "私", "は", "ラーメン", "を", "食べる"
+        ]
+
+    def test_thai(self):
+        """Test Thai, whose vowel and tone marks stay with their consonant."""
+        thai = Segmenter(["ร\u0e49าน", "อาหาร", "ร\u0e49านอาหาร", "ไทย"])
+        assert thai.segment("ร\u0e49านอาหารไทย") == ["ร\u0e49านอาหาร", "ไทย"]
+        assert thai.segment("ร\u0e49านอร\u0e48อย") == ["ร\u0e49าน", "อร\u0e48อย"]
+
+    def test_mixed_scripts_and_whitespace(self):
+        """Test that other scripts and whitespace split as in word_count."""
+        assert CHINESE.segment("iPhone手机 hello  world") == [
+            "iPhone", "手机", "hello", "world"
+        ]
+
+    def test_punctuation_attaches(self):
+        """Test that punctuation joins the neighbouring word."""
+        assert CHINESE.segment("「北京」欢迎你！") == ["「北京」", "欢迎", "你！"]
+        assert CHINESE.segment("北京，大学") == ["北京，", "大学"]
+        assert CHINESE.segment("。。。") == ["。。。"]
+
+    def test_spans_and_word_count(self):
+        """Test that spans index the input and word_count counts them."""
+        text = " 北京 欢迎你"
+        assert CHINESE.spans(text) == [(1, 3), (4, 6), (6, 7)]
+        assert CHINESE.word_count(text) == 3
+        assert CHINESE.word_count("") == 0
+
+    def test_extend(self):
+        """Test that extend adds words without changing the original."""
+        extended = CHINESE.extend(["欢迎你"])
+        assert extended.segment("欢迎你") == ["欢迎你"]
+        assert CHINESE.segment("欢迎你") == ["欢迎", "你"]
+
+    def test_invalid_dictionary(self):
+        """Test that non-string and empty words are rejected."""
+        with pytest.raises(TypeError, match="Words must be strings"):
+            Segmenter(["北京", 1])
+        with pytest.raises(ValueError, match="cannot be empty"):
+            Segmenter([""])
+
+    def test_invalid_input(self):
+        """Test that non-string input raises TypeError."""
+        with pytest.raises(TypeError, match="Input must be a string"):
+            CHINESE.segment(None)
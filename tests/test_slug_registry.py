@@ -0,0 +1,183 @@
+"""
+Unit tests for slug_registry module.
+
+Tests issuing unique slugs, the built-in and custom collision strategies,
+preloaded and released slugs, length limits, concurrent use and error
+conditions.
+"""
+
+import threading
+
+import pytest
+from src.slug_registry import (
+    SlugRegistry,
+    hash_suffix,
+    numeric_suffix,
+    random_suffix,
+)
+from src.transliterate import SlugOptions
+
+
+class TestStrategies:
+    """Test suite for the built-in collision strategies."""
+
+    def test_numeric_suffix(self):
+        """Test that the numeric suffix is the attempt number."""
+        assert numeric_suffix("post", 2) == "2"
+        assert numeric_suffix("post", 10) == "10"
+
+    def test_hash_suffix(self):
+        """Test that hash suffixes are deterministic and vary by attempt."""
+        assert hash_suffix("post", 2) == hash_suffix("post", 2)
+        assert hash_suffix("post", 2) != hash_suffix("post", 3)
+        assert hash_suffix("post", 2) != hash_suffix("page", 2)
+        assert len(hash_suffix("post", 2)) == 7
+        assert set(hash_suffix("post", 2)) <= set("0123456789abcdef")
+
+    def test_random_suffix(self):
+        """Test that random suffixes are 6 base62 characters."""
+        suffixes = {random_suffix("post", 2) for _ in range(20)}
+        assert len(suffixes) == 20
+        for suffix in suffixes:
+            assert len(suffix) == 6
+            assert suffix.isascii() and suffix.isalnum()
+
+
+class TestSlugRegistry:
+    """Test suite for SlugRegistry class."""
+
+    def test_first_slug_unsuffixed(self):
+        """Test that a free slug is issued as it is."""
+        registry = SlugRegistry()
+        assert registry.issue("Hello, World!") == "hello-world"
+        assert "hello-world" in registry
+        assert len(registry) == 1
+
+    def test_numeric_collisions(self):
+        """Test that collisions get -2, -3 and so on."""
+        registry = SlugRegistry()
+        slugs = [registry.issue("My Post") for _ in range(4)]
+        assert slugs == ["my-post", "my-post-2", "my-post-3", "my-post-4"]
+
+    def test_suffix_skips_taken_slugs(self):
+        """Test that a suffixed slug already taken is skipped."""
+        registry = SlugRegistry(issued=["post", "post-2"])
+        assert registry.issue("Post") == "post-3"
+        assert registry.issue("Post 2") == "post-2-2"
+
+    def test_hash_strategy(self):
+        """Test issuing slugs with hash suffixes."""
+        registry = SlugRegistry(hash_suffix)
+        assert registry.issue("Post") == "post"
+        assert registry.issue("Post") == "post-" + hash_suffix("post", 2)
+
+    def test_random_strategy(self):
+        """Test issuing slugs with random suffixes."""
+        registry = SlugRegistry(random_suffix)
+        registry.issue("Post")
+        slug = registry.issue("Post")
+        assert slug.startswith("post-") and len(slug) == len("post-") + 6
+
+    def test_custom_strategy(self):
+        """Test that any function of the same signature can be a strategy."""
+        registry = SlugRegistry(lambda slug, attempt: "v" + str(attempt - 1))
+        registry.issue("Release")
+        assert registry.issue("Release") == "release-v1"
+        assert registry.issue("Release") == "release-v2"
+
+    def test_transliterated_text(self):
+        """Test that text is converted with slugify_transliterated."""
+        registry = SlugRegistry()
+        assert registry.issue("Привет, мир") == "privet-mir"
+        assert registry.issue("privet mir") == "privet-mir-2"
+
+    def test_options(self):
+        """Test that the separator of the options is used for suffixes."""
+        registry = SlugRegistry(options=SlugOptions(separator="_"))
+        registry.issue("Hello World")
+        assert registry.issue("Hello World") == "hello_world_2"
+
+    def test_max_length(self):
+        """Test that slugs are shortened so the suffix fits."""
+        registry = SlugRegistry(options=SlugOptions(max_length=12))
+        assert registry.issue("Hello wonderful world") == "hello"
+        assert registry.issue("Hello wonderful world") == "hello-2"
+        assert registry.issue("Supercalifragilistic") == "supercalifra"
+        assert registry.issue("Supercalifragilistic") == "supercalif-2"
+
+    def test_release(self):
+        """Test that a released slug can be issued again."""
+        registry = SlugRegistry()
+        registry.issue("Post")
+        registry.issue("Post")
+        assert registry.release("post")
+        assert not registry.release("post")
+        assert "post" not in registry
+        assert registry.issue("Post") == "post"
+        assert registry.issue("Post") == "post-3"
+
+    def test_concurrent_issue(self):
+        """Test that concurrent callers never receive the same slug."""
+        registry = SlugRegistry(max_attempts=1000)
+        results = []
+        barrier = threading.Barrier(8)
+
+        def worker():
+            barrier.wait()
+            results.extend(registry.issue("Same Title") for _ in range(50))
+
+        threads = [threading.Thread(target=worker) for _ in range(8)]
+        for thread in threads:
+            thread.start()
+        for thread in threads:
+            thread.join()
+        assert len(results) == 400
+        assert len(set(results)) == 400
+        assert len(registry) == 400
+
+
+class TestSlugRegistryErrors:
+    """Test suite for SlugRegistry error conditions."""
+
+    def test_attempts_exhausted(self):
+        """Test that issue raises when no suffix is free."""
+        registry = SlugRegistry(lambda slug, attempt: "x", max_attempts=3)
+        registry.issue("Post")
+        registry.issue("Post")
+        with pytest.raises(ValueError, match="No free slug for 'post' after 3"):
+            registry.issue("Post")
+
+    def test_empty_slug(self):
+        """Test that text with nothing to make a slug from raises."""
+        with pytest.raises(ValueError, match="Cannot make a slug"):
+            SlugRegistry().issue("!!!")
+
+    def test_suffix_too_long(self):
+        """Test that a suffix that cannot fit the maximum length raises."""
+        registry = SlugRegistry(hash_suffix, options=SlugOptions(max_length=8))
+        registry.issue("Post")
+        with pytest.raises(ValueError, match="does not fit"):
+            registry.issue("Post")
+
+    def test_strategy_must_return_string(self):
+        """Test that a strategy returning a non-string raises TypeError."""
+        registry = SlugRegistry(lambda slug, attempt: attempt)
+        registry.issue("Post")
+        with pytest.raises(TypeError, match="Strategy must return a string"):
+            registry.issue("Post")
+
+    def test_invalid_construction(self):
+        """Test that invalid constructor arguments raise."""
+        with pytest.raises(TypeError, match="Strategy must be callable"):
+            SlugRegistry("numeric")
+        with pytest.raises(ValueError, match="at least 1"):
+            SlugRegistry(max_attempts=0)
+        with pytest.raises(ValueError, match="Separator cannot be empty"):
+            SlugRegistry(options=SlugOptions(separator=""))
+        with pytest.raises(TypeError, match="Slugs must be strings"):
+            SlugRegistry(issued=[1])
+
+    def test_invalid_input(self):
+        """Test that non-string text raises TypeError."""
+        with pytest.raises(TypeError, match="Input must be a string"):
+            SlugRegistry().issue(None)
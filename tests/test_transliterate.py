@@ -0,0 +1,254 @@
+"""
+Unit tests for transliterate module.
+
+Tests the built-in Cyrillic, Greek and Arabic schemes, case handling,
+custom tables, streaming, transliterated slugs and error conditions.
+"""
+
+import io
+
+import pytest
+from src.transliterate import (
+    BGN_PCGN_RUSSIAN,
+    SCHEMES,
+    SlugOptions,
+    Transliterator,
+    slugify_transliterated,
+    transliterate,
+)
+
+
+class TestTransliterate:
+    """Test suite for transliterate function."""
+
+    def test_russian_bgn_pcgn(self):
+        """Test BGN/PCGN romanization of Russian."""
+        assert transliterate("Щукин Хабаровск", "ru") == "Shchukin Khabarovsk"
+        assert transliterate("подъезд", "ru") == "podʺyezd"
+
+    def test_russian_initial_forms(self):
+        """Test that е and ё are spelled ye and yë at word start and after vowels."""
+        assert transliterate("Елена", "ru") == "Yelena"
+        assert transliterate("Ёж", "ru") == "Yëzh"
+        assert transliterate("поезд", "ru") == "poyezd"
+        assert transliterate("лес", "ru") == "les"
+
+    def test_ukrainian_national(self):
+        """Test the Ukrainian national system, including initial forms."""
+        assert transliterate("Київ", "uk") == "Kyiv"
+        assert transliterate("Юрій Їжакевич", "uk") == "Yurii Yizhakevych"
+        assert transliterate("Згорани", "uk") == "Zghorany"
+        assert transliterate("Знам'янка", "uk") == "Znamianka"
+
+    def test_iso_9(self):
+        """Test the one-to-one ISO 9 mapping across Cyrillic alphabets."""
+        assert transliterate("Щука", "iso9") == "Ŝuka"
+        assert transliterate("Љубљана", "iso9") == "L\u0302ubl\u0302ana"
+
+    def test_greek(self):
+        """Test ELOT 743, including digraphs and accented vowels."""
+        assert transliterate("Αθήνα", "el") == "Athina"
+        assert transliterate("Ευαγγελία", "el") == "Evangelia"
+        assert transliterate("Ντόρα", "el") == "Dora"
+
+    def test_arabic(self):
+        """Test the simplified Arabic scheme, with vowel marks and digits."""
+        assert transliterate("م\u064fنى", "ar") == "muna"
+        assert transliterate("شمس ٢٠", "ar") == "shms 20"
+
+    def test_case_follows_input(self):
+        """Test that digraph output follows the case of the surrounding text."""
+        assert transliterate("ЩУКИН", "ru") == "SHCHUKIN"
+        assert transliterate("Ж", "ru") == "Zh"
+        assert transliterate("ЖЖ", "ru") == "ZHZH"
+
+    def test_other_characters_kept(self):
+        """Test that Latin text, digits and punctuation pass through."""
+        assert transliterate("Tom и Jerry, 2024!", "ru") == "Tom i Jerry, 2024!"
+
+    def test_decomposed_input(self):
+        """Test that decomposed letters are normalized before matching."""
+        assert transliterate("и\u0306", "ru") == "y"
+
+    def test_unknown_scheme(self):
+        """Test that unknown scheme names raise ValueError."""
+        with pytest.raises(ValueError, match="Unknown transliteration scheme"):
+            transliterate("text", "xx")
+        with pytest.raises(TypeError, match="Scheme must be a string"):
+            transliterate("text", BGN_PCGN_RUSSIAN)
+
+    def test_invalid_input(self):
+        """Test that non-string input raises TypeError."""
+        with pytest.raises(TypeError, match="Input must be a string"):
+            transliterate(None, "ru")
+
+
+class TestTransliterator:
+    """Test suite for the Transliterator class."""
+
+    def test_custom_table(self):
+        """Test a transliterator built from a custom table."""
+        custom = Transliterator({"ж": "j", "а": "a", "н": "n"})
+        assert custom.transliterate("Жан жан") == "Jan jan"
+
+    def test_longest_match_wins(self):
+        """Test that multi-character keys take precedence over single ones."""
+        custom = Transliterator({"a": "1", "ab": "2", "b": "3"})
+        assert custom.transliterate("abba") == "231"
+
+    def test_extend_overrides(self):
+        """Test that extend adds and overrides entries without mutation."""
+        german = BGN_PCGN_RUSSIAN.extend({"ш": "sch", "е": "je"}, "German")
+        assert german.transliterate("Шея") == "Schjeya"
+        assert german.name == "German"
+        assert BGN_PCGN_RUSSIAN.transliterate("Ш") == "Sh"
+
+    def test_invalid_table(self):
+        """Test that malformed tables are rejected."""
+        with pytest.raises(TypeError, match="map strings to strings"):
+            Transliterator({"a": 1})
+        with pytest.raises(ValueError, match="cannot be empty"):
+            Transliterator({"": "x"})
+
+    def test_schemes_registry(self):
+        """Test that every built-in scheme is a Transliterator with a name."""
+        assert set(SCHEMES) == {"ru", "uk", "iso9", "el", "ar"}
+        assert all(scheme.name for scheme in SCHEMES.values())
+
+
+class TestTransliterateStream:
+    """Test suite for Transliterator.transliterate_stream."""
+
+    @pytest.mark.parametrize("chunk_size", [1, 2, 5, 64])
+    def test_matches_whole_input(self, chunk_size):
+        """Test that chunk boundaries never change the output."""
+        text = "Щукин ЁЛКИН, Елена и поезд! " * 20
+        output = io.StringIO()
+        BGN_PCGN_RUSSIAN.transliterate_stream(io.StringIO(text), output, chunk_size)
+        assert output.getvalue() == transliterate(text, "ru")
+
+    def test_binary_stream(self):
+        """Test that binary streams are decoded and encoded as UTF-8."""
+        output = io.BytesIO()
+        SCHEMES["el"].transliterate_stream(
+            io.BytesIO("Ευαγγελία".encode("utf-8")), output, 3
+        )
+        assert output.getvalue() == b"Evangelia"
+
+    def test_combining_mark_across_chunks(self):
+        """Test that a mark in the next chunk still joins its letter."""
+        output = io.StringIO()
+        BGN_PCGN_RUSSIAN.transliterate_stream(io.StringIO("и\u0306"), output, 1)
+        assert output.getvalue() == "y"
+
+    def test_invalid_chunk_size(self):
+        """Test that chunk sizes below 1 raise ValueError."""
+        with pytest.raises(ValueError, match="Chunk size must be at least 1"):
+            BGN_PCGN_RUSSIAN.transliterate_stream(io.StringIO(""), io.StringIO(), 0)
+
+    def test_rejects_control_characters(self):
+        """Test that disallowed control characters raise ValueError."""
+        with pytest.raises(ValueError, match="control character"):
+            BGN_PCGN_RUSSIAN.transliterate_stream(
+                io.StringIO("a\x01"), io.StringIO()
+            )
+
+
+class TestSlugifyTransliterated:
+    """Test suite for slugify_transliterated function."""
+
+    def test_latin_text(self):
+        """Test that Latin text is slugified as by slugify."""
+        assert slugify_transliterated("Café Crème: A Guide!") == "cafe-creme-a-guide"
+        assert slugify_transliterated("  --Hello--  ") == "hello"
+
+    def test_cyrillic(self):
+        """Test that Russian is transliterated with BGN/PCGN by default."""
+        assert slugify_transliterated("Щукин Хабаровск") == "shchukin-khabarovsk"
+        assert slugify_transliterated("Подъезд, Ёлка") == "podyezd-yelka"
+
+    def test_other_cyrillic_alphabets(self):
+        """Test that letters outside Russian fall back to ISO 9."""
+        assert slugify_transliterated("Ђорђевић") == "dordevic"
+        assert slugify_transliterated("Київ") == "kiiv"
+
+    def test_greek(self):
+        """Test that Greek is transliterated with ELOT 743."""
+        assert slugify_transliterated("Καλημέρα κόσμε") == "kalimera-kosme"
+        assert slugify_transliterated("ΕΛΛΗΝΙΚΑ") == "ellinika"
+
+    def test_mixed_text(self):
+        """Test that Latin, Cyrillic and Greek words mix in one slug."""
+        assert slugify_transliterated("Hello, Мир! Γεια 日本") == "hello-mir-geia"
+
+    def test_scheme(self):
+        """Test that a chosen scheme replaces the defaults."""
+        options = SlugOptions(scheme="uk")
+        assert slugify_transliterated("Київ", options) == "kyiv"
+        options = SlugOptions(scheme="ar")
+        assert slugify_transliterated("مرحبا", options) == "mrhba"
+
+    def test_separator(self):
+        """Test a custom separator."""
+        options = SlugOptions(separator="_")
+        assert slugify_transliterated("Привет, мир", options) == "privet_mir"
+
+    def test_replacements(self):
+        """Test that replacements apply before anything else."""
+        options = SlugOptions(replacements={"&": " and ", "++": "pp", "#": "sharp"})
+        assert slugify_transliterated("C++ & C#", options) == "cpp-and-csharp"
+        options = SlugOptions(replacements={"ё": "jo"})
+        assert slugify_transliterated("ёж", options) == "jozh"
+
+    def test_longest_replacement_first(self):
+        """Test that overlapping replacement keys match the longest first."""
+        options = SlugOptions(replacements={"+": " plus ", "++": " pp "})
+        assert slugify_transliterated("a+++b", options) == "a-pp-plus-b"
+
+    def test_max_length_at_word_boundary(self):
+        """Test that long slugs are cut after the last whole word."""
+        options = SlugOptions(max_length=20)
+        slug = slugify_transliterated("Fish and Chips: A Love Story", options)
+        assert slug == "fish-and-chips-a"
+        options = SlugOptions(max_length=16)
+        assert slugify_transliterated("fish and chips a love", options) == (
+            "fish-and-chips-a"
+        )
+        options = SlugOptions(max_length=5)
+        assert slugify_transliterated("ab cd", options) == "ab-cd"
+
+    def test_max_length_long_first_word(self):
+        """Test that a first word longer than the limit is cut mid-word."""
+        options = SlugOptions(max_length=5)
+        assert slugify_transliterated("Supercalifragilistic day", options) == "super"
+
+    def test_max_length_multi_character_separator(self):
+        """Test that a separator longer than one character is never split."""
+        options = SlugOptions(separator="__", max_length=5)
+        assert slugify_transliterated("ab cd", options) == "ab"
+
+    def test_nothing_to_spell(self):
+        """Test that text with no transliterable letters gives an empty slug."""
+        assert slugify_transliterated("日本 ★") == ""
+
+    def test_invalid_options(self):
+        """Test that unusable options raise."""
+        with pytest.raises(ValueError, match="Separator cannot be empty"):
+            slugify_transliterated("x", SlugOptions(separator=""))
+        with pytest.raises(TypeError, match="Separator must be a string"):
+            slugify_transliterated("x", SlugOptions(separator=None))
+        with pytest.raises(ValueError, match="at least 1"):
+            slugify_transliterated("x", SlugOptions(max_length=0))
+        with pytest.raises(ValueError, match="Replacement keys cannot be empty"):
+            slugify_transliterated("x", SlugOptions(replacements={"": "y"}))
+        with pytest.raises(TypeError, match="Replacements must map strings"):
+            slugify_transliterated("x", SlugOptions(replacements={"&": None}))
+        with pytest.raises(ValueError, match="Unknown transliteration scheme"):
+            slugify_transliterated("x", SlugOptions(scheme="xx"))
+
+    def test_invalid_input(self):
+        """Test that invalid input raises."""
+        with pytest.raises(TypeError, match="Input must be a string"):
+            slugify_transliterated(None)
+        with pytest.raises(ValueError):
+            slugify_transliterated("bad\x00title")